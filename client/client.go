@@ -0,0 +1,248 @@
+// Package client is a Go client for the top sites ranking API.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultRetryWait  = 200 * time.Millisecond
+	defaultTimeout    = 30 * time.Second
+)
+
+// DailyRank is the rank of a domain on one day.
+type DailyRank struct {
+	Rank int
+	Date time.Time
+}
+
+// Client calls the ranking API. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	maxRetries int
+	retryWait  time.Duration
+	timeout    time.Duration
+	apiKey     string
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the http.Client used to send requests.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how many times a failed request is retried and the wait before the first retry.
+// The wait doubles on every further retry unless the server sends Retry-After.
+func WithRetries(maxRetries int, wait time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryWait = wait
+	}
+}
+
+// WithTimeout sets the timeout of a single attempt. Zero disables it.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithAPIKey sets the API key sent with every request.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// New creates a Client for the API served at baseURL, e.g. "https://ranking.example.com".
+// baseURL may have a path, e.g. "https://example.com/ranking", which is prepended to the paths of the API.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url %s: %w", baseURL, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("base url %s must be absolute", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		maxRetries: defaultMaxRetries,
+		retryWait:  defaultRetryWait,
+		timeout:    defaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// GetDailyRanking returns the rank of domain for every day from start to end on which it was ranked, newest first.
+// It returns an empty slice when the domain was not ranked in the range.
+func (c *Client) GetDailyRanking(ctx context.Context, domain string, start time.Time, end time.Time) ([]DailyRank, error) {
+	q := url.Values{}
+	q.Set("domain", domain)
+	q.Set("start_date", start.Format("2006-01-02"))
+	q.Set("end_date", end.Format("2006-01-02"))
+
+	return c.getRanking(ctx, "/api/v1/rankings/daily", q)
+}
+
+// GetMonthlyRanking returns the rank of domain on the last day of every month from start to end, newest first.
+// Only the year and month of start and end are used.
+// It returns an empty slice when the domain was not ranked in the range.
+func (c *Client) GetMonthlyRanking(ctx context.Context, domain string, start time.Time, end time.Time) ([]DailyRank, error) {
+	q := url.Values{}
+	q.Set("domain", domain)
+	q.Set("start_month", start.Format("2006-01"))
+	q.Set("end_month", end.Format("2006-01"))
+
+	return c.getRanking(ctx, "/api/v1/rankings/monthly", q)
+}
+
+type rankingResponse struct {
+	Ranks []struct {
+		Rank *int   `json:"rank"`
+		Date string `json:"date"`
+	} `json:"ranks"`
+	Domain string `json:"domain"`
+}
+
+func (c *Client) getRanking(ctx context.Context, path string, q url.Values) ([]DailyRank, error) {
+	var resp rankingResponse
+	err := c.get(ctx, path, q, &resp)
+	if err != nil {
+		var apiErr *Error
+		if errors.As(err, &apiErr) && apiErr.Code == CodeNotFound {
+			return []DailyRank{}, nil
+		}
+		return nil, err
+	}
+
+	ranks := make([]DailyRank, 0, len(resp.Ranks))
+	for _, r := range resp.Ranks {
+		if r.Rank == nil {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", r.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %s in response: %w", r.Date, err)
+		}
+		ranks = append(ranks, DailyRank{Rank: *r.Rank, Date: date})
+	}
+	return ranks, nil
+}
+
+// get sends a GET request and decodes the JSON response into dest, retrying temporary failures.
+func (c *Client) get(ctx context.Context, path string, q url.Values, dest any) error {
+	// path is joined rather than resolved, so that the path of the base url, e.g. behind a reverse proxy, is kept
+	u := c.baseURL.JoinPath(path)
+	u.RawQuery = q.Encode()
+
+	wait := c.retryWait
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := c.do(ctx, u.String(), dest)
+		if err == nil {
+			return nil
+		}
+		if attempt >= c.maxRetries || !isRetryable(ctx, err) {
+			return err
+		}
+
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// do sends one request. It returns the delay requested by a Retry-After header along with any error.
+func (c *Client) do(ctx context.Context, u string, dest any) (time.Duration, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return parseRetryAfter(resp.Header.Get("Retry-After")), newError(resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+		return 0, fmt.Errorf("failed to decode response from %s: %w", u, err)
+	}
+	return 0, nil
+}
+
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		// the quota is only reset on the next day
+		if apiErr.Code == CodeQuotaExceeded {
+			return false
+		}
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	// transport errors and timeouts of a single attempt
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
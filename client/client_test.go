@@ -0,0 +1,216 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/shigaichi/top-sites-ranking-api/client"
+	route "github.com/shigaichi/top-sites-ranking-api/internal/adapter/http"
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/handler"
+	"github.com/shigaichi/top-sites-ranking-api/internal/config"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+type usecaseStub struct {
+	ranks []model.DailyRank
+	err   error
+}
+
+func (u usecaseStub) GetDailyRanking(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	return u.ranks, u.err
+}
+
+func (u usecaseStub) GetDailyRankingWithGaps(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time, fill model.FillMode) ([]model.DailyRankEntry, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (u usecaseStub) GetMonthlyRanking(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	return u.ranks, u.err
+}
+
+func newServer(t *testing.T, u usecaseStub) *httptest.Server {
+	t.Helper()
+	r := route.NewRouteImpl(route.Handlers{Rankings: handler.NewGetRankingImpl(u)}, nil, config.ServerConfig{}, config.AuthConfig{}, nil).InitRoute()
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_GetDailyRanking(t *testing.T) {
+	tests := []struct {
+		name     string
+		usecase  usecaseStub
+		start    time.Time
+		end      time.Time
+		expected []client.DailyRank
+		err      *client.Error
+	}{
+		{
+			name: "ranks found",
+			usecase: usecaseStub{ranks: []model.DailyRank{
+				{Rank: 2, Date: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)},
+				{Rank: 1, Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+			}},
+			start: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+			expected: []client.DailyRank{
+				{Rank: 2, Date: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)},
+				{Rank: 1, Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:     "no ranks",
+			usecase:  usecaseStub{ranks: []model.DailyRank{}},
+			start:    time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			end:      time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+			expected: []client.DailyRank{},
+		},
+		{
+			name:  "invalid range",
+			start: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			err:   &client.Error{StatusCode: http.StatusBadRequest, Code: client.CodeInvalidRange, Param: "start_date", Detail: "start_date should be before or equal to end_date"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t, tt.usecase)
+			c, err := client.New(srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := c.GetDailyRanking(context.Background(), "example.com", tt.start, tt.end)
+			if tt.err != nil {
+				var apiErr *client.Error
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("expected *client.Error, got %v", err)
+				}
+				if diff := cmp.Diff(tt.err, apiErr, cmpopts.IgnoreFields(client.Error{}, "RequestID")); diff != "" {
+					t.Errorf("unexpected error (-want +got):\n%s", diff)
+				}
+				if apiErr.RequestID == "" {
+					t.Errorf("expected request id in error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClient_GetMonthlyRanking(t *testing.T) {
+	srv := newServer(t, usecaseStub{ranks: []model.DailyRank{
+		{Rank: 3, Date: time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC)},
+	}})
+	c, err := client.New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.GetMonthlyRanking(context.Background(), "example.com", time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []client.DailyRank{{Rank: 3, Date: time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC)}}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("unexpected result (-want +got):\n%s", diff)
+	}
+}
+
+func TestClient_BaseURLWithPath(t *testing.T) {
+	router := route.NewRouteImpl(route.Handlers{Rankings: handler.NewGetRankingImpl(usecaseStub{ranks: []model.DailyRank{
+		{Rank: 1, Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}})}, nil, config.ServerConfig{}, config.AuthConfig{}, nil).InitRoute()
+	srv := httptest.NewServer(http.StripPrefix("/ranking", router))
+	defer srv.Close()
+
+	for _, base := range []string{srv.URL + "/ranking", srv.URL + "/ranking/"} {
+		c, err := client.New(base)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := c.GetDailyRanking(context.Background(), "example.com", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatalf("base url %s: unexpected error: %v", base, err)
+		}
+		if len(got) != 1 {
+			t.Errorf("base url %s: expected 1 rank, got %v", base, got)
+		}
+	}
+}
+
+func TestClient_Retry(t *testing.T) {
+	router := route.NewRouteImpl(route.Handlers{Rankings: handler.NewGetRankingImpl(usecaseStub{ranks: []model.DailyRank{
+		{Rank: 1, Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}})}, nil, config.ServerConfig{}, config.AuthConfig{}, nil).InitRoute()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		router.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	c, err := client.New(srv.URL, client.WithRetries(2, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.GetDailyRanking(context.Background(), "example.com", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || calls.Load() != 3 {
+		t.Errorf("expected 1 rank after 3 calls, got %d ranks after %d calls", len(got), calls.Load())
+	}
+}
+
+func TestClient_RetryGivesUp(t *testing.T) {
+	srv := newServer(t, usecaseStub{err: errors.New("db down")})
+	c, err := client.New(srv.URL, client.WithRetries(1, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.GetDailyRanking(context.Background(), "example.com", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError || apiErr.Code != client.CodeInternal {
+		t.Errorf("expected internal error, got %v", err)
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	c, err := client.New(srv.URL, client.WithTimeout(10*time.Millisecond), client.WithRetries(0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.GetDailyRanking(context.Background(), "example.com", time.Now(), time.Now())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Error codes returned by the API. See the problem schema of the OpenAPI document for the full list.
+const (
+	CodeMissingParameter = "missing_parameter"
+	CodeInvalidParameter = "invalid_parameter"
+	CodeInvalidRange     = "invalid_range"
+	CodeNotFound         = "not_found"
+	CodeInternal         = "internal_error"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeQuotaExceeded    = "quota_exceeded"
+	CodeRateLimited      = "rate_limited"
+	CodeInvalidBody      = "invalid_body"
+	CodeRequestTooLarge  = "request_too_large"
+)
+
+// Error is an error response of the API.
+type Error struct {
+	StatusCode int
+	Code       string
+	Param      string
+	Detail     string
+	RequestID  string
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("ranking api returned %d", e.StatusCode)
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+	if e.Param != "" {
+		msg += " (param " + e.Param + ")"
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.RequestID != "" {
+		msg += " [request id " + e.RequestID + "]"
+	}
+	return msg
+}
+
+// newError builds an Error from a non-2xx response. Bodies that are not problem documents keep only the status.
+func newError(resp *http.Response) *Error {
+	e := &Error{StatusCode: resp.StatusCode}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return e
+	}
+
+	var p struct {
+		Detail    string `json:"detail"`
+		Code      string `json:"code"`
+		Param     string `json:"param"`
+		RequestID string `json:"request_id"`
+	}
+	if strings.Contains(resp.Header.Get("Content-Type"), "json") && json.Unmarshal(body, &p) == nil {
+		e.Code = p.Code
+		e.Param = p.Param
+		e.Detail = p.Detail
+		e.RequestID = p.RequestID
+	} else {
+		e.Detail = strings.TrimSpace(string(body))
+	}
+	return e
+}
@@ -2,43 +2,88 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"flag"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
-	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shigaichi/top-sites-ranking-api/internal/config"
 	"github.com/shigaichi/top-sites-ranking-api/internal/injector"
 
-	route "github.com/shigaichi/top-sites-ranking-api/internal/adapter/http"
-	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/handler"
 	"github.com/shigaichi/top-sites-ranking-api/internal/infra"
+	"github.com/shigaichi/top-sites-ranking-api/internal/metrics"
+	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
 	"github.com/shigaichi/top-sites-ranking-api/internal/util"
 	log "github.com/sirupsen/logrus"
 )
 
 func main() {
-	err := util.SetupLogger()
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
 	if err != nil {
-		log.WithFields(log.Fields{"error": err}).Error("failed to set up logger when start up api server")
-		return
+		log.WithFields(log.Fields{"error": err}).Fatal("failed to load config when start up api server")
 	}
 
-	db, err := infra.NewDb()
+	err = util.SetupLogger(cfg.Log.Level)
 	if err != nil {
-		log.WithFields(log.Fields{"error": err}).Error("failed to create db connection when start up api server")
-		return
+		log.WithFields(log.Fields{"error": err}).Fatal("failed to set up logger when start up api server")
 	}
 
-	u := injector.NewRankHistoryInteractor(db)
-	h := handler.NewGetRankingImpl(u)
-	ri := route.NewRouteImpl(h)
+	shutdownTracing, err := util.SetupTracing(context.Background(), "api-server")
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Fatal("failed to set up tracing when start up api server")
+	}
+
+	db, err := infra.NewDb(cfg.Database)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Fatal("failed to create db connection when start up api server")
+	}
+
+	replicas, err := infra.NewReplicaDbs(cfg.Database)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Fatal("failed to open read replicas when start up api server")
+	}
+	reader := infra.NewRoutingDB(db, replicas, cfg.Database.ReplicaHealthCheckInterval)
+	healthCtx, stopHealthCheck := context.WithCancel(context.Background())
+	defer stopHealthCheck()
+	reader.Start(healthCtx)
+
+	var reg *prometheus.Registry
+	var metricsSrv *http.Server
+	if cfg.Metrics.ListenAddress != "" {
+		replicaPools := make([]*sql.DB, len(replicas))
+		for i, replica := range replicas {
+			replicaPools[i] = replica.DB
+		}
+		reg = metrics.NewRegistry(db.DB, replicaPools)
+		metricsSrv = &http.Server{
+			Addr:              cfg.Metrics.ListenAddress,
+			Handler:           metrics.Handler(reg),
+			ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		}
+		go func() {
+			if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.WithFields(log.Fields{"error": err, "address": cfg.Metrics.ListenAddress}).Error("Failed to start metrics server")
+			}
+		}()
+	}
+
+	// admin jobs are only started through the admin API, which requires authentication
+	var jobs *usecase.JobInteractor
+	if cfg.Auth.Enabled {
+		jobs = injector.NewJobInteractor(db, cfg.Writer)
+	}
+
+	ri := injector.NewRoute(db, reader, cfg, jobs, reg)
 	r := ri.InitRoute()
 
 	srv := http.Server{
-		Addr:              ":3333",
+		Addr:              fmt.Sprintf(":%d", cfg.Server.Port),
 		Handler:           r,
-		ReadHeaderTimeout: 3 * time.Minute,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
 	}
 
 	go func() {
@@ -59,10 +104,8 @@ func main() {
 	// Block until we receive our signal.
 	<-c
 
-	var wait = 30 * time.Second
-
 	// Create a deadline to wait for.
-	ctx, cancel := context.WithTimeout(context.Background(), wait)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 	// Doesn't block if no connections, but will otherwise wait
 	// until the timeout deadline.
@@ -70,6 +113,21 @@ func main() {
 	if err != nil {
 		log.WithFields(log.Fields{"error": err}).Error("Failed to shutdown server")
 	}
+	// let running admin jobs finish within the same deadline, so that a list is not left half written
+	if jobs != nil {
+		if err := jobs.Wait(ctx); err != nil {
+			log.WithFields(log.Fields{"error": err}).Error("Failed to wait for running jobs")
+		}
+	}
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(ctx); err != nil {
+			log.WithFields(log.Fields{"error": err}).Error("Failed to shutdown metrics server")
+		}
+	}
+	err = shutdownTracing(ctx)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Error("Failed to flush traces")
+	}
 	// Optionally, you could run srv.Shutdown in a goroutine and block on
 	// <-ctx.Done() if your application should wait for other services
 	// to finalize based on context cancellation.
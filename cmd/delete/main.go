@@ -3,32 +3,48 @@ package main
 import (
 	"context"
 	"flag"
+	"os"
 	"time"
 
 	"github.com/jmoiron/sqlx"
 
+	"github.com/shigaichi/top-sites-ranking-api/internal/config"
 	"github.com/shigaichi/top-sites-ranking-api/internal/infra"
 	"github.com/shigaichi/top-sites-ranking-api/internal/injector"
+	"github.com/shigaichi/top-sites-ranking-api/internal/metrics"
 	"github.com/shigaichi/top-sites-ranking-api/internal/util"
 	log "github.com/sirupsen/logrus"
 )
 
 func main() {
-	err := util.SetupLogger()
-	if err != nil {
-		log.WithFields(log.Fields{"error": err}).Fatal("failed to set up logger when starting delete")
-	}
-
 	var sinceFlag int
 	flag.IntVar(&sinceFlag, "since", 100, "Number of days back to process")
 
-	flag.Parse()
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Fatal("failed to load config when starting delete")
+	}
+
+	err = util.SetupLogger(cfg.Log.Level)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Fatal("failed to set up logger when starting delete")
+	}
 
 	if sinceFlag < 0 {
 		log.WithFields(log.Fields{"since": sinceFlag}).Fatal("error: 'since' flag cannot be negative")
 	}
 
-	db, err := infra.NewDb()
+	shutdownTracing, err := util.SetupTracing(context.Background(), "delete")
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Fatal("failed to set up tracing when starting delete")
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.WithFields(log.Fields{"error": err}).Warn("failed to flush delete traces")
+		}
+	}()
+
+	db, err := infra.NewDb(cfg.Database)
 
 	if err != nil {
 		log.WithFields(log.Fields{"error": err}).Fatal("failed to create db connection when start up service.")
@@ -41,8 +57,15 @@ func main() {
 		}
 	}(db)
 
+	start := time.Now()
+	m := metrics.NewJobMetrics("delete")
+
 	usecase := injector.NewDeleteInteractor(db, 1)
-	err = usecase.Delete(context.Background(), time.Duration(sinceFlag)*24*time.Hour)
+	deleted, err := usecase.Delete(context.Background(), time.Duration(sinceFlag)*24*time.Hour)
+	m.Observe(start, deleted, err)
+	if exportErr := m.Export(cfg.Metrics.Textfile, cfg.Metrics.PushgatewayURL); exportErr != nil {
+		log.WithFields(log.Fields{"error": exportErr}).Warn("failed to export delete metrics")
+	}
 	if err != nil {
 		log.WithFields(log.Fields{"error": err, "since": sinceFlag}).Fatal("failed to delete old records")
 	}
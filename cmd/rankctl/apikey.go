@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/config"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/injector"
+)
+
+// runAPIKey manages the API keys accepted by the api server.
+func runAPIKey(ctx context.Context, dbConfig config.DatabaseConfig, args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected a subcommand: create, list or revoke")
+	}
+
+	sub, args := args[0], args[1:]
+	fs, common := newFlagSet("apikey " + sub)
+	name := fs.String("name", "", "Name of the key (create)")
+	scopesStr := fs.String("scopes", string(model.ScopeRead), "Comma separated scopes of the key: read, admin (create)")
+	quota := fs.Int("quota", 0, "Requests allowed per UTC day, 0 for unlimited (create)")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := common.validate(); err != nil {
+		return err
+	}
+
+	db, err := openDb(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	u := injector.NewAPIKeyInteractor(db)
+
+	switch sub {
+	case "create":
+		var scopes []model.Scope
+		for _, s := range strings.Split(*scopesStr, ",") {
+			scope, err := model.ParseScope(strings.TrimSpace(s))
+			if err != nil {
+				return err
+			}
+			scopes = append(scopes, scope)
+		}
+
+		key, secret, err := u.Create(ctx, *name, scopes, *quota)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Store the key now, it cannot be shown again.\n")
+		return renderAPIKeys(common.output, []model.APIKey{key}, secret)
+	case "list":
+		keys, err := u.List(ctx)
+		if err != nil {
+			return err
+		}
+		return renderAPIKeys(common.output, keys, "")
+	case "revoke":
+		if len(positional) != 1 {
+			return errors.New("expected the name of the key to revoke")
+		}
+		return u.Revoke(ctx, positional[0])
+	default:
+		return fmt.Errorf("unknown subcommand %q, expected create, list or revoke", sub)
+	}
+}
+
+// renderAPIKeys writes keys. secret is only shown when a key was just created.
+func renderAPIKeys(output string, keys []model.APIKey, secret string) error {
+	t := table{header: []string{"name", "scopes", "daily_quota", "created_at", "revoked_at"}}
+	type row struct {
+		Name       string   `json:"name"`
+		Key        string   `json:"key,omitempty"`
+		Scopes     []string `json:"scopes"`
+		DailyQuota int      `json:"daily_quota"`
+		CreatedAt  string   `json:"created_at"`
+		RevokedAt  string   `json:"revoked_at,omitempty"`
+	}
+	if secret != "" {
+		t.header = append(t.header, "key")
+	}
+
+	rows := make([]row, len(keys))
+	for i, k := range keys {
+		rows[i] = row{Name: k.Name, Key: secret, DailyQuota: k.DailyQuota, CreatedAt: k.CreatedAt.UTC().Format(dateLayout)}
+		for _, s := range k.Scopes {
+			rows[i].Scopes = append(rows[i].Scopes, string(s))
+		}
+		if k.RevokedAt != nil {
+			rows[i].RevokedAt = k.RevokedAt.UTC().Format(dateLayout)
+		}
+
+		cells := []string{k.Name, strings.Join(rows[i].Scopes, ","), strconv.Itoa(k.DailyQuota), rows[i].CreatedAt, rows[i].RevokedAt}
+		if secret != "" {
+			cells = append(cells, secret)
+		}
+		t.rows = append(t.rows, cells)
+	}
+	return render(os.Stdout, output, t, rows)
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/shigaichi/top-sites-ranking-api/client"
+	"github.com/shigaichi/top-sites-ranking-api/internal/config"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/infra"
+	"github.com/shigaichi/top-sites-ranking-api/internal/injector"
+)
+
+const dateLayout = "2006-01-02"
+
+// commonFlags are accepted by every command.
+type commonFlags struct {
+	output string
+}
+
+func newFlagSet(name string) (*flag.FlagSet, *commonFlags) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	c := &commonFlags{}
+	fs.StringVar(&c.output, "o", "table", "Output format: table, json or csv")
+	return fs, c
+}
+
+func (c *commonFlags) validate() error {
+	switch c.output {
+	case "table", "json", "csv":
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q, expected table, json or csv", c.output)
+	}
+}
+
+// parseArgs parses flags that may appear before or after positional arguments and returns the positional ones.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func parseDate(name, value string) (time.Time, error) {
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid -%s %q, expected YYYY-MM-DD", name, value)
+	}
+	return date, nil
+}
+
+func today() string {
+	return time.Now().UTC().Format(dateLayout)
+}
+
+func daysAgo(days int) string {
+	return time.Now().UTC().AddDate(0, 0, -days).Format(dateLayout)
+}
+
+func openDb(cfg config.DatabaseConfig) (*sqlx.DB, error) {
+	db, err := infra.NewDb(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return db, nil
+}
+
+func runHistory(ctx context.Context, dbConfig config.DatabaseConfig, args []string) error {
+	fs, common := newFlagSet("history")
+	fromStr := fs.String("from", daysAgo(30), "First day of the range (YYYY-MM-DD)")
+	toStr := fs.String("to", today(), "Last day of the range (YYYY-MM-DD)")
+	monthly := fs.Bool("monthly", false, "Only show month-end ranks")
+	// history is the only command the API can answer, so the other commands do not accept -api
+	api := fs.String("api", "", "Base URL of the ranking API. If not specified, the database is queried directly.")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := common.validate(); err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("expected exactly one domain")
+	}
+	domain := positional[0]
+
+	from, err := parseDate("from", *fromStr)
+	if err != nil {
+		return err
+	}
+	to, err := parseDate("to", *toStr)
+	if err != nil {
+		return err
+	}
+	if from.After(to) {
+		return errors.New("-from should be before or equal to -to")
+	}
+
+	var ranks []model.DailyRank
+	if *api != "" {
+		ranks, err = historyFromAPI(ctx, *api, domain, from, to, *monthly)
+	} else {
+		ranks, err = historyFromDb(ctx, dbConfig, domain, from, to, *monthly)
+	}
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"date", "rank"}}
+	type row struct {
+		Date string `json:"date"`
+		Rank int    `json:"rank"`
+	}
+	rows := make([]row, len(ranks))
+	for i, r := range ranks {
+		rows[i] = row{Date: r.Date.UTC().Format(dateLayout), Rank: r.Rank}
+		t.rows = append(t.rows, []string{rows[i].Date, strconv.Itoa(r.Rank)})
+	}
+	return render(os.Stdout, common.output, t, struct {
+		Domain string `json:"domain"`
+		Ranks  []row  `json:"ranks"`
+	}{Domain: domain, Ranks: rows})
+}
+
+func historyFromDb(ctx context.Context, dbConfig config.DatabaseConfig, domain string, from, to time.Time, monthly bool) ([]model.DailyRank, error) {
+	db, err := openDb(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	u := injector.NewRankHistoryInteractor(db)
+	if monthly {
+		return u.GetMonthlyRanking(ctx, domain, model.RollupNone, from, to)
+	}
+	return u.GetDailyRanking(ctx, domain, model.RollupNone, from, to)
+}
+
+func historyFromAPI(ctx context.Context, api string, domain string, from, to time.Time, monthly bool) ([]model.DailyRank, error) {
+	c, err := client.New(api)
+	if err != nil {
+		return nil, err
+	}
+
+	var ranks []client.DailyRank
+	if monthly {
+		ranks, err = c.GetMonthlyRanking(ctx, domain, from, to)
+	} else {
+		ranks, err = c.GetDailyRanking(ctx, domain, from, to)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.DailyRank, len(ranks))
+	for i, r := range ranks {
+		result[i] = model.DailyRank{Rank: r.Rank, Date: r.Date}
+	}
+	return result, nil
+}
+
+func runTop(ctx context.Context, dbConfig config.DatabaseConfig, args []string) error {
+	fs, common := newFlagSet("top")
+	dateStr := fs.String("date", today(), "Date of the list (YYYY-MM-DD)")
+	n := fs.Int("n", 100, "Number of domains")
+
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if err := common.validate(); err != nil {
+		return err
+	}
+	if *n <= 0 {
+		return errors.New("-n must be positive")
+	}
+	date, err := parseDate("date", *dateStr)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDb(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	list, rankings, err := injector.NewTopSitesInteractor(db, 1).GetTop(ctx, date, *n)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"rank", "domain"}}
+	type row struct {
+		Rank   int    `json:"rank"`
+		Domain string `json:"domain"`
+	}
+	rows := make([]row, len(rankings))
+	for i, r := range rankings {
+		rows[i] = row{Rank: r.Rank, Domain: r.Domain}
+		t.rows = append(t.rows, []string{strconv.Itoa(r.Rank), r.Domain})
+	}
+	return render(os.Stdout, common.output, t, struct {
+		ListID string `json:"list_id"`
+		Date   string `json:"date"`
+		Ranks  []row  `json:"ranks"`
+	}{ListID: list.ID, Date: list.CreatedOn.UTC().Format(dateLayout), Ranks: rows})
+}
+
+func runLists(ctx context.Context, dbConfig config.DatabaseConfig, args []string) error {
+	fs, common := newFlagSet("lists")
+	fromStr := fs.String("from", daysAgo(30), "First day of the range (YYYY-MM-DD)")
+	toStr := fs.String("to", today(), "Last day of the range (YYYY-MM-DD)")
+
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if err := common.validate(); err != nil {
+		return err
+	}
+	from, err := parseDate("from", *fromStr)
+	if err != nil {
+		return err
+	}
+	to, err := parseDate("to", *toStr)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDb(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	lists, err := injector.NewTopSitesInteractor(db, 1).GetLists(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"id", "created_on"}}
+	type row struct {
+		ID        string `json:"id"`
+		CreatedOn string `json:"created_on"`
+	}
+	rows := make([]row, len(lists))
+	for i, l := range lists {
+		rows[i] = row{ID: l.ID, CreatedOn: l.CreatedOn.UTC().Format(dateLayout)}
+		t.rows = append(t.rows, []string{rows[i].ID, rows[i].CreatedOn})
+	}
+	return render(os.Stdout, common.output, t, rows)
+}
+
+func runDiff(ctx context.Context, dbConfig config.DatabaseConfig, args []string) error {
+	fs, common := newFlagSet("diff")
+	fromStr := fs.String("from", "", "Date of the earlier list (YYYY-MM-DD)")
+	toStr := fs.String("to", "", "Date of the later list (YYYY-MM-DD)")
+	n := fs.Int("n", 100, "Number of top domains to compare")
+
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if err := common.validate(); err != nil {
+		return err
+	}
+	if *fromStr == "" || *toStr == "" {
+		return errors.New("-from and -to are required")
+	}
+	if *n <= 0 {
+		return errors.New("-n must be positive")
+	}
+	from, err := parseDate("from", *fromStr)
+	if err != nil {
+		return err
+	}
+	to, err := parseDate("to", *toStr)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDb(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	changes, err := injector.NewTopSitesInteractor(db, 1).Diff(ctx, from, to, *n)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"domain", "from", "to", "change"}}
+	type row struct {
+		Domain string `json:"domain"`
+		From   *int   `json:"from"`
+		To     *int   `json:"to"`
+		Change string `json:"change"`
+	}
+	rows := make([]row, len(changes))
+	for i, c := range changes {
+		rows[i] = row{Domain: c.Domain, From: c.FromRank, To: c.ToRank, Change: describeChange(c)}
+		t.rows = append(t.rows, []string{c.Domain, formatRank(c.FromRank), formatRank(c.ToRank), rows[i].Change})
+	}
+	return render(os.Stdout, common.output, t, rows)
+}
+
+func formatRank(rank *int) string {
+	if rank == nil {
+		return "-"
+	}
+	return strconv.Itoa(*rank)
+}
+
+func describeChange(c model.RankChange) string {
+	switch {
+	case c.FromRank == nil:
+		return "new"
+	case c.ToRank == nil:
+		return "dropped"
+	case c.Change() > 0:
+		return "+" + strconv.Itoa(c.Change())
+	default:
+		return strconv.Itoa(c.Change())
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/config"
+	"github.com/shigaichi/top-sites-ranking-api/internal/injector"
+)
+
+// runDomains maintains the stored domains.
+func runDomains(ctx context.Context, dbConfig config.DatabaseConfig, args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected a subcommand: normalize")
+	}
+
+	sub, args := args[0], args[1:]
+	fs, _ := newFlagSet("domains " + sub)
+	batch := fs.Int("batch", 10000, "Domains updated per statement (normalize)")
+
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if *batch <= 0 {
+		return errors.New("-batch must be positive")
+	}
+
+	db, err := openDb(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch sub {
+	case "normalize":
+		updated, err := injector.NewDomainNameInteractor(db, *batch).Normalize(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "normalized %d domains\n", updated)
+		return nil
+	default:
+		return fmt.Errorf("unknown subcommand %q, expected normalize", sub)
+	}
+}
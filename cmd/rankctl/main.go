@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/config"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+const usage = `rankctl queries the ranking database or the ranking API.
+
+Usage:
+  rankctl history <domain> [-from YYYY-MM-DD] [-to YYYY-MM-DD] [-monthly] [-api URL]
+  rankctl top [-date YYYY-MM-DD] [-n 100]
+  rankctl lists [-from YYYY-MM-DD] [-to YYYY-MM-DD]
+  rankctl diff -from YYYY-MM-DD -to YYYY-MM-DD [-n 100]
+  rankctl apikey create -name NAME [-scopes read,admin] [-quota 0]
+  rankctl apikey list
+  rankctl apikey revoke <name>
+  rankctl domains normalize [-batch 10000]
+  rankctl stats refresh [-from YYYY-MM-DD] [-to YYYY-MM-DD]
+  rankctl stats monthly [-from YYYY-MM-DD] [-to YYYY-MM-DD]
+
+Every command accepts:
+  -o table|json|csv  output format (default table)
+
+history -api URL queries the HTTP API at URL instead of the database. The other commands only query the database.
+
+The database is configured with the DB_* environment variables or the YAML file in CONFIG_FILE.
+`
+
+func main() {
+	cfg, err := config.Load(nil, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rankctl: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = util.SetupLogger(cfg.Log.Level)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Fatal("failed to set up logger when starting rankctl")
+	}
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	cmd, args := os.Args[1], os.Args[2:]
+	switch cmd {
+	case "history":
+		err = runHistory(ctx, cfg.Database, args)
+	case "top":
+		err = runTop(ctx, cfg.Database, args)
+	case "lists":
+		err = runLists(ctx, cfg.Database, args)
+	case "diff":
+		err = runDiff(ctx, cfg.Database, args)
+	case "apikey":
+		err = runAPIKey(ctx, cfg.Database, args)
+	case "domains":
+		err = runDomains(ctx, cfg.Database, args)
+	case "stats":
+		err = runStats(ctx, cfg.Database, args)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rankctl %s: %v\n", cmd, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/config"
+	"github.com/shigaichi/top-sites-ranking-api/internal/injector"
+)
+
+// runStats maintains the precomputed statistics.
+func runStats(ctx context.Context, dbConfig config.DatabaseConfig, args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected a subcommand: refresh or monthly")
+	}
+
+	sub, args := args[0], args[1:]
+	fs, _ := newFlagSet("stats " + sub)
+	fromStr := fs.String("from", daysAgo(30), "First day of the lists to refresh (YYYY-MM-DD)")
+	toStr := fs.String("to", today(), "Last day of the lists to refresh (YYYY-MM-DD)")
+
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	from, err := parseDate("from", *fromStr)
+	if err != nil {
+		return err
+	}
+	to, err := parseDate("to", *toStr)
+	if err != nil {
+		return err
+	}
+	if from.After(to) {
+		return errors.New("-from should be before or equal to -to")
+	}
+
+	db, err := openDb(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch sub {
+	case "refresh":
+		refreshed, err := injector.NewTLDStatsInteractor(db).Refresh(ctx, from, to)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "refreshed TLD stats of %d lists\n", refreshed)
+		return nil
+	case "monthly":
+		refreshed, err := injector.NewRankHistoryInteractor(db).RefreshMonthly(ctx, from, to)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "refreshed monthly rankings of %d lists\n", refreshed)
+		return nil
+	default:
+		return fmt.Errorf("unknown subcommand %q, expected refresh or monthly", sub)
+	}
+}
@@ -2,22 +2,26 @@ package main
 
 import (
 	"flag"
+	"os"
 	"time"
 
 	"github.com/shigaichi/top-sites-ranking-api/internal"
+	"github.com/shigaichi/top-sites-ranking-api/internal/config"
 	"github.com/shigaichi/top-sites-ranking-api/internal/util"
 	log "github.com/sirupsen/logrus"
 )
 
 func main() {
-	err := util.SetupLogger()
+	dateStr := flag.String("date", "", "Specify date in the format YYYY-MM-DD. If not specified, uses the current date.")
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
 	if err != nil {
-		log.WithFields(log.Fields{"error": err}).Error("failed to set up logger when starting standard writer")
-		return
+		log.WithFields(log.Fields{"error": err}).Fatal("failed to load config when starting standard writer")
 	}
 
-	dateStr := flag.String("date", "", "Specify date in the format YYYY-MM-DD. If not specified, uses the current date.")
-	flag.Parse()
+	err = util.SetupLogger(cfg.Log.Level)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Fatal("failed to set up logger when starting standard writer")
+	}
 
 	var date time.Time
 	if *dateStr == "" {
@@ -31,7 +35,7 @@ func main() {
 		}
 	}
 
-	err = internal.StandardWriter(date)
+	err = internal.StandardWriter(cfg, date)
 	if err != nil {
 		log.WithFields(log.Fields{"error": err, "date": date}).Error("Failed to execute StandardWriter for the given date")
 		return
@@ -1,19 +1,102 @@
-CREATE TABLE tranco_lists
+-- The schema of the ranking database. Postgres runs it when it creates a fresh volume. Every statement can be run
+-- again, so running it on an existing database migrates it: psql "$DATABASE_URL" -f init/db-init.sql
+
+CREATE TABLE IF NOT EXISTS tranco_lists
 (
     id         TEXT PRIMARY KEY,
     created_on TIMESTAMP NOT NULL
 );
 
-CREATE TABLE tranco_domains
+-- row_count is how many rankings a list has. The writer stores it with the list, so that the list catalogue
+-- does not count them on every request; lists saved before it existed are counted once here.
+ALTER TABLE tranco_lists
+    ADD COLUMN IF NOT EXISTS row_count INT;
+
+CREATE TABLE IF NOT EXISTS tranco_domains
 (
     id     SERIAL PRIMARY KEY,
     domain TEXT UNIQUE NOT NULL
 );
 
-CREATE TABLE tranco_rankings
+-- registrable is the public suffix plus one label (mail.google.com -> google.com) and tld the last label.
+-- They are added to an existing database here. Rows saved before they existed are NULL until the standard writer,
+-- or `rankctl domains normalize`, fills them in.
+ALTER TABLE tranco_domains
+    ADD COLUMN IF NOT EXISTS registrable TEXT,
+    ADD COLUMN IF NOT EXISTS tld         TEXT;
+
+-- serves rolling ranks up by registrable domain
+CREATE INDEX IF NOT EXISTS tranco_domains_registrable_idx ON tranco_domains (registrable);
+
+-- serves finding the rows to fill in, and is empty once they are
+CREATE INDEX IF NOT EXISTS tranco_domains_unparsed_idx ON tranco_domains (id) WHERE registrable IS NULL;
+
+CREATE TABLE IF NOT EXISTS tranco_rankings
 (
     domain_id BIGINT,
     list_id   TEXT,
     ranking   INT NOT NULL,
     PRIMARY KEY (domain_id, list_id)
 );
+
+-- serves deleting a list and counting its rankings
+CREATE INDEX IF NOT EXISTS tranco_rankings_list_id_idx ON tranco_rankings (list_id);
+
+-- backfills the row counts of the lists saved before they were stored
+UPDATE tranco_lists tl
+SET row_count = (SELECT COUNT(*) FROM tranco_rankings tr WHERE tr.list_id = tl.id)
+WHERE tl.row_count IS NULL;
+
+-- a copy of the rankings of month-end lists, filled by the writer and completed by the delete job before every
+-- retention pass, that serves the monthly rankings.
+-- It has no foreign keys so that it outlives the daily rankings; deleting a single list removes its rows.
+CREATE TABLE IF NOT EXISTS tranco_monthly_rankings
+(
+    domain_id  BIGINT    NOT NULL,
+    created_on TIMESTAMP NOT NULL,
+    list_id    TEXT      NOT NULL,
+    ranking    INT       NOT NULL,
+    PRIMARY KEY (domain_id, created_on)
+);
+
+CREATE INDEX IF NOT EXISTS tranco_monthly_rankings_list_id_idx ON tranco_monthly_rankings (list_id);
+
+-- backfills the month-end lists stored before the snapshot existed
+INSERT INTO tranco_monthly_rankings (domain_id, created_on, list_id, ranking)
+SELECT tr.domain_id, tl.created_on, tr.list_id, tr.ranking
+FROM tranco_rankings tr
+         INNER JOIN tranco_lists tl ON tr.list_id = tl.id
+WHERE tl.created_on::date = (date_trunc('month', tl.created_on) + INTERVAL '1 month' - INTERVAL '1 day')::date
+ON CONFLICT (domain_id, created_on) DO NOTHING;
+
+-- how many domains of each TLD appear in the top_n of a list, for top_n in model.TLDStatsTops.
+-- The writer fills it with the rankings of every list; `rankctl stats refresh` fills it for lists saved before it existed.
+CREATE TABLE IF NOT EXISTS tranco_tld_stats
+(
+    list_id TEXT NOT NULL REFERENCES tranco_lists (id) ON DELETE CASCADE,
+    top_n   INT  NOT NULL,
+    tld     TEXT NOT NULL,
+    domains INT  NOT NULL,
+    PRIMARY KEY (list_id, top_n, tld)
+);
+
+-- key_hash is the hex SHA-256 of the key; the key itself is only shown once when it is created.
+-- daily_quota 0 means unlimited.
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id          SERIAL PRIMARY KEY,
+    name        TEXT UNIQUE NOT NULL,
+    key_hash    TEXT UNIQUE NOT NULL,
+    scopes      TEXT[]      NOT NULL DEFAULT '{read}',
+    daily_quota INT         NOT NULL DEFAULT 0,
+    created_at  TIMESTAMP   NOT NULL DEFAULT now(),
+    revoked_at  TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS api_key_usage
+(
+    key_id   INT    NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE,
+    day      DATE   NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (key_id, day)
+);
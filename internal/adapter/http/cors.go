@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/cors"
+	mymiddleware "github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/middleware"
+)
+
+// requestHeaders are the request headers every route may read.
+var requestHeaders = []string{"Accept", "Content-Type", "If-None-Match", "If-Modified-Since", mymiddleware.RequestIDHeader}
+
+// responseHeaders are the response headers beyond the CORS-safelisted ones that clients may read.
+var responseHeaders = []string{"ETag", "Content-Disposition", mymiddleware.RequestIDHeader}
+
+// corsOptions builds the CORS policy. The allowed methods are the ones of the registered routes,
+// and the allowed and exposed headers are the ones of the enabled features.
+func (i RouteImpl) corsOptions() cors.Options {
+	allowed := slices.Clone(requestHeaders)
+	if i.apiKeys != nil {
+		allowed = append(allowed, "Authorization", mymiddleware.APIKeyHeader)
+	}
+	allowed = append(allowed, i.server.CORS.AllowedHeaders...)
+
+	exposed := slices.Clone(responseHeaders)
+	if i.server.RateLimit.Enabled {
+		exposed = append(exposed, "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After")
+	}
+
+	return cors.Options{
+		AllowedOrigins:   i.server.CORS.AllowedOrigins,
+		AllowedMethods:   i.routeMethods(),
+		AllowedHeaders:   allowed,
+		ExposedHeaders:   exposed,
+		AllowCredentials: i.server.CORS.AllowCredentials,
+		MaxAge:           i.server.CORS.MaxAge,
+	}
+}
+
+// routeMethods returns the methods of the registered routes, sorted.
+// The routes are registered on a scratch router since the CORS middleware has to be installed before them.
+func (i RouteImpl) routeMethods() []string {
+	scratch := chi.NewRouter()
+	i.routes(scratch, mymiddleware.NewRateLimiter(time.Now, time.Minute))
+
+	var methods []string
+	_ = chi.Walk(scratch, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		if !slices.Contains(methods, method) {
+			methods = append(methods, method)
+		}
+		return nil
+	})
+	slices.Sort(methods)
+	return methods
+}
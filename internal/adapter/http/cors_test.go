@@ -0,0 +1,146 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/handler"
+	"github.com/shigaichi/top-sites-ranking-api/internal/config"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
+)
+
+func corsRouter(apiKeys bool) http.Handler {
+	server := config.Default().Server
+	server.CORS.AllowedOrigins = []string{"https://example.com", "https://*.example.org"}
+	server.CORS.AllowedHeaders = []string{"X-Client-Version"}
+
+	var keys apiKeyStub
+	if apiKeys {
+		return NewRouteImpl(Handlers{Rankings: getRankingStub{}, Health: healthStub{}}, keys, server, config.AuthConfig{Enabled: true, AllowAnonymous: true}, nil).InitRoute()
+	}
+	return NewRouteImpl(Handlers{Rankings: getRankingStub{}, Health: healthStub{}}, nil, server, config.Default().Auth, nil).InitRoute()
+}
+
+func TestCORS_Origins(t *testing.T) {
+	router := corsRouter(false)
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{origin: "https://example.com", allowed: true},
+		{origin: "https://app.example.org", allowed: true},
+		{origin: "https://a.b.example.org", allowed: true},
+		{origin: "https://example.org", allowed: false},
+		{origin: "http://example.com", allowed: false},
+		{origin: "https://example.com.evil.test", allowed: false},
+		{origin: "https://evilexample.org", allowed: false},
+		{origin: "https://other.test", allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/rankings/daily", nil)
+			req.Header.Set("Origin", tt.origin)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			got := rec.Header().Get("Access-Control-Allow-Origin")
+			if tt.allowed && got != tt.origin {
+				t.Errorf("expected origin %s to be allowed, got %q", tt.origin, got)
+			}
+			if !tt.allowed {
+				for _, h := range []string{"Access-Control-Allow-Origin", "Access-Control-Expose-Headers", "Access-Control-Allow-Credentials"} {
+					if v := rec.Header().Get(h); v != "" {
+						t.Errorf("expected no %s for disallowed origin, got %q", h, v)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	tests := []struct {
+		name    string
+		apiKeys bool
+		origin  string
+		method  string
+		headers string
+		allowed bool
+	}{
+		{name: "get", origin: "https://example.com", method: http.MethodGet, allowed: true},
+		{name: "conditional get", origin: "https://example.com", method: http.MethodGet, headers: "if-none-match", allowed: true},
+		{name: "configured header", origin: "https://example.com", method: http.MethodGet, headers: "x-client-version", allowed: true},
+		{name: "method without route", origin: "https://example.com", method: http.MethodDelete, allowed: false},
+		{name: "api key header without auth", origin: "https://example.com", method: http.MethodGet, headers: "authorization", allowed: false},
+		{name: "api key header with auth", apiKeys: true, origin: "https://example.com", method: http.MethodGet, headers: "authorization,x-api-key", allowed: true},
+		{name: "disallowed origin", origin: "https://other.test", method: http.MethodGet, allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, "/api/v1/rankings/daily", nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", tt.method)
+			if tt.headers != "" {
+				req.Header.Set("Access-Control-Request-Headers", tt.headers)
+			}
+			rec := httptest.NewRecorder()
+			corsRouter(tt.apiKeys).ServeHTTP(rec, req)
+
+			got := rec.Header().Get("Access-Control-Allow-Origin")
+			if tt.allowed && got != tt.origin {
+				t.Errorf("expected preflight to be allowed, got Access-Control-Allow-Origin %q", got)
+			}
+			if !tt.allowed && got != "" {
+				t.Errorf("expected preflight to be rejected, got Access-Control-Allow-Origin %q", got)
+			}
+		})
+	}
+}
+
+func TestCORS_MethodsFromRoutes(t *testing.T) {
+	got := NewRouteImpl(Handlers{Rankings: getRankingStub{}, Health: healthStub{}}, nil, config.Default().Server, config.Default().Auth, nil).routeMethods()
+	if len(got) != 1 || got[0] != http.MethodGet {
+		t.Errorf("expected only GET, got %v", got)
+	}
+}
+
+type rankHistoryStub struct {
+	usecase.RankHistoryUseCase
+}
+
+func (rankHistoryStub) GetDailyRanking(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	return []model.DailyRank{{Rank: 1, Date: start, ListID: "X5Y7N"}}, nil
+}
+
+func TestCORS_CachedResponseVariesByOrigin(t *testing.T) {
+	server := config.Default().Server
+	server.CORS.AllowedOrigins = []string{"https://example.com"}
+	router := NewRouteImpl(Handlers{Rankings: handler.NewGetRankingImpl(rankHistoryStub{})}, nil, server, config.Default().Auth, nil).InitRoute()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/rankings/daily?domain=example.com&start_date=2023-01-01&end_date=2023-01-01", nil)
+	req.Header.Set("Origin", "https://example.com")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if rec.Header().Get("Cache-Control") == "" {
+		t.Fatalf("expected a cacheable response")
+	}
+	vary := strings.Join(rec.Header().Values("Vary"), ",")
+	for _, want := range []string{"Origin", "Accept"} {
+		if !slices.Contains(strings.Split(strings.ReplaceAll(vary, " ", ""), ","), want) {
+			t.Errorf("expected Vary to contain %s, got %q", want, vary)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/handler/dto"
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/problem"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxAdminBodyBytes bounds the JSON bodies of admin requests.
+const maxAdminBodyBytes = 1 << 20
+
+type Admin interface {
+	Ingest(w http.ResponseWriter, r *http.Request)
+	Retention(w http.ResponseWriter, r *http.Request)
+	DeleteList(w http.ResponseWriter, r *http.Request)
+	GetJob(w http.ResponseWriter, r *http.Request)
+}
+
+type AdminImpl struct {
+	u usecase.JobUseCase
+}
+
+func NewAdminImpl(u usecase.JobUseCase) *AdminImpl {
+	return &AdminImpl{u: u}
+}
+
+// Ingest starts ingesting the list of the requested date.
+func (a AdminImpl) Ingest(w http.ResponseWriter, r *http.Request) {
+	var req dto.RequestIngest
+	if !decodeBody(w, r, maxAdminBodyBytes, &req) {
+		return
+	}
+	if req.Date == "" {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeMissingParameter, "date", "Missing required body field")
+		return
+	}
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "date", "date must be in YYYY-MM-DD format")
+		return
+	}
+
+	job, err := a.u.StartIngest(r.Context(), date)
+	writeJobStarted(w, r, job, err)
+}
+
+// Retention starts deleting the lists older than the requested number of days, except those of month ends.
+func (a AdminImpl) Retention(w http.ResponseWriter, r *http.Request) {
+	var req dto.RequestRetention
+	if !decodeBody(w, r, maxAdminBodyBytes, &req) {
+		return
+	}
+	if req.Since == nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeMissingParameter, "since", "Missing required body field")
+		return
+	}
+	if *req.Since < 0 {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "since", "since must not be negative")
+		return
+	}
+
+	job, err := a.u.StartRetention(r.Context(), time.Duration(*req.Since)*24*time.Hour, req.DryRun)
+	writeJobStarted(w, r, job, err)
+}
+
+// DeleteList starts deleting one list and its rankings.
+func (a AdminImpl) DeleteList(w http.ResponseWriter, r *http.Request) {
+	job, err := a.u.StartDeleteList(r.Context(), chi.URLParam(r, "id"))
+	writeJobStarted(w, r, job, err)
+}
+
+// GetJob reports the status of a job.
+func (a AdminImpl) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := a.u.Get(r.Context(), chi.URLParam(r, "id"))
+	if errors.Is(err, usecase.ErrJobNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "", "No job with the given id")
+		return
+	}
+	if err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err}).Error("Get usecase returned error while processing job")
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal, "", "")
+		return
+	}
+
+	writeJob(w, r, http.StatusOK, job)
+}
+
+func writeJobStarted(w http.ResponseWriter, r *http.Request, job model.Job, err error) {
+	if err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err}).Error("cannot start admin job")
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal, "", "")
+		return
+	}
+	w.Header().Set("Location", "/api/v1/admin/jobs/"+job.ID)
+	writeJob(w, r, http.StatusAccepted, job)
+}
+
+func writeJob(w http.ResponseWriter, r *http.Request, status int, job model.Job) {
+	resp := dto.ResponseJob{
+		ID:        job.ID,
+		Type:      string(job.Type),
+		Status:    string(job.Status),
+		Params:    job.Params,
+		CreatedAt: job.CreatedAt.UTC().Format(time.RFC3339),
+		Rows:      job.Rows,
+		ListIDs:   job.ListIDs,
+		Error:     job.Error,
+	}
+	if !job.StartedAt.IsZero() {
+		resp.StartedAt = job.StartedAt.UTC().Format(time.RFC3339)
+	}
+	if !job.FinishedAt.IsZero() {
+		resp.FinishedAt = job.FinishedAt.UTC().Format(time.RFC3339)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err}).Error("cannot write job response")
+	}
+}
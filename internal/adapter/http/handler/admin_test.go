@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
+)
+
+type jobUseCaseStub struct {
+	started []string
+}
+
+func (s *jobUseCaseStub) queued(typ model.JobType, params map[string]string) model.Job {
+	s.started = append(s.started, fmt.Sprintf("%s %v", typ, params))
+	return model.Job{ID: "j1", Type: typ, Status: model.JobStatusQueued, Params: params, CreatedAt: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)}
+}
+
+func (s *jobUseCaseStub) StartIngest(ctx context.Context, date time.Time) (model.Job, error) {
+	return s.queued(model.JobTypeIngest, map[string]string{"date": date.Format("2006-01-02")}), nil
+}
+
+func (s *jobUseCaseStub) StartRetention(ctx context.Context, since time.Duration, dryRun bool) (model.Job, error) {
+	return s.queued(model.JobTypeRetention, map[string]string{"since": since.String(), "dry_run": fmt.Sprint(dryRun)}), nil
+}
+
+func (s *jobUseCaseStub) StartDeleteList(ctx context.Context, id string) (model.Job, error) {
+	return s.queued(model.JobTypeDeleteList, map[string]string{"list_id": id}), nil
+}
+
+func (s *jobUseCaseStub) Get(ctx context.Context, id string) (model.Job, error) {
+	if id != "j1" {
+		return model.Job{}, usecase.ErrJobNotFound
+	}
+	return model.Job{
+		ID:         "j1",
+		Type:       model.JobTypeRetention,
+		Status:     model.JobStatusSucceeded,
+		Params:     map[string]string{"since": "720h0m0s", "dry_run": "true"},
+		CreatedAt:  time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+		StartedAt:  time.Date(2024, 1, 10, 0, 0, 1, 0, time.UTC),
+		FinishedAt: time.Date(2024, 1, 10, 0, 0, 2, 0, time.UTC),
+		Rows:       1,
+		ListIDs:    []string{"Q94V4"},
+	}, nil
+}
+
+func adminRouter(u usecase.JobUseCase) http.Handler {
+	a := NewAdminImpl(u)
+	r := chi.NewRouter()
+	r.Post("/api/v1/admin/ingest", a.Ingest)
+	r.Post("/api/v1/admin/retention", a.Retention)
+	r.Delete("/api/v1/admin/lists/{id}", a.DeleteList)
+	r.Get("/api/v1/admin/jobs/{id}", a.GetJob)
+	return r
+}
+
+func TestAdminImpl_StartJobs(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		wantStatus  int
+		wantCode    string
+		wantStarted string
+	}{
+		{name: "ingest", method: http.MethodPost, path: "/api/v1/admin/ingest", body: `{"date":"2024-01-09"}`, wantStatus: http.StatusAccepted, wantStarted: "ingest map[date:2024-01-09]"},
+		{name: "ingest without date", method: http.MethodPost, path: "/api/v1/admin/ingest", body: `{}`, wantStatus: http.StatusBadRequest, wantCode: "missing_parameter"},
+		{name: "ingest with invalid date", method: http.MethodPost, path: "/api/v1/admin/ingest", body: `{"date":"2024/01/09"}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_parameter"},
+		{name: "unknown field", method: http.MethodPost, path: "/api/v1/admin/ingest", body: `{"date":"2024-01-09","force":true}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_body"},
+		{name: "malformed json", method: http.MethodPost, path: "/api/v1/admin/ingest", body: `{"date":`, wantStatus: http.StatusBadRequest, wantCode: "invalid_body"},
+		{name: "retention", method: http.MethodPost, path: "/api/v1/admin/retention", body: `{"since":30}`, wantStatus: http.StatusAccepted, wantStarted: "retention map[dry_run:false since:720h0m0s]"},
+		{name: "retention dry run", method: http.MethodPost, path: "/api/v1/admin/retention", body: `{"since":0,"dry_run":true}`, wantStatus: http.StatusAccepted, wantStarted: "retention map[dry_run:true since:0s]"},
+		{name: "retention without since", method: http.MethodPost, path: "/api/v1/admin/retention", body: `{"dry_run":true}`, wantStatus: http.StatusBadRequest, wantCode: "missing_parameter"},
+		{name: "retention with negative since", method: http.MethodPost, path: "/api/v1/admin/retention", body: `{"since":-1}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_parameter"},
+		{name: "delete list", method: http.MethodDelete, path: "/api/v1/admin/lists/X5Y7N", wantStatus: http.StatusAccepted, wantStarted: "delete_list map[list_id:X5Y7N]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &jobUseCaseStub{}
+			rec := httptest.NewRecorder()
+			adminRouter(u).ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantCode != "" {
+				var p struct {
+					Code string `json:"code"`
+				}
+				if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+					t.Fatal(err)
+				}
+				if p.Code != tt.wantCode {
+					t.Errorf("expected code %s, got %s", tt.wantCode, p.Code)
+				}
+				if len(u.started) != 0 {
+					t.Errorf("expected no job to start, got %v", u.started)
+				}
+				return
+			}
+
+			if got := rec.Header().Get("Location"); got != "/api/v1/admin/jobs/j1" {
+				t.Errorf("expected the job location, got %q", got)
+			}
+			if len(u.started) != 1 || u.started[0] != tt.wantStarted {
+				t.Errorf("expected %q to start, got %v", tt.wantStarted, u.started)
+			}
+		})
+	}
+}
+
+func TestAdminImpl_GetJob(t *testing.T) {
+	router := adminRouter(&jobUseCaseStub{})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/jobs/j1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	want := `{"id":"j1","type":"retention","status":"succeeded","params":{"dry_run":"true","since":"720h0m0s"},"created_at":"2024-01-10T00:00:00Z","started_at":"2024-01-10T00:00:01Z","finished_at":"2024-01-10T00:00:02Z","rows":1,"list_ids":["Q94V4"]}` + "\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("unexpected body\nwant %s\ngot  %s", want, got)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/jobs/unknown", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
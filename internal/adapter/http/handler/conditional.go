@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+// validator identifies a ranking representation so that clients and caches can revalidate it with a conditional GET.
+// A ranking only changes when the lists it is built from change, so the validator is derived from their IDs and creation dates.
+type validator struct {
+	etag         string
+	lastModified time.Time
+}
+
+// newValidator builds the validator of a ranking that the request r gets in format from the given lists.
+// The ETag also covers the request path and query, so that different rankings built from the same lists get different tags.
+func newValidator(r *http.Request, format responseFormat, listIDs []string, listDates []time.Time) validator {
+	ids := make([]string, 0, len(listIDs))
+	seen := make(map[string]bool, len(listIDs))
+	for _, id := range listIDs {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	h := sha256.New()
+	for _, part := range []string{r.URL.Path, r.URL.Query().Encode(), string(format)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte(strings.Join(ids, ",")))
+
+	var lastModified time.Time
+	for _, date := range listDates {
+		if date.After(lastModified) {
+			lastModified = date
+		}
+	}
+
+	return validator{
+		etag:         `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`,
+		lastModified: lastModified,
+	}
+}
+
+// rankValidator builds the validator of a ranking made of ranks.
+func rankValidator(r *http.Request, format responseFormat, ranks []model.DailyRank) validator {
+	ids := make([]string, 0, len(ranks))
+	dates := make([]time.Time, 0, len(ranks))
+	for _, rank := range ranks {
+		ids = append(ids, rank.ListID)
+		dates = append(dates, rank.Date)
+	}
+	return newValidator(r, format, ids, dates)
+}
+
+// entryValidator builds the validator of a ranking made of entries. Days that are not ingested have no list and are skipped.
+func entryValidator(r *http.Request, format responseFormat, entries []model.DailyRankEntry) validator {
+	ids := make([]string, 0, len(entries))
+	dates := make([]time.Time, 0, len(entries))
+	for _, entry := range entries {
+		if entry.ListID == "" {
+			continue
+		}
+		ids = append(ids, entry.ListID)
+		dates = append(dates, entry.Date)
+	}
+	return newValidator(r, format, ids, dates)
+}
+
+// writeNotModified sets the ETag and Last-Modified headers of v and answers the preconditions of r.
+// It writes 304 Not Modified and returns true when the client already has the current representation.
+// If-None-Match takes precedence over If-Modified-Since as required by RFC 9110.
+// Vary is added here rather than with the representation so that 304 responses carry it too. It is added, not set,
+// to keep the Origin the CORS middleware varies on.
+func writeNotModified(w http.ResponseWriter, r *http.Request, v validator) bool {
+	w.Header().Set("ETag", v.etag)
+	w.Header().Add("Vary", "Accept")
+	if !v.lastModified.IsZero() {
+		w.Header().Set("Last-Modified", v.lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if !isNotModified(r, v) {
+		return false
+	}
+
+	// a 304 carries the validators but no representation headers
+	w.Header().Del("Content-Type")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+func isNotModified(r *http.Request, v validator) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, v.etag)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || v.lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	// HTTP dates have second precision
+	return !v.lastModified.Truncate(time.Second).After(since)
+}
+
+// etagMatches reports whether the If-None-Match header value matches etag using the weak comparison.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+func conditionalMock() UsecaseMock {
+	return UsecaseMock{
+		Domain: "example.com",
+		Start:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:    time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+		Result: []model.DailyRank{
+			{Rank: 10, Date: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), ListID: "B"},
+			{Rank: 20, Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), ListID: "A"},
+		},
+	}
+}
+
+const conditionalURL = "/api/v1/rankings/daily?domain=example.com&start_date=2023-01-01&end_date=2023-01-02"
+
+func serveDaily(t *testing.T, u UsecaseMock, url string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rr := httptest.NewRecorder()
+	NewGetRankingImpl(u).GetDailyRanking(rr, req)
+	return rr
+}
+
+func TestGetDailyRanking_Validators(t *testing.T) {
+	rr := serveDaily(t, conditionalMock(), conditionalURL, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	etag := rr.Header().Get("ETag")
+	if len(etag) < 3 || etag[0] != '"' || etag[len(etag)-1] != '"' {
+		t.Errorf("expected a strong etag, got %q", etag)
+	}
+	if got, want := rr.Header().Get("Last-Modified"), "Mon, 02 Jan 2023 00:00:00 GMT"; got != want {
+		t.Errorf("expected Last-Modified %q, got %q", want, got)
+	}
+	if got := rr.Header().Values("Vary"); len(got) != 1 || got[0] != "Accept" {
+		t.Errorf("expected Vary: Accept once, got %q", got)
+	}
+
+	// the same lists in another order give the same tag
+	reordered := conditionalMock()
+	reordered.Result = []model.DailyRank{reordered.Result[1], reordered.Result[0]}
+	if got := serveDaily(t, reordered, conditionalURL, nil).Header().Get("ETag"); got != etag {
+		t.Errorf("expected etag %s for the same lists, got %s", etag, got)
+	}
+
+	newList := conditionalMock()
+	newList.Result[0].ListID = "C"
+	if got := serveDaily(t, newList, conditionalURL, nil).Header().Get("ETag"); got == etag {
+		t.Errorf("expected a new etag when a list changes")
+	}
+
+	if got := serveDaily(t, conditionalMock(), conditionalURL+"&format=csv", nil).Header().Get("ETag"); got == etag {
+		t.Errorf("expected another etag for another format")
+	}
+}
+
+func TestGetDailyRanking_ConditionalRequests(t *testing.T) {
+	etag := serveDaily(t, conditionalMock(), conditionalURL, nil).Header().Get("ETag")
+
+	tests := []struct {
+		name           string
+		header         http.Header
+		expectedStatus int
+	}{
+		{name: "no preconditions", expectedStatus: http.StatusOK},
+		{name: "matching etag", header: http.Header{"If-None-Match": {etag}}, expectedStatus: http.StatusNotModified},
+		{name: "weak matching etag in list", header: http.Header{"If-None-Match": {`"other", W/` + etag}}, expectedStatus: http.StatusNotModified},
+		{name: "any etag", header: http.Header{"If-None-Match": {"*"}}, expectedStatus: http.StatusNotModified},
+		{name: "stale etag", header: http.Header{"If-None-Match": {`"other"`}}, expectedStatus: http.StatusOK},
+		{name: "not modified since", header: http.Header{"If-Modified-Since": {"Mon, 02 Jan 2023 00:00:00 GMT"}}, expectedStatus: http.StatusNotModified},
+		{name: "modified since", header: http.Header{"If-Modified-Since": {"Sun, 01 Jan 2023 00:00:00 GMT"}}, expectedStatus: http.StatusOK},
+		{name: "invalid date", header: http.Header{"If-Modified-Since": {"yesterday"}}, expectedStatus: http.StatusOK},
+		{
+			name:           "etag wins over date",
+			header:         http.Header{"If-None-Match": {`"other"`}, "If-Modified-Since": {"Mon, 02 Jan 2023 00:00:00 GMT"}},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := serveDaily(t, conditionalMock(), conditionalURL, tt.header)
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+			if rr.Header().Get("ETag") != etag {
+				t.Errorf("expected etag %s, got %s", etag, rr.Header().Get("ETag"))
+			}
+			if tt.expectedStatus == http.StatusNotModified && rr.Body.Len() != 0 {
+				t.Errorf("expected empty body, got %q", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetFilledDailyRanking_ETagCoversIngestedDays(t *testing.T) {
+	rank := 10
+	entries := func(status model.RankStatus, listID string) []model.DailyRankEntry {
+		return []model.DailyRankEntry{
+			{Date: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), Status: status, ListID: listID},
+			{Rank: &rank, Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Status: model.RankStatusRanked, ListID: "A"},
+		}
+	}
+	url := conditionalURL + "&fill=null"
+
+	pending := conditionalMock()
+	pending.Fill = model.FillNull
+	pending.Entries = entries(model.RankStatusNotIngested, "")
+	before := serveDaily(t, pending, url, nil)
+
+	ingested := pending
+	ingested.Entries = entries(model.RankStatusNotRanked, "B")
+	after := serveDaily(t, ingested, url, http.Header{"If-None-Match": {before.Header().Get("ETag")}})
+
+	if after.Code != http.StatusOK {
+		t.Errorf("expected status %d once the missing day is ingested, got %d", http.StatusOK, after.Code)
+	}
+	if got, want := after.Header().Get("Last-Modified"), "Mon, 02 Jan 2023 00:00:00 GMT"; got != want {
+		t.Errorf("expected Last-Modified %q, got %q", want, got)
+	}
+}
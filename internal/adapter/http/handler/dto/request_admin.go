@@ -0,0 +1,11 @@
+package dto
+
+type RequestIngest struct {
+	Date string `json:"date"`
+}
+
+type RequestRetention struct {
+	// Since is how many days of lists are kept. It is a pointer to tell 0 from a missing value.
+	Since  *int `json:"since"`
+	DryRun bool `json:"dry_run"`
+}
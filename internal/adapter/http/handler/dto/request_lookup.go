@@ -0,0 +1,6 @@
+package dto
+
+type RequestLookup struct {
+	Date    string   `json:"date"`
+	Domains []string `json:"domains"`
+}
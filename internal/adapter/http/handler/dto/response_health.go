@@ -0,0 +1,22 @@
+package dto
+
+type ResponseHealth struct {
+	Status string                `json:"status"`
+	Checks *ResponseHealthChecks `json:"checks,omitempty"`
+}
+
+type ResponseHealthChecks struct {
+	Database ResponseDatabaseCheck `json:"database"`
+	Data     ResponseDataCheck     `json:"data"`
+}
+
+type ResponseDatabaseCheck struct {
+	Status string `json:"status"`
+}
+
+type ResponseDataCheck struct {
+	Status              string `json:"status"`
+	LatestListID        string `json:"latest_list_id,omitempty"`
+	LatestListCreatedOn string `json:"latest_list_created_on,omitempty"`
+	AgeSeconds          *int64 `json:"age_seconds,omitempty"`
+}
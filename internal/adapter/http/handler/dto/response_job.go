@@ -0,0 +1,14 @@
+package dto
+
+type ResponseJob struct {
+	ID         string            `json:"id"`
+	Type       string            `json:"type"`
+	Status     string            `json:"status"`
+	Params     map[string]string `json:"params"`
+	CreatedAt  string            `json:"created_at"`
+	StartedAt  string            `json:"started_at,omitempty"`
+	FinishedAt string            `json:"finished_at,omitempty"`
+	Rows       int               `json:"rows"`
+	ListIDs    []string          `json:"list_ids,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+type ResponseList struct {
+	ID        string `json:"id"`
+	CreatedOn string `json:"created_on"`
+	Rows      int    `json:"rows"`
+}
+
+type ResponseLists struct {
+	Lists []ResponseList `json:"lists"`
+}
@@ -0,0 +1,7 @@
+package dto
+
+// ResponseLookupRank is the rank of one looked up domain. Rank is null when the domain is not ranked.
+type ResponseLookupRank struct {
+	Domain string `json:"domain"`
+	Rank   *int   `json:"rank"`
+}
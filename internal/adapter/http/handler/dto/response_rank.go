@@ -1,6 +1,8 @@
 package dto
 
 type ResponseRank struct {
-	Rank int    `json:"rank"`
-	Date string `json:"date"`
+	Rank   *int   `json:"rank"`
+	Date   string `json:"date"`
+	Status string `json:"status,omitempty"`
+	Filled bool   `json:"filled,omitempty"`
 }
@@ -0,0 +1,12 @@
+package dto
+
+type ResponseRanking struct {
+	Ranks  []ResponseRank `json:"ranks"`
+	Domain string         `json:"domain"`
+}
+
+// ResponseRankRecord is one line of a ranking streamed as NDJSON.
+type ResponseRankRecord struct {
+	Domain string `json:"domain"`
+	ResponseRank
+}
@@ -0,0 +1,30 @@
+package dto
+
+type ResponseTLDCount struct {
+	TLD     string  `json:"tld"`
+	Domains int     `json:"domains"`
+	Share   float64 `json:"share"`
+}
+
+type ResponseTLDStats struct {
+	Date   string             `json:"date"`
+	ListID string             `json:"list_id"`
+	Top    int                `json:"top"`
+	Total  int                `json:"total"`
+	TLDs   []ResponseTLDCount `json:"tlds"`
+}
+
+type ResponseTLDHistoryPoint struct {
+	Date         string             `json:"date"`
+	ListID       string             `json:"list_id"`
+	Total        int                `json:"total"`
+	TLDs         []ResponseTLDCount `json:"tlds"`
+	OtherDomains int                `json:"other_domains"`
+	OtherShare   float64            `json:"other_share"`
+}
+
+type ResponseTLDHistory struct {
+	Top    int                       `json:"top"`
+	TLDs   []string                  `json:"tlds"`
+	Points []ResponseTLDHistoryPoint `json:"points"`
+}
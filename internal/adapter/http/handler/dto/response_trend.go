@@ -0,0 +1,16 @@
+package dto
+
+type ResponseTrend struct {
+	Domain       string  `json:"domain"`
+	StartDate    string  `json:"start_date"`
+	EndDate      string  `json:"end_date"`
+	Points       int     `json:"points"`
+	FirstRank    int     `json:"first_rank"`
+	LastRank     int     `json:"last_rank"`
+	Slope        float64 `json:"slope"`
+	StdDev       float64 `json:"std_dev"`
+	MaxDrawdown  int     `json:"max_drawdown"`
+	DrawdownFrom *string `json:"drawdown_from"`
+	DrawdownTo   *string `json:"drawdown_to"`
+	Class        string  `json:"class"`
+}
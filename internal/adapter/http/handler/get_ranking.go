@@ -1,15 +1,16 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
 	"time"
 
 	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
 
 	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/handler/dto"
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/problem"
 
 	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -31,51 +32,72 @@ func (g GetRankingImpl) GetDailyRanking(w http.ResponseWriter, r *http.Request)
 	startDateStr := r.URL.Query().Get("start_date")
 	endDateStr := r.URL.Query().Get("end_date")
 
-	if domain == "" || startDateStr == "" || endDateStr == "" {
-		http.Error(w, "Bad Request: Missing or invalid query parameters", http.StatusBadRequest)
+	if param := firstMissingParam(r.URL.Query(), "domain", "start_date", "end_date"); param != "" {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeMissingParameter, param, "Missing required query parameter")
 		return
 	}
 
 	startDate, err := time.Parse("2006-01-02", startDateStr)
 	if err != nil {
-		http.Error(w, "Bad Request: Invalid start_date format", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "start_date", "start_date must be in YYYY-MM-DD format")
 		return
 	}
 	endDate, err := time.Parse("2006-01-02", endDateStr)
 	if err != nil {
-		http.Error(w, "Bad Request: Invalid end_date format", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "end_date", "end_date must be in YYYY-MM-DD format")
 		return
 	}
 
 	if startDate.After(endDate) {
-		http.Error(w, "start_date should be before or equal to end_date", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidRange, "start_date", "start_date should be before or equal to end_date")
 		return
 	}
 
-	ranks, err := g.u.GetDailyRanking(r.Context(), domain, startDate, endDate)
+	fill, err := model.ParseFillMode(r.URL.Query().Get("fill"))
 	if err != nil {
-		log.WithContext(r.Context()).WithFields(log.Fields{"error": err, "domain": domain, "stat_date": startDateStr, "end_date": endDateStr}).Error("GetDailyRanking usecase returned error while processing daily ranking")
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "fill", "fill must be one of null, previous or interpolate")
+		return
+	}
+
+	domain, rollup, ok := parseRollup(w, r, domain)
+	if !ok {
+		return
+	}
+
+	format, err := negotiateFormat(r)
+	if err != nil {
+		writeFormatError(w, r, err)
+		return
+	}
+
+	filename := domain + "_daily_" + startDateStr + "_" + endDateStr
+
+	if fill != model.FillNone {
+		g.getFilledDailyRanking(w, r, domain, rollup, startDate, endDate, fill, format, filename)
+		return
+	}
+
+	ranks, err := g.u.GetDailyRanking(r.Context(), domain, rollup, startDate, endDate)
+	if err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err, "domain": domain, "stat_date": startDateStr, "end_date": endDateStr}).Error("GetDailyRanking usecase returned error while processing daily ranking")
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal, "", "")
 		return
 	}
 
 	if len(ranks) == 0 {
-		http.Error(w, "Not Found: No rankings available for the given period", http.StatusNotFound)
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "", "No rankings available for the given domain and period")
 		return
 	}
 
 	responseRanks := make([]dto.ResponseRank, len(ranks))
 	for i, rank := range ranks {
 		responseRanks[i] = dto.ResponseRank{
-			Rank: rank.Rank,
+			Rank: &rank.Rank,
 			Date: rank.Date.UTC().Format("2006-01-02"),
 		}
 	}
 
-	resp := struct {
-		Ranks  []dto.ResponseRank `json:"ranks"`
-		Domain string             `json:"domain"`
-	}{
+	resp := dto.ResponseRanking{
 		Ranks:  responseRanks,
 		Domain: domain,
 	}
@@ -84,13 +106,90 @@ func (g GetRankingImpl) GetDailyRanking(w http.ResponseWriter, r *http.Request)
 		w.Header().Set("Cache-Control", "max-age=86400")
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		log.WithContext(r.Context()).WithFields(log.Fields{"error": err, "domain": domain, "stat_date": startDateStr, "end_date": endDateStr}).Error("cannot marshall to resp json while processing daily ranking")
-		http.Error(w, "Failed to encode the resp", http.StatusInternalServerError)
+	if writeNotModified(w, r, rankValidator(r, format, ranks)) {
+		return
+	}
+
+	if err := writeRanking(w, format, filename, resp); err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err, "domain": domain, "stat_date": startDateStr, "end_date": endDateStr, "format": format}).Error("cannot write resp while processing daily ranking")
 	}
 }
 
+// getFilledDailyRanking writes a daily ranking that has an entry for every requested day.
+func (g GetRankingImpl) getFilledDailyRanking(w http.ResponseWriter, r *http.Request, domain string, rollup model.Rollup, startDate, endDate time.Time, fill model.FillMode, format responseFormat, filename string) {
+	entries, err := g.u.GetDailyRankingWithGaps(r.Context(), domain, rollup, startDate, endDate, fill)
+	if err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err, "domain": domain, "stat_date": startDate, "end_date": endDate, "fill": fill}).Error("GetDailyRankingWithGaps usecase returned error while processing daily ranking")
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal, "", "")
+		return
+	}
+
+	if !hasRankedEntry(entries) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "", "No rankings available for the given domain and period")
+		return
+	}
+
+	responseRanks := make([]dto.ResponseRank, len(entries))
+	for i, entry := range entries {
+		responseRanks[i] = dto.ResponseRank{
+			Rank:   entry.Rank,
+			Date:   entry.Date.UTC().Format("2006-01-02"),
+			Status: string(entry.Status),
+			Filled: entry.Filled,
+		}
+	}
+
+	resp := dto.ResponseRanking{
+		Ranks:  responseRanks,
+		Domain: domain,
+	}
+
+	// days whose list is not ingested yet may still change, so only complete histories are cached.
+	if isEveryDayIngested(entries) {
+		w.Header().Set("Cache-Control", "max-age=86400")
+	}
+
+	if writeNotModified(w, r, entryValidator(r, format, entries)) {
+		return
+	}
+
+	if err := writeRanking(w, format, filename, resp); err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err, "domain": domain, "stat_date": startDate, "end_date": endDate, "fill": fill, "format": format}).Error("cannot write resp while processing daily ranking")
+	}
+}
+
+// parseRollup reads the rollup query parameter. When ranks are rolled up, the registrable domain of domain is returned in its place
+// so that it is reported in the response and every subdomain shares the same cached history.
+func parseRollup(w http.ResponseWriter, r *http.Request, domain string) (string, model.Rollup, bool) {
+	rollup, err := model.ParseRollup(r.URL.Query().Get("rollup"))
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "rollup", "rollup must be registrable")
+		return "", model.RollupNone, false
+	}
+	if rollup == model.RollupRegistrable {
+		domain = model.ParseDomainName(domain).Registrable
+	}
+	return domain, rollup, true
+}
+
+func hasRankedEntry(entries []model.DailyRankEntry) bool {
+	for _, entry := range entries {
+		if entry.Status == model.RankStatusRanked {
+			return true
+		}
+	}
+	return false
+}
+
+func isEveryDayIngested(entries []model.DailyRankEntry) bool {
+	for _, entry := range entries {
+		if entry.Status == model.RankStatusNotIngested {
+			return false
+		}
+	}
+	return true
+}
+
 func isIncludingEveryDayRecord(start, end time.Time, ranks []model.DailyRank) bool {
 	d := end.Add(time.Hour * 24).Sub(start)
 	return int(d.Hours()/24) == len(ranks)
@@ -101,53 +200,61 @@ func (g GetRankingImpl) GetMonthlyRanking(w http.ResponseWriter, r *http.Request
 	startMonthStr := r.URL.Query().Get("start_month")
 	endMonthStr := r.URL.Query().Get("end_month")
 
-	if domain == "" || startMonthStr == "" || endMonthStr == "" {
-		http.Error(w, "Bad Request: Missing or invalid query parameters", http.StatusBadRequest)
+	if param := firstMissingParam(r.URL.Query(), "domain", "start_month", "end_month"); param != "" {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeMissingParameter, param, "Missing required query parameter")
 		return
 	}
 
 	// "2023-12" -> 2023-12-01 00:00:00 +0000
 	startMonth, err := time.Parse("2006-01", startMonthStr)
 	if err != nil {
-		http.Error(w, "Invalid start_month format", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "start_month", "start_month must be in YYYY-MM format")
 		return
 	}
 
 	endMonth, err := time.Parse("2006-01", endMonthStr)
 	if err != nil {
-		http.Error(w, "Invalid end_month format", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "end_month", "end_month must be in YYYY-MM format")
 		return
 	}
 
 	if startMonth.After(endMonth) {
-		http.Error(w, "start_month should be before or equal to end_month", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidRange, "start_month", "start_month should be before or equal to end_month")
+		return
+	}
+
+	domain, rollup, ok := parseRollup(w, r, domain)
+	if !ok {
+		return
+	}
+
+	format, err := negotiateFormat(r)
+	if err != nil {
+		writeFormatError(w, r, err)
 		return
 	}
 
-	ranks, err := g.u.GetMonthlyRanking(r.Context(), domain, getLastDayOfMonth(startMonth), getLastDayOfMonth(endMonth))
+	ranks, err := g.u.GetMonthlyRanking(r.Context(), domain, rollup, getLastDayOfMonth(startMonth), getLastDayOfMonth(endMonth))
 	if err != nil {
-		log.WithContext(r.Context()).WithFields(log.Fields{"error": err, "domain": domain, "stat_date": startMonthStr, "end_date": endMonthStr}).Error("GetMonthlyRanking usecase returned error while processing monthly ranking")
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err, "domain": domain, "stat_date": startMonthStr, "end_date": endMonthStr}).Error("GetMonthlyRanking usecase returned error while processing monthly ranking")
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal, "", "")
 		return
 	}
 
 	if len(ranks) == 0 {
-		http.Error(w, "No ranks found for the given domain and date range", http.StatusNotFound)
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "", "No rankings available for the given domain and period")
 		return
 	}
 
 	responseRanks := make([]dto.ResponseRank, len(ranks))
 	for i, rank := range ranks {
 		responseRanks[i] = dto.ResponseRank{
-			Rank: rank.Rank,
+			Rank: &rank.Rank,
 			Date: rank.Date.UTC().Format("2006-01-02"),
 		}
 	}
 
-	resp := struct {
-		Ranks  []dto.ResponseRank `json:"ranks"`
-		Domain string             `json:"domain"`
-	}{
+	resp := dto.ResponseRanking{
 		Ranks:  responseRanks,
 		Domain: domain,
 	}
@@ -156,10 +263,13 @@ func (g GetRankingImpl) GetMonthlyRanking(w http.ResponseWriter, r *http.Request
 		w.Header().Set("Cache-Control", "max-age=86400")
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		log.WithContext(r.Context()).WithFields(log.Fields{"error": err, "domain": domain, "stat_date": startMonthStr, "end_date": endMonthStr}).Error("cannot marshall to response json while processing monthly ranking")
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	if writeNotModified(w, r, rankValidator(r, format, ranks)) {
+		return
+	}
+
+	filename := domain + "_monthly_" + startMonthStr + "_" + endMonthStr
+	if err := writeRanking(w, format, filename, resp); err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err, "domain": domain, "stat_date": startMonthStr, "end_date": endMonthStr, "format": format}).Error("cannot write response while processing monthly ranking")
 	}
 }
 
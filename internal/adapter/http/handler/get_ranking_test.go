@@ -15,22 +15,32 @@ import (
 )
 
 type UsecaseMock struct {
-	Domain string
-	Start  time.Time
-	End    time.Time
-	Result []model.DailyRank
-	Err    error
+	Domain  string
+	Rollup  model.Rollup
+	Start   time.Time
+	End     time.Time
+	Fill    model.FillMode
+	Result  []model.DailyRank
+	Entries []model.DailyRankEntry
+	Err     error
 }
 
-func (m UsecaseMock) GetDailyRanking(ctx context.Context, domain string, start time.Time, end time.Time) ([]model.DailyRank, error) {
-	if m.Domain == domain && m.Start.Equal(start) && m.End.Equal(end) {
+func (m UsecaseMock) GetDailyRanking(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	if m.Domain == domain && m.Rollup == rollup && m.Start.Equal(start) && m.End.Equal(end) {
 		return m.Result, m.Err
 	}
 	return nil, errors.New("unexpected parameters")
 }
 
-func (m UsecaseMock) GetMonthlyRanking(ctx context.Context, domain string, start time.Time, end time.Time) ([]model.DailyRank, error) {
-	if m.Domain == domain && m.Start.Equal(start) && m.End.Equal(end) {
+func (m UsecaseMock) GetDailyRankingWithGaps(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time, fill model.FillMode) ([]model.DailyRankEntry, error) {
+	if m.Domain == domain && m.Rollup == rollup && m.Start.Equal(start) && m.End.Equal(end) && m.Fill == fill {
+		return m.Entries, m.Err
+	}
+	return nil, errors.New("unexpected parameters")
+}
+
+func (m UsecaseMock) GetMonthlyRanking(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	if m.Domain == domain && m.Rollup == rollup && m.Start.Equal(start) && m.End.Equal(end) {
 		return m.Result, m.Err
 	}
 	return nil, errors.New("unexpected parameters")
@@ -83,6 +93,29 @@ func TestGetRankingImpl_GetDailyRanking(t *testing.T) {
 			expectedRanks:  2,
 			hasCacheHeader: false,
 		},
+		{
+			name: "rolled up to the registrable domain",
+			mockUsecase: UsecaseMock{
+				Domain: "google.com",
+				Rollup: model.RollupRegistrable,
+				Start:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				End:    time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				Result: []model.DailyRank{
+					{Rank: 1, Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+				},
+			},
+			requestURL:     "/api/v1/rankings/daily?domain=mail.google.com&start_date=2023-01-01&end_date=2023-01-01&rollup=registrable",
+			expectedStatus: http.StatusOK,
+			expectedDomain: "google.com",
+			expectedRanks:  1,
+			hasCacheHeader: true,
+		},
+		{
+			name:           "unknown rollup",
+			mockUsecase:    UsecaseMock{},
+			requestURL:     "/api/v1/rankings/daily?domain=mail.google.com&start_date=2023-01-01&end_date=2023-01-01&rollup=tld",
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name:           "empty start date request",
 			mockUsecase:    UsecaseMock{},
@@ -195,6 +228,125 @@ func TestGetRankingImpl_GetDailyRanking(t *testing.T) {
 	}
 }
 
+func TestGetRankingImpl_GetDailyRanking_Fill(t *testing.T) {
+	rank := func(r int) *int { return &r }
+
+	tests := []struct {
+		name           string
+		mockUsecase    UsecaseMock
+		requestURL     string
+		expectedStatus int
+		expectedBody   string
+		hasCacheHeader bool
+	}{
+		{
+			name: "fill with previous",
+			mockUsecase: UsecaseMock{
+				Domain: "example.com",
+				Start:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				End:    time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC),
+				Fill:   model.FillPrevious,
+				Entries: []model.DailyRankEntry{
+					{Rank: rank(1), Date: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC), Status: model.RankStatusNotRanked, Filled: true},
+					{Rank: rank(1), Date: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), Status: model.RankStatusRanked},
+					{Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Status: model.RankStatusNotRanked},
+				},
+			},
+			requestURL:     "/api/v1/rankings/daily?domain=example.com&start_date=2023-01-01&end_date=2023-01-03&fill=previous",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"ranks":[{"rank":1,"date":"2023-01-03","status":"not_ranked","filled":true},{"rank":1,"date":"2023-01-02","status":"ranked"},{"rank":null,"date":"2023-01-01","status":"not_ranked"}],"domain":"example.com"}`,
+			hasCacheHeader: true,
+		},
+		{
+			name: "fill with null and a day is not ingested",
+			mockUsecase: UsecaseMock{
+				Domain: "example.com",
+				Start:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				End:    time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+				Fill:   model.FillNull,
+				Entries: []model.DailyRankEntry{
+					{Date: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), Status: model.RankStatusNotIngested},
+					{Rank: rank(5), Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Status: model.RankStatusRanked},
+				},
+			},
+			requestURL:     "/api/v1/rankings/daily?domain=example.com&start_date=2023-01-01&end_date=2023-01-02&fill=null",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"ranks":[{"rank":null,"date":"2023-01-02","status":"not_ingested"},{"rank":5,"date":"2023-01-01","status":"ranked"}],"domain":"example.com"}`,
+			hasCacheHeader: false,
+		},
+		{
+			name:           "invalid fill",
+			mockUsecase:    UsecaseMock{},
+			requestURL:     "/api/v1/rankings/daily?domain=example.com&start_date=2023-01-01&end_date=2023-01-02&fill=zero",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "no ranked day",
+			mockUsecase: UsecaseMock{
+				Domain: "example.com",
+				Start:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				End:    time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				Fill:   model.FillInterpolate,
+				Entries: []model.DailyRankEntry{
+					{Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Status: model.RankStatusNotRanked},
+				},
+			},
+			requestURL:     "/api/v1/rankings/daily?domain=example.com&start_date=2023-01-01&end_date=2023-01-01&fill=interpolate",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "get error while fetching ranking data",
+			mockUsecase: UsecaseMock{
+				Domain: "example.com",
+				Start:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				End:    time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				Fill:   model.FillNull,
+				Err:    errors.New("test"),
+			},
+			requestURL:     "/api/v1/rankings/daily?domain=example.com&start_date=2023-01-01&end_date=2023-01-01&fill=null",
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.requestURL, nil)
+
+			rec := httptest.NewRecorder()
+			handler := NewGetRankingImpl(tt.mockUsecase)
+			handlerFunc := http.HandlerFunc(handler.GetDailyRanking)
+			handlerFunc.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if tt.expectedStatus == http.StatusOK {
+				var body map[string]interface{}
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+					t.Fatal(err)
+				}
+
+				expectedBodyMap := map[string]interface{}{}
+				if err := json.Unmarshal([]byte(tt.expectedBody), &expectedBodyMap); err != nil {
+					t.Fatal(err)
+				}
+
+				if diff := cmp.Diff(expectedBodyMap, body); diff != "" {
+					t.Errorf("unexpected response (-want +got):\n%s", diff)
+				}
+			}
+
+			c := rec.Header().Get("Cache-Control")
+			if tt.hasCacheHeader && (len(c) == 0) {
+				t.Errorf("header has been expected.")
+			} else if !tt.hasCacheHeader && (len(c) > 0) {
+				t.Errorf("header has not been expected. but got %s", c)
+			}
+		})
+	}
+}
+
 func TestGetRankingImpl_GetMonthlyRanking(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -242,35 +394,35 @@ func TestGetRankingImpl_GetMonthlyRanking(t *testing.T) {
 			mockUsecase:    UsecaseMock{},
 			requestURL:     "/api/v1/rankings/monthly?domain=example.com&end_month=2023-12",
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   "Bad Request: Missing or invalid query parameters",
+			expectedBody:   `{"type":"about:blank","title":"Bad Request","status":400,"detail":"Missing required query parameter","instance":"/api/v1/rankings/monthly","code":"missing_parameter","param":"start_month"}`,
 		},
 		{
 			name:           "empty end month request",
 			mockUsecase:    UsecaseMock{},
 			requestURL:     "/api/v1/rankings/monthly?domain=example.com&start_month=2023-01&end_month=",
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   "Bad Request: Missing or invalid query parameters",
+			expectedBody:   `{"type":"about:blank","title":"Bad Request","status":400,"detail":"Missing required query parameter","instance":"/api/v1/rankings/monthly","code":"missing_parameter","param":"end_month"}`,
 		},
 		{
 			name:           "invalid start month request",
 			mockUsecase:    UsecaseMock{},
 			requestURL:     "/api/v1/rankings/monthly?domain=example.com&start_month=2023-01-01&end_month=2023-12",
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   "Invalid start_month format",
+			expectedBody:   `{"type":"about:blank","title":"Bad Request","status":400,"detail":"start_month must be in YYYY-MM format","instance":"/api/v1/rankings/monthly","code":"invalid_parameter","param":"start_month"}`,
 		},
 		{
 			name:           "invalid end month request",
 			mockUsecase:    UsecaseMock{},
 			requestURL:     "/api/v1/rankings/monthly?domain=example.com&start_month=2023-01&end_month=XXX",
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   "Invalid end_month format",
+			expectedBody:   `{"type":"about:blank","title":"Bad Request","status":400,"detail":"end_month must be in YYYY-MM format","instance":"/api/v1/rankings/monthly","code":"invalid_parameter","param":"end_month"}`,
 		},
 		{
 			name:           "start date after end date request",
 			mockUsecase:    UsecaseMock{},
 			requestURL:     "/api/v1/rankings/monthly?domain=example.com&start_month=2023-01&end_month=2022-12",
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   "start_month should be before or equal to end_month",
+			expectedBody:   `{"type":"about:blank","title":"Bad Request","status":400,"detail":"start_month should be before or equal to end_month","instance":"/api/v1/rankings/monthly","code":"invalid_range","param":"start_month"}`,
 		},
 		{
 			name: "get error while fetching ranking data",
@@ -283,7 +435,7 @@ func TestGetRankingImpl_GetMonthlyRanking(t *testing.T) {
 			},
 			requestURL:     "/api/v1/rankings/monthly?domain=example.com&start_month=2023-01&end_month=2023-12",
 			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   http.StatusText(http.StatusInternalServerError),
+			expectedBody:   `{"type":"about:blank","title":"Internal Server Error","status":500,"instance":"/api/v1/rankings/monthly","code":"internal_error"}`,
 		},
 		{
 			name: "get no data about requested domain",
@@ -295,7 +447,7 @@ func TestGetRankingImpl_GetMonthlyRanking(t *testing.T) {
 			},
 			requestURL:     "/api/v1/rankings/monthly?domain=example.com&start_month=2023-01&end_month=2023-12",
 			expectedStatus: http.StatusNotFound,
-			expectedBody:   "No ranks found for the given domain and date range",
+			expectedBody:   `{"type":"about:blank","title":"Not Found","status":404,"detail":"No rankings available for the given domain and period","instance":"/api/v1/rankings/monthly","code":"not_found"}`,
 		},
 	}
 
@@ -312,23 +464,23 @@ func TestGetRankingImpl_GetMonthlyRanking(t *testing.T) {
 				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
 			}
 
-			if tt.expectedStatus == http.StatusOK {
-				var body map[string]interface{}
-				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
-					t.Fatal(err)
-				}
+			var body map[string]interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
 
-				expectedBodyMap := map[string]interface{}{}
-				if err := json.Unmarshal([]byte(tt.expectedBody), &expectedBodyMap); err != nil {
-					t.Fatal(err)
-				}
+			expectedBodyMap := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(tt.expectedBody), &expectedBodyMap); err != nil {
+				t.Fatal(err)
+			}
 
-				if diff := cmp.Diff(expectedBodyMap, body); diff != "" {
-					t.Errorf("unexpected response (-want +got):\n%s", diff)
-				}
-			} else {
-				if body := rec.Body.String(); body != tt.expectedBody+"\n" {
-					t.Errorf("expected body %q, got %q", tt.expectedBody, body)
+			if diff := cmp.Diff(expectedBodyMap, body); diff != "" {
+				t.Errorf("unexpected response (-want +got):\n%s", diff)
+			}
+
+			if tt.expectedStatus != http.StatusOK {
+				if c := rec.Header().Get("Content-Type"); c != "application/problem+json" {
+					t.Errorf("expected problem content type, got %s", c)
 				}
 			}
 
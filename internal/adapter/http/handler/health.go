@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/handler/dto"
+	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+type Health interface {
+	Live(w http.ResponseWriter, r *http.Request)
+	Ready(w http.ResponseWriter, r *http.Request)
+}
+
+type HealthImpl struct {
+	u usecase.ReadinessUseCase
+}
+
+func NewHealthImpl(u usecase.ReadinessUseCase) *HealthImpl {
+	return &HealthImpl{u: u}
+}
+
+// Live reports that the process is up. It does not touch the database so that a database outage does not restart the server.
+func (h HealthImpl) Live(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, r, http.StatusOK, dto.ResponseHealth{Status: "ok"})
+}
+
+// Ready reports whether the database is reachable and the latest list is fresh, responding 503 otherwise.
+func (h HealthImpl) Ready(w http.ResponseWriter, r *http.Request) {
+	readiness := h.u.Check(r.Context())
+
+	checks := &dto.ResponseHealthChecks{
+		Database: dto.ResponseDatabaseCheck{Status: "up"},
+		Data:     dto.ResponseDataCheck{Status: "fresh"},
+	}
+	if readiness.DatabaseErr != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": readiness.DatabaseErr}).Error("database is not reachable in readiness check")
+		checks.Database.Status = "down"
+		checks.Data.Status = "unknown"
+	} else if readiness.Stale {
+		checks.Data.Status = "stale"
+	}
+	if readiness.LatestList.ID != "" {
+		age := int64(readiness.Age / time.Second)
+		checks.Data.LatestListID = readiness.LatestList.ID
+		checks.Data.LatestListCreatedOn = readiness.LatestList.CreatedOn.UTC().Format("2006-01-02")
+		checks.Data.AgeSeconds = &age
+	}
+
+	if !readiness.Ready() {
+		writeHealth(w, r, http.StatusServiceUnavailable, dto.ResponseHealth{Status: "not_ready", Checks: checks})
+		return
+	}
+	writeHealth(w, r, http.StatusOK, dto.ResponseHealth{Status: "ready", Checks: checks})
+}
+
+func writeHealth(w http.ResponseWriter, r *http.Request, status int, resp dto.ResponseHealth) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err}).Error("cannot write health response")
+	}
+}
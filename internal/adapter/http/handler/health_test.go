@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+type readinessStub struct {
+	readiness model.Readiness
+}
+
+func (s readinessStub) Check(ctx context.Context) model.Readiness {
+	return s.readiness
+}
+
+func TestHealthImpl_Ready(t *testing.T) {
+	tests := []struct {
+		name       string
+		readiness  model.Readiness
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "ready",
+			readiness:  model.Readiness{LatestList: model.TrancoList{ID: "X5Y7N", CreatedOn: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)}, Age: time.Hour},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"ready","checks":{"database":{"status":"up"},"data":{"status":"fresh","latest_list_id":"X5Y7N","latest_list_created_on":"2024-01-09","age_seconds":3600}}}` + "\n",
+		},
+		{
+			name:       "stale",
+			readiness:  model.Readiness{LatestList: model.TrancoList{ID: "X5Y7N", CreatedOn: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, Age: 100 * time.Hour, Stale: true},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"status":"not_ready","checks":{"database":{"status":"up"},"data":{"status":"stale","latest_list_id":"X5Y7N","latest_list_created_on":"2024-01-01","age_seconds":360000}}}` + "\n",
+		},
+		{
+			name:       "database down",
+			readiness:  model.Readiness{DatabaseErr: errors.New("connection refused"), Stale: true},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"status":"not_ready","checks":{"database":{"status":"down"},"data":{"status":"unknown"}}}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealthImpl(readinessStub{readiness: tt.readiness})
+			rec := httptest.NewRecorder()
+			h.Ready(rec, httptest.NewRequest(http.MethodGet, "/healthz/ready", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if rec.Header().Get("Cache-Control") != "no-store" {
+				t.Errorf("expected no-store, got %q", rec.Header().Get("Cache-Control"))
+			}
+			if diff := cmp.Diff(tt.wantBody, rec.Body.String()); diff != "" {
+				t.Errorf("unexpected body (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
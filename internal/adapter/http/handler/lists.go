@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/handler/dto"
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/problem"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// Without from or to the catalogue is unbounded on that side.
+var (
+	earliestListDate = time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+	latestListDate   = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+)
+
+type Lists interface {
+	GetLists(w http.ResponseWriter, r *http.Request)
+	GetLatestList(w http.ResponseWriter, r *http.Request)
+}
+
+type ListsImpl struct {
+	u usecase.ListCatalogUseCase
+}
+
+func NewListsImpl(u usecase.ListCatalogUseCase) *ListsImpl {
+	return &ListsImpl{u: u}
+}
+
+// GetLists returns the stored lists created from the optional from date to the optional to date, oldest first.
+func (l ListsImpl) GetLists(w http.ResponseWriter, r *http.Request) {
+	from, to := earliestListDate, latestListDate
+	var err error
+	if s := r.URL.Query().Get("from"); s != "" {
+		from, err = time.Parse("2006-01-02", s)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "from", "from must be in YYYY-MM-DD format")
+			return
+		}
+	}
+	if s := r.URL.Query().Get("to"); s != "" {
+		to, err = time.Parse("2006-01-02", s)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "to", "to must be in YYYY-MM-DD format")
+			return
+		}
+	}
+	if from.After(to) {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidRange, "from", "from should be before or equal to to")
+		return
+	}
+
+	lists, err := l.u.GetLists(r.Context(), from, to)
+	if err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err, "from": from, "to": to}).Error("GetLists usecase returned error while processing lists")
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal, "", "")
+		return
+	}
+
+	resp := dto.ResponseLists{Lists: make([]dto.ResponseList, len(lists))}
+	for i, list := range lists {
+		resp.Lists[i] = toResponseList(list)
+	}
+	writeLists(w, r, lists, resp)
+}
+
+// GetLatestList returns the most recently created list.
+func (l ListsImpl) GetLatestList(w http.ResponseWriter, r *http.Request) {
+	list, err := l.u.GetLatestList(r.Context())
+	if errors.Is(err, usecase.ErrListNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "", "No list is stored yet")
+		return
+	}
+	if err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err}).Error("GetLatestList usecase returned error while processing latest list")
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal, "", "")
+		return
+	}
+
+	writeLists(w, r, []model.TrancoListSummary{list}, toResponseList(list))
+}
+
+func toResponseList(list model.TrancoListSummary) dto.ResponseList {
+	return dto.ResponseList{ID: list.ID, CreatedOn: list.CreatedOn.UTC().Format("2006-01-02"), Rows: list.Rows}
+}
+
+// writeLists writes resp, built from lists, as JSON. Lists never change once saved and only come and go,
+// so their IDs make an ETag for conditional GETs. There is no Last-Modified, since deleting a list
+// changes the catalogue without a newer creation date.
+func writeLists(w http.ResponseWriter, r *http.Request, lists []model.TrancoListSummary, resp any) {
+	ids := make([]string, len(lists))
+	for i, list := range lists {
+		ids[i] = list.ID
+	}
+	if writeNotModified(w, r, newValidator(r, formatJSON, ids, nil)) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err}).Error("cannot write lists response")
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
+)
+
+type listCatalogStub struct {
+	lists      []model.TrancoListSummary
+	start, end time.Time
+}
+
+func (s *listCatalogStub) GetLists(ctx context.Context, start time.Time, end time.Time) ([]model.TrancoListSummary, error) {
+	s.start, s.end = start, end
+	return s.lists, nil
+}
+
+func (s *listCatalogStub) GetLatestList(ctx context.Context) (model.TrancoListSummary, error) {
+	if len(s.lists) == 0 {
+		return model.TrancoListSummary{}, usecase.ErrListNotFound
+	}
+	return s.lists[len(s.lists)-1], nil
+}
+
+func TestListsImpl_GetLists(t *testing.T) {
+	lists := []model.TrancoListSummary{
+		{TrancoList: model.TrancoList{ID: "Q94V4", CreatedOn: time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)}, Rows: 1000000},
+		{TrancoList: model.TrancoList{ID: "X5Y7N", CreatedOn: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)}, Rows: 999999},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantStart  time.Time
+		wantEnd    time.Time
+		wantBody   string
+	}{
+		{
+			name:       "range",
+			query:      "?from=2024-01-08&to=2024-01-09",
+			wantStatus: http.StatusOK,
+			wantStart:  time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC),
+			wantEnd:    time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC),
+			wantBody:   `{"lists":[{"id":"Q94V4","created_on":"2024-01-08","rows":1000000},{"id":"X5Y7N","created_on":"2024-01-09","rows":999999}]}` + "\n",
+		},
+		{
+			name:       "unbounded",
+			wantStatus: http.StatusOK,
+			wantStart:  earliestListDate,
+			wantEnd:    latestListDate,
+		},
+		{name: "invalid from", query: "?from=2024-1-8", wantStatus: http.StatusBadRequest},
+		{name: "inverted range", query: "?from=2024-01-09&to=2024-01-08", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &listCatalogStub{lists: lists}
+			rec := httptest.NewRecorder()
+			NewListsImpl(u).GetLists(rec, httptest.NewRequest(http.MethodGet, "/api/v1/lists"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if !u.start.Equal(tt.wantStart) || !u.end.Equal(tt.wantEnd) {
+				t.Errorf("expected range %s to %s, got %s to %s", tt.wantStart, tt.wantEnd, u.start, u.end)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("unexpected body\nwant %s\ngot  %s", tt.wantBody, rec.Body.String())
+			}
+			if rec.Header().Get("ETag") == "" {
+				t.Error("expected an ETag")
+			}
+		})
+	}
+}
+
+func TestListsImpl_GetLatestList(t *testing.T) {
+	u := &listCatalogStub{lists: []model.TrancoListSummary{
+		{TrancoList: model.TrancoList{ID: "X5Y7N", CreatedOn: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)}, Rows: 1000000},
+	}}
+	h := NewListsImpl(u)
+
+	rec := httptest.NewRecorder()
+	h.GetLatestList(rec, httptest.NewRequest(http.MethodGet, "/api/v1/lists/latest", nil))
+	want := `{"id":"X5Y7N","created_on":"2024-01-09","rows":1000000}` + "\n"
+	if rec.Code != http.StatusOK || rec.Body.String() != want {
+		t.Errorf("expected 200 %s, got %d %s", want, rec.Code, rec.Body.String())
+	}
+
+	// the same list is not sent again
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/lists/latest", nil)
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	rec = httptest.NewRecorder()
+	h.GetLatestList(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("expected status %d, got %d", http.StatusNotModified, rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	NewListsImpl(&listCatalogStub{}).GetLatestList(rec, httptest.NewRequest(http.MethodGet, "/api/v1/lists/latest", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status %d without lists, got %d", http.StatusNotFound, rec.Code)
+	}
+}
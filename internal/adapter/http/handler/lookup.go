@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/handler/dto"
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/problem"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxDomainBytes is the space one domain may take in a lookup request: 253 characters, quotes, a comma and some whitespace.
+const maxDomainBytes = 260
+
+// MaxLookupDomains is how many domains one lookup request may contain.
+type MaxLookupDomains int
+
+type Lookup interface {
+	LookupRanks(w http.ResponseWriter, r *http.Request)
+}
+
+type LookupImpl struct {
+	u          usecase.LookupUseCase
+	maxDomains int
+}
+
+func NewLookupImpl(u usecase.LookupUseCase, maxDomains MaxLookupDomains) *LookupImpl {
+	return &LookupImpl{u: u, maxDomains: int(maxDomains)}
+}
+
+// LookupRanks returns the ranks of the requested domains on one day, in the order they were requested.
+// The ranks are streamed as they are looked up, so a failure midway aborts the response instead of turning it into an error.
+func (l LookupImpl) LookupRanks(w http.ResponseWriter, r *http.Request) {
+	var req dto.RequestLookup
+	if !decodeBody(w, r, int64(l.maxDomains)*maxDomainBytes+1024, &req) {
+		return
+	}
+	if req.Date == "" {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeMissingParameter, "date", "Missing required body field")
+		return
+	}
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "date", "date must be in YYYY-MM-DD format")
+		return
+	}
+	if len(req.Domains) == 0 {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeMissingParameter, "domains", "Missing required body field")
+		return
+	}
+	if len(req.Domains) > l.maxDomains {
+		problem.Write(w, r, http.StatusRequestEntityTooLarge, problem.CodeRequestTooLarge, "domains", fmt.Sprintf("At most %d domains can be looked up at once", l.maxDomains))
+		return
+	}
+	for _, domain := range req.Domains {
+		if domain == "" {
+			problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "domains", "domains must not contain empty strings")
+			return
+		}
+	}
+
+	format, err := negotiateFormat(r)
+	if err != nil {
+		writeFormatError(w, r, err)
+		return
+	}
+
+	list, err := l.u.FindList(r.Context(), date)
+	if errors.Is(err, usecase.ErrListNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "date", "No list is stored for the given date")
+		return
+	}
+	if err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err, "date": req.Date}).Error("FindList usecase returned error while processing lookup")
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal, "", "")
+		return
+	}
+
+	w.Header().Set("Content-Type", formatContentTypes[format])
+	w.Header().Add("Vary", "Accept")
+	if format != formatJSON {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "lookup_" + req.Date + "." + string(format)}))
+	}
+
+	stream := newLookupStream(w, format, req.Date, list.ID)
+	err = l.u.Lookup(r.Context(), list, req.Domains, stream.write)
+	if err == nil {
+		err = stream.close()
+	}
+	if err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err, "date": req.Date, "domains": len(req.Domains), "format": format}).Error("cannot complete lookup response")
+		// the status is already sent, so the only way to tell the client is to cut the response short
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// lookupStream writes looked up ranks in a response format as they arrive and flushes every batch.
+type lookupStream struct {
+	w       io.Writer
+	flusher http.Flusher
+	format  responseFormat
+	date    string
+	listID  string
+	csv     *csv.Writer
+	enc     *json.Encoder
+	started bool
+	written int
+}
+
+func newLookupStream(w http.ResponseWriter, format responseFormat, date, listID string) *lookupStream {
+	flusher, _ := w.(http.Flusher)
+	return &lookupStream{w: w, flusher: flusher, format: format, date: date, listID: listID, csv: csv.NewWriter(w), enc: json.NewEncoder(w)}
+}
+
+func (s *lookupStream) write(ranks []model.DomainRank) error {
+	if err := s.begin(); err != nil {
+		return err
+	}
+
+	for _, rank := range ranks {
+		var err error
+		switch s.format {
+		case formatCSV:
+			r := ""
+			if rank.Rank != nil {
+				r = strconv.Itoa(*rank.Rank)
+			}
+			err = s.csv.Write([]string{rank.Domain, r})
+		case formatNDJSON:
+			err = s.enc.Encode(dto.ResponseLookupRank{Domain: rank.Domain, Rank: rank.Rank})
+		default:
+			if s.written > 0 {
+				if _, err = io.WriteString(s.w, ","); err != nil {
+					return err
+				}
+			}
+			var b []byte
+			b, err = json.Marshal(dto.ResponseLookupRank{Domain: rank.Domain, Rank: rank.Rank})
+			if err == nil {
+				_, err = s.w.Write(b)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		s.written++
+	}
+
+	if s.format == formatCSV {
+		s.csv.Flush()
+		if err := s.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+	return nil
+}
+
+// begin writes what precedes the first rank, once.
+func (s *lookupStream) begin() error {
+	if s.started {
+		return nil
+	}
+	s.started = true
+
+	switch s.format {
+	case formatCSV:
+		return s.csv.Write([]string{"domain", "rank"})
+	case formatNDJSON:
+		return nil
+	default:
+		date, _ := json.Marshal(s.date)
+		listID, _ := json.Marshal(s.listID)
+		_, err := fmt.Fprintf(s.w, `{"date":%s,"list_id":%s,"ranks":[`, date, listID)
+		return err
+	}
+}
+
+// close writes what follows the last rank.
+func (s *lookupStream) close() error {
+	if err := s.begin(); err != nil {
+		return err
+	}
+	switch s.format {
+	case formatCSV:
+		s.csv.Flush()
+		return s.csv.Error()
+	case formatNDJSON:
+		return nil
+	default:
+		_, err := io.WriteString(s.w, "]}\n")
+		return err
+	}
+}
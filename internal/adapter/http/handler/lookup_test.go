@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
+)
+
+type lookupUseCaseStub struct {
+	ranks map[string]int
+	err   error
+}
+
+func (s lookupUseCaseStub) FindList(ctx context.Context, date time.Time) (model.TrancoList, error) {
+	if date.Day() != 9 {
+		return model.TrancoList{}, usecase.ErrListNotFound
+	}
+	return model.TrancoList{ID: "X5Y7N", CreatedOn: date}, nil
+}
+
+// Lookup emits one batch per domain.
+func (s lookupUseCaseStub) Lookup(ctx context.Context, list model.TrancoList, domains []string, emit func(ranks []model.DomainRank) error) error {
+	for _, domain := range domains {
+		rank := model.DomainRank{Domain: domain}
+		if r, ok := s.ranks[domain]; ok {
+			rank.Rank = &r
+		}
+		if err := emit([]model.DomainRank{rank}); err != nil {
+			return err
+		}
+		if s.err != nil {
+			return s.err
+		}
+	}
+	return nil
+}
+
+func TestLookupImpl_LookupRanks(t *testing.T) {
+	u := lookupUseCaseStub{ranks: map[string]int{"google.com": 1, "example.com": 300}}
+	body := `{"date":"2024-01-09","domains":["google.com","unranked.test","example.com"]}`
+
+	tests := []struct {
+		name       string
+		body       string
+		accept     string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "json",
+			body:       body,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"date":"2024-01-09","list_id":"X5Y7N","ranks":[{"domain":"google.com","rank":1},{"domain":"unranked.test","rank":null},{"domain":"example.com","rank":300}]}` + "\n",
+		},
+		{
+			name:       "ndjson",
+			body:       body,
+			accept:     "application/x-ndjson",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"domain":"google.com","rank":1}` + "\n" + `{"domain":"unranked.test","rank":null}` + "\n" + `{"domain":"example.com","rank":300}` + "\n",
+		},
+		{
+			name:       "csv",
+			body:       body,
+			accept:     "text/csv",
+			wantStatus: http.StatusOK,
+			wantBody:   "domain,rank\ngoogle.com,1\nunranked.test,\nexample.com,300\n",
+		},
+		{name: "no list", body: `{"date":"2024-01-08","domains":["google.com"]}`, wantStatus: http.StatusNotFound},
+		{name: "missing domains", body: `{"date":"2024-01-09"}`, wantStatus: http.StatusBadRequest},
+		{name: "empty domain", body: `{"date":"2024-01-09","domains":[""]}`, wantStatus: http.StatusBadRequest},
+		{name: "too many domains", body: `{"date":"2024-01-09","domains":["a.test","b.test","c.test","d.test"]}`, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "body too large", body: `{"date":"2024-01-09","domains":["` + strings.Repeat("a", 2048) + `"]}`, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "unknown field", body: `{"date":"2024-01-09","domains":["a.test"],"list_id":"X5Y7N"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/rankings/lookup", strings.NewReader(tt.body))
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			NewLookupImpl(u, 3).LookupRanks(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("unexpected body\nwant %q\ngot  %q", tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestLookupImpl_LookupRanksAbortsOnError(t *testing.T) {
+	u := lookupUseCaseStub{err: errors.New("db down")}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/rankings/lookup", strings.NewReader(`{"date":"2024-01-09","domains":["a.test","b.test"]}`))
+
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("expected the response to be aborted, got %v", r)
+		}
+	}()
+	NewLookupImpl(u, 3).LookupRanks(httptest.NewRecorder(), req)
+}
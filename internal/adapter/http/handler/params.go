@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/problem"
+)
+
+// firstMissingParam returns the first of names that is empty in q, or "" when every parameter is present.
+func firstMissingParam(q url.Values, names ...string) string {
+	for _, name := range names {
+		if q.Get(name) == "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// decodeBody decodes the JSON body of r, which may be up to limit bytes, into v and writes a problem when it is malformed or too large.
+func decodeBody(w http.ResponseWriter, r *http.Request, limit int64, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		problem.Write(w, r, http.StatusRequestEntityTooLarge, problem.CodeRequestTooLarge, "", fmt.Sprintf("The request body must not exceed %d bytes", limit))
+		return false
+	}
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidBody, "", "The request body must be a JSON object with the documented fields")
+		return false
+	}
+	return true
+}
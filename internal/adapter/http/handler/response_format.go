@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/handler/dto"
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/problem"
+)
+
+// responseFormat is a representation that ranking endpoints can respond with.
+type responseFormat string
+
+const (
+	formatJSON   responseFormat = "json"
+	formatCSV    responseFormat = "csv"
+	formatNDJSON responseFormat = "ndjson"
+)
+
+var formatContentTypes = map[responseFormat]string{
+	formatJSON:   "application/json",
+	formatCSV:    "text/csv; charset=utf-8",
+	formatNDJSON: "application/x-ndjson",
+}
+
+var (
+	errUnsupportedFormat = errors.New("unsupported format")
+	errNotAcceptable     = errors.New("no acceptable format")
+)
+
+// negotiateFormat decides the response format from the format query parameter, falling back to the Accept header.
+// An explicit format parameter wins over the Accept header.
+func negotiateFormat(r *http.Request) (responseFormat, error) {
+	if f := r.URL.Query().Get("format"); f != "" {
+		if _, ok := formatContentTypes[responseFormat(f)]; !ok {
+			return "", errUnsupportedFormat
+		}
+		return responseFormat(f), nil
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return formatJSON, nil
+	}
+
+	for _, mediaType := range parseAccept(accept) {
+		switch mediaType {
+		case "application/json", "application/*", "*/*":
+			return formatJSON, nil
+		case "text/csv", "text/*":
+			return formatCSV, nil
+		case "application/x-ndjson", "application/ndjson":
+			return formatNDJSON, nil
+		}
+	}
+	return "", errNotAcceptable
+}
+
+// parseAccept returns the media types of an Accept header ordered by preference.
+// Media types with q=0 are dropped.
+func parseAccept(accept string) []string {
+	type mediaRange struct {
+		mediaType string
+		q         float64
+	}
+
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	mediaTypes := make([]string, len(ranges))
+	for i, mr := range ranges {
+		mediaTypes[i] = mr.mediaType
+	}
+	return mediaTypes
+}
+
+// writeRanking writes resp in format f. CSV and NDJSON are sent as downloads named after filename.
+func writeRanking(w http.ResponseWriter, f responseFormat, filename string, resp dto.ResponseRanking) error {
+	w.Header().Set("Content-Type", formatContentTypes[f])
+	if f != formatJSON {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename + "." + string(f)}))
+	}
+
+	switch f {
+	case formatCSV:
+		return writeRankingCSV(w, resp)
+	case formatNDJSON:
+		return writeRankingNDJSON(w, resp)
+	default:
+		return json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func writeRankingCSV(w http.ResponseWriter, resp dto.ResponseRanking) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"domain", "date", "rank", "status", "filled"}); err != nil {
+		return err
+	}
+	for _, rank := range resp.Ranks {
+		r := ""
+		if rank.Rank != nil {
+			r = strconv.Itoa(*rank.Rank)
+		}
+		if err := cw.Write([]string{resp.Domain, rank.Date, r, rank.Status, strconv.FormatBool(rank.Filled)}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeRankingNDJSON(w http.ResponseWriter, resp dto.ResponseRanking) error {
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	for _, rank := range resp.Ranks {
+		if err := enc.Encode(dto.ResponseRankRecord{Domain: resp.Domain, ResponseRank: rank}); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	return nil
+}
+
+// writeFormatError responds to a failed negotiateFormat.
+func writeFormatError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, errNotAcceptable) {
+		problem.Write(w, r, http.StatusNotAcceptable, problem.CodeNotAcceptable, "Accept", "Supported formats are application/json, text/csv and application/x-ndjson")
+		return
+	}
+	problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "format", "format must be one of json, csv or ndjson")
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/handler/dto"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		accept   string
+		expected responseFormat
+		err      error
+	}{
+		{name: "no preference", url: "/", expected: formatJSON},
+		{name: "format parameter", url: "/?format=csv", expected: formatCSV},
+		{name: "format parameter wins over accept", url: "/?format=ndjson", accept: "text/csv", expected: formatNDJSON},
+		{name: "unknown format parameter", url: "/?format=xml", err: errUnsupportedFormat},
+		{name: "accept csv", url: "/", accept: "text/csv", expected: formatCSV},
+		{name: "accept ndjson", url: "/", accept: "application/x-ndjson", expected: formatNDJSON},
+		{name: "accept with quality", url: "/", accept: "application/json;q=0.5, text/csv;q=0.9", expected: formatCSV},
+		{name: "accept anything", url: "/", accept: "*/*", expected: formatJSON},
+		{name: "accept excluded by zero quality", url: "/", accept: "text/csv;q=0, application/json", expected: formatJSON},
+		{name: "accept nothing supported", url: "/", accept: "application/xml", err: errNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			got, err := negotiateFormat(req)
+			if err != tt.err {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected format %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWriteRanking(t *testing.T) {
+	rank := 3
+	resp := dto.ResponseRanking{
+		Domain: "example.com",
+		Ranks: []dto.ResponseRank{
+			{Rank: &rank, Date: "2023-01-02", Status: "ranked"},
+			{Date: "2023-01-01", Status: "not_ingested"},
+		},
+	}
+
+	tests := []struct {
+		name                string
+		format              responseFormat
+		expectedType        string
+		expectedDisposition string
+		expectedBody        string
+	}{
+		{
+			name:         "json",
+			format:       formatJSON,
+			expectedType: "application/json",
+			expectedBody: `{"ranks":[{"rank":3,"date":"2023-01-02","status":"ranked"},{"rank":null,"date":"2023-01-01","status":"not_ingested"}],"domain":"example.com"}` + "\n",
+		},
+		{
+			name:                "csv",
+			format:              formatCSV,
+			expectedType:        "text/csv; charset=utf-8",
+			expectedDisposition: `attachment; filename=example.com_daily.csv`,
+			expectedBody:        "domain,date,rank,status,filled\nexample.com,2023-01-02,3,ranked,false\nexample.com,2023-01-01,,not_ingested,false\n",
+		},
+		{
+			name:                "ndjson",
+			format:              formatNDJSON,
+			expectedType:        "application/x-ndjson",
+			expectedDisposition: `attachment; filename=example.com_daily.ndjson`,
+			expectedBody:        `{"domain":"example.com","rank":3,"date":"2023-01-02","status":"ranked"}` + "\n" + `{"domain":"example.com","rank":null,"date":"2023-01-01","status":"not_ingested"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			if err := writeRanking(rec, tt.format, "example.com_daily", resp); err != nil {
+				t.Fatal(err)
+			}
+
+			if c := rec.Header().Get("Content-Type"); c != tt.expectedType {
+				t.Errorf("expected content type %q, got %q", tt.expectedType, c)
+			}
+			if d := rec.Header().Get("Content-Disposition"); d != tt.expectedDisposition {
+				t.Errorf("expected content disposition %q, got %q", tt.expectedDisposition, d)
+			}
+			if body := rec.Body.String(); body != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, body)
+			}
+		})
+	}
+}
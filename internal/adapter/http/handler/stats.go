@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/handler/dto"
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/problem"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultStatsTop is the top N reported when top is not requested.
+	defaultStatsTop = 1000
+	// maxHistoryTLDs bounds the tlds of a TLD history.
+	maxHistoryTLDs = 20
+)
+
+type Stats interface {
+	GetTLDStats(w http.ResponseWriter, r *http.Request)
+	GetTLDHistory(w http.ResponseWriter, r *http.Request)
+}
+
+type StatsImpl struct {
+	u usecase.TLDStatsUseCase
+}
+
+func NewStatsImpl(u usecase.TLDStatsUseCase) *StatsImpl {
+	return &StatsImpl{u: u}
+}
+
+// GetTLDStats returns how many of the top N domains of the list of the optional date belong to each TLD, largest first.
+// Without date the latest list is used.
+func (s StatsImpl) GetTLDStats(w http.ResponseWriter, r *http.Request) {
+	top, ok := parseStatsTop(w, r)
+	if !ok {
+		return
+	}
+
+	var date time.Time
+	if str := r.URL.Query().Get("date"); str != "" {
+		var err error
+		date, err = time.Parse("2006-01-02", str)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "date", "date must be in YYYY-MM-DD format")
+			return
+		}
+	}
+
+	dist, err := s.u.GetDistribution(r.Context(), date, top)
+	if errors.Is(err, usecase.ErrListNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "", "No list is stored for the given date")
+		return
+	}
+	if err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err, "date": date, "top": top}).Error("GetDistribution usecase returned error while processing TLD stats")
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal, "", "")
+		return
+	}
+	if len(dist.Counts) == 0 {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "", "No TLD statistics are stored for the list of the given date")
+		return
+	}
+
+	resp := dto.ResponseTLDStats{
+		Date:   dist.List.CreatedOn.UTC().Format("2006-01-02"),
+		ListID: dist.List.ID,
+		Top:    dist.Top,
+		Total:  dist.Total,
+		TLDs:   toResponseTLDCounts(dist),
+	}
+	writeStats(w, r, []string{dist.List.ID}, resp)
+}
+
+// GetTLDHistory returns how many of the top N domains of every list in the range belong to each of the requested TLDs, oldest first.
+// Without tlds the TLDs with the most domains over the range are reported.
+func (s StatsImpl) GetTLDHistory(w http.ResponseWriter, r *http.Request) {
+	if param := firstMissingParam(r.URL.Query(), "start_date", "end_date"); param != "" {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeMissingParameter, param, "Missing required query parameter")
+		return
+	}
+	startDate, err := time.Parse("2006-01-02", r.URL.Query().Get("start_date"))
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "start_date", "start_date must be in YYYY-MM-DD format")
+		return
+	}
+	endDate, err := time.Parse("2006-01-02", r.URL.Query().Get("end_date"))
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "end_date", "end_date must be in YYYY-MM-DD format")
+		return
+	}
+	if startDate.After(endDate) {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidRange, "start_date", "start_date should be before or equal to end_date")
+		return
+	}
+
+	top, ok := parseStatsTop(w, r)
+	if !ok {
+		return
+	}
+
+	var tlds []string
+	if str := r.URL.Query().Get("tlds"); str != "" {
+		for _, tld := range strings.Split(str, ",") {
+			tld = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(tld)), ".")
+			if tld == "" {
+				problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "tlds", "tlds must be a comma separated list of TLDs")
+				return
+			}
+			tlds = append(tlds, tld)
+		}
+		if len(tlds) > maxHistoryTLDs {
+			problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "tlds", fmt.Sprintf("tlds must not have more than %d TLDs", maxHistoryTLDs))
+			return
+		}
+	}
+
+	history, tlds, err := s.u.GetHistory(r.Context(), startDate, endDate, top, tlds)
+	if err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err, "start_date": startDate, "end_date": endDate, "top": top}).Error("GetHistory usecase returned error while processing TLD history")
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal, "", "")
+		return
+	}
+
+	resp := dto.ResponseTLDHistory{Top: top, TLDs: tlds, Points: make([]dto.ResponseTLDHistoryPoint, len(history))}
+	if resp.TLDs == nil {
+		resp.TLDs = []string{}
+	}
+	ids := make([]string, len(history))
+	for i, dist := range history {
+		ids[i] = dist.List.ID
+		resp.Points[i] = dto.ResponseTLDHistoryPoint{
+			Date:         dist.List.CreatedOn.UTC().Format("2006-01-02"),
+			ListID:       dist.List.ID,
+			Total:        dist.Total,
+			TLDs:         toResponseTLDCounts(dist),
+			OtherDomains: dist.Other,
+			OtherShare:   share(dist.Other, dist.Total),
+		}
+	}
+	writeStats(w, r, ids, resp)
+}
+
+// parseStatsTop reads the top query parameter, which must be one of the precomputed cut-offs.
+func parseStatsTop(w http.ResponseWriter, r *http.Request) (int, bool) {
+	str := r.URL.Query().Get("top")
+	if str == "" {
+		return defaultStatsTop, true
+	}
+	top, err := strconv.Atoi(str)
+	if err != nil || !model.IsTLDStatsTop(top) {
+		tops := make([]string, len(model.TLDStatsTops))
+		for i, n := range model.TLDStatsTops {
+			tops[i] = strconv.Itoa(n)
+		}
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "top", "top must be one of "+strings.Join(tops, ", "))
+		return 0, false
+	}
+	return top, true
+}
+
+func toResponseTLDCounts(dist model.TLDDistribution) []dto.ResponseTLDCount {
+	counts := make([]dto.ResponseTLDCount, len(dist.Counts))
+	for i, count := range dist.Counts {
+		counts[i] = dto.ResponseTLDCount{TLD: count.TLD, Domains: count.Domains, Share: share(count.Domains, dist.Total)}
+	}
+	return counts
+}
+
+// share returns the fraction of total that domains is, rounded to six decimal places.
+func share(domains, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(domains)/float64(total)*1e6) / 1e6
+}
+
+// writeStats writes resp, built from the lists listIDs, as JSON. Like the list catalogue, only an ETag is given.
+func writeStats(w http.ResponseWriter, r *http.Request, listIDs []string, resp any) {
+	if writeNotModified(w, r, newValidator(r, formatJSON, listIDs, nil)) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err}).Error("cannot write stats response")
+	}
+}
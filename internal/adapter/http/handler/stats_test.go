@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
+)
+
+type tldStatsStub struct {
+	usecase.TLDStatsUseCase
+	date time.Time
+	top  int
+	tlds []string
+}
+
+func (s *tldStatsStub) GetDistribution(ctx context.Context, date time.Time, top int) (model.TLDDistribution, error) {
+	s.date, s.top = date, top
+	switch {
+	case date.IsZero() || date.Equal(time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)):
+		return model.TLDDistribution{
+			List:   model.TrancoList{ID: "X5Y7N", CreatedOn: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)},
+			Top:    top,
+			Total:  3,
+			Counts: []model.TLDCount{{TLD: "com", Domains: 2}, {TLD: "io", Domains: 1}},
+		}, nil
+	case date.Equal(time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)):
+		return model.TLDDistribution{List: model.TrancoList{ID: "Q94V4", CreatedOn: date}, Top: top}, nil
+	default:
+		return model.TLDDistribution{}, usecase.ErrListNotFound
+	}
+}
+
+func (s *tldStatsStub) GetHistory(ctx context.Context, start time.Time, end time.Time, top int, tlds []string) ([]model.TLDDistribution, []string, error) {
+	s.top, s.tlds = top, tlds
+	if len(tlds) == 0 {
+		tlds = []string{"com"}
+	}
+	history := []model.TLDDistribution{{
+		List:   model.TrancoList{ID: "X5Y7N", CreatedOn: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)},
+		Top:    top,
+		Total:  4,
+		Counts: make([]model.TLDCount, len(tlds)),
+		Other:  1,
+	}}
+	for i, tld := range tlds {
+		history[0].Counts[i] = model.TLDCount{TLD: tld, Domains: 3 - i*3}
+	}
+	return history, tlds, nil
+}
+
+func TestStatsImpl_GetTLDStats(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantTop    int
+		wantBody   string
+	}{
+		{
+			name:       "latest list",
+			wantStatus: http.StatusOK,
+			wantTop:    1000,
+			wantBody:   `{"date":"2024-01-09","list_id":"X5Y7N","top":1000,"total":3,"tlds":[{"tld":"com","domains":2,"share":0.666667},{"tld":"io","domains":1,"share":0.333333}]}` + "\n",
+		},
+		{name: "date and top", query: "?date=2024-01-09&top=100", wantStatus: http.StatusOK, wantTop: 100},
+		{name: "list without stats", query: "?date=2024-01-08", wantStatus: http.StatusNotFound, wantTop: 1000},
+		{name: "no list", query: "?date=2024-01-07", wantStatus: http.StatusNotFound, wantTop: 1000},
+		{name: "invalid date", query: "?date=2024-1-9", wantStatus: http.StatusBadRequest},
+		{name: "top without stats", query: "?top=500", wantStatus: http.StatusBadRequest},
+		{name: "top not a number", query: "?top=all", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &tldStatsStub{}
+			rec := httptest.NewRecorder()
+			NewStatsImpl(u).GetTLDStats(rec, httptest.NewRequest(http.MethodGet, "/api/v1/stats/tld"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if u.top != tt.wantTop {
+				t.Errorf("expected top %d, got %d", tt.wantTop, u.top)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("unexpected body\nwant %s\ngot  %s", tt.wantBody, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && rec.Header().Get("ETag") == "" {
+				t.Error("expected an ETag")
+			}
+		})
+	}
+}
+
+func TestStatsImpl_GetTLDHistory(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantTLDs   []string
+		wantBody   string
+	}{
+		{
+			name:       "largest TLDs",
+			query:      "?start_date=2024-01-09&end_date=2024-01-09",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"top":1000,"tlds":["com"],"points":[{"date":"2024-01-09","list_id":"X5Y7N","total":4,"tlds":[{"tld":"com","domains":3,"share":0.75}],"other_domains":1,"other_share":0.25}]}` + "\n",
+		},
+		{name: "requested TLDs", query: "?start_date=2024-01-09&end_date=2024-01-09&tlds=COM,%20.io", wantStatus: http.StatusOK, wantTLDs: []string{"com", "io"}},
+		{name: "empty TLD", query: "?start_date=2024-01-09&end_date=2024-01-09&tlds=com,,io", wantStatus: http.StatusBadRequest},
+		{name: "too many TLDs", query: "?start_date=2024-01-09&end_date=2024-01-09&tlds=a,b,c,d,e,f,g,h,i,j,k,l,m,n,o,p,q,r,s,t,u", wantStatus: http.StatusBadRequest},
+		{name: "missing end date", query: "?start_date=2024-01-09", wantStatus: http.StatusBadRequest},
+		{name: "inverted range", query: "?start_date=2024-01-09&end_date=2024-01-08", wantStatus: http.StatusBadRequest},
+		{name: "invalid top", query: "?start_date=2024-01-09&end_date=2024-01-09&top=10", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &tldStatsStub{}
+			rec := httptest.NewRecorder()
+			NewStatsImpl(u).GetTLDHistory(rec, httptest.NewRequest(http.MethodGet, "/api/v1/stats/tld/history"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if diff := cmp.Diff(tt.wantTLDs, u.tlds); diff != "" {
+				t.Errorf("unexpected tlds (-want +got):\n%s", diff)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("unexpected body\nwant %s\ngot  %s", tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/handler/dto"
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/problem"
+	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+type Trend interface {
+	GetTrend(w http.ResponseWriter, r *http.Request)
+}
+
+type TrendImpl struct {
+	u usecase.TrendUseCase
+}
+
+func NewTrendImpl(u usecase.TrendUseCase) *TrendImpl {
+	return &TrendImpl{u: u}
+}
+
+// GetTrend summarizes the daily ranks of the domain of the path in the range: the slope of the log-rank, the standard
+// deviation of the ranks, the worst drop and whether the domain is rising, falling, stable or volatile.
+func (t TrendImpl) GetTrend(w http.ResponseWriter, r *http.Request) {
+	if param := firstMissingParam(r.URL.Query(), "start_date", "end_date"); param != "" {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeMissingParameter, param, "Missing required query parameter")
+		return
+	}
+	startDate, err := time.Parse("2006-01-02", r.URL.Query().Get("start_date"))
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "start_date", "start_date must be in YYYY-MM-DD format")
+		return
+	}
+	endDate, err := time.Parse("2006-01-02", r.URL.Query().Get("end_date"))
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "end_date", "end_date must be in YYYY-MM-DD format")
+		return
+	}
+	if startDate.After(endDate) {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidRange, "start_date", "start_date should be before or equal to end_date")
+		return
+	}
+
+	domain, rollup, ok := parseRollup(w, r, chi.URLParam(r, "domain"))
+	if !ok {
+		return
+	}
+
+	trend, err := t.u.GetTrend(r.Context(), domain, rollup, startDate, endDate)
+	if err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err, "domain": domain, "start_date": startDate, "end_date": endDate}).Error("GetTrend usecase returned error while processing trend")
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal, "", "")
+		return
+	}
+	if trend.Points == 0 {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "", "No rankings available for the given domain and period")
+		return
+	}
+
+	resp := dto.ResponseTrend{
+		Domain:      domain,
+		StartDate:   startDate.Format("2006-01-02"),
+		EndDate:     endDate.Format("2006-01-02"),
+		Points:      trend.Points,
+		FirstRank:   trend.FirstRank,
+		LastRank:    trend.LastRank,
+		Slope:       round6(trend.Slope),
+		StdDev:      round6(trend.StdDev),
+		MaxDrawdown: trend.MaxDrawdown,
+		Class:       string(trend.Class),
+	}
+	if trend.MaxDrawdown > 0 {
+		from, to := trend.DrawdownFrom.UTC().Format("2006-01-02"), trend.DrawdownTo.UTC().Format("2006-01-02")
+		resp.DrawdownFrom, resp.DrawdownTo = &from, &to
+	}
+	writeStats(w, r, trend.ListIDs, resp)
+}
+
+// round6 rounds v to six decimal places.
+func round6(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+type trendStub struct {
+	domain string
+	rollup model.Rollup
+}
+
+func (s *trendStub) GetTrend(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time) (model.RankTrend, error) {
+	s.domain, s.rollup = domain, rollup
+	switch domain {
+	case "example.com", "google.com":
+		return model.RankTrend{
+			Points:       3,
+			FirstRank:    100,
+			LastRank:     400,
+			Slope:        0.6931471805599453,
+			StdDev:       124.72191289246472,
+			MaxDrawdown:  300,
+			DrawdownFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			DrawdownTo:   time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+			Class:        model.TrendFalling,
+			ListIDs:      []string{"A", "B", "C"},
+		}, nil
+	case "stable.com":
+		return model.RankTrend{Points: 1, FirstRank: 5, LastRank: 5, Class: model.TrendStable, ListIDs: []string{"A"}}, nil
+	case "error.com":
+		return model.RankTrend{}, errors.New("some error")
+	default:
+		return model.RankTrend{}, nil
+	}
+}
+
+func TestTrendImpl_GetTrend(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantDomain string
+		wantRollup model.Rollup
+		wantBody   string
+	}{
+		{
+			name:       "trend",
+			path:       "/api/v1/domains/example.com/trend?start_date=2024-01-01&end_date=2024-01-31",
+			wantStatus: http.StatusOK,
+			wantDomain: "example.com",
+			wantBody:   `{"domain":"example.com","start_date":"2024-01-01","end_date":"2024-01-31","points":3,"first_rank":100,"last_rank":400,"slope":0.693147,"std_dev":124.721913,"max_drawdown":300,"drawdown_from":"2024-01-01","drawdown_to":"2024-01-03","class":"falling"}` + "\n",
+		},
+		{
+			name:       "no drop",
+			path:       "/api/v1/domains/stable.com/trend?start_date=2024-01-01&end_date=2024-01-31",
+			wantStatus: http.StatusOK,
+			wantDomain: "stable.com",
+			wantBody:   `{"domain":"stable.com","start_date":"2024-01-01","end_date":"2024-01-31","points":1,"first_rank":5,"last_rank":5,"slope":0,"std_dev":0,"max_drawdown":0,"drawdown_from":null,"drawdown_to":null,"class":"stable"}` + "\n",
+		},
+		{
+			name:       "rolled up",
+			path:       "/api/v1/domains/mail.google.com/trend?start_date=2024-01-01&end_date=2024-01-31&rollup=registrable",
+			wantStatus: http.StatusOK,
+			wantDomain: "google.com",
+			wantRollup: model.RollupRegistrable,
+		},
+		{name: "not ranked", path: "/api/v1/domains/unknown.com/trend?start_date=2024-01-01&end_date=2024-01-31", wantStatus: http.StatusNotFound, wantDomain: "unknown.com"},
+		{name: "usecase error", path: "/api/v1/domains/error.com/trend?start_date=2024-01-01&end_date=2024-01-31", wantStatus: http.StatusInternalServerError, wantDomain: "error.com"},
+		{name: "missing start date", path: "/api/v1/domains/example.com/trend?end_date=2024-01-31", wantStatus: http.StatusBadRequest},
+		{name: "invalid end date", path: "/api/v1/domains/example.com/trend?start_date=2024-01-01&end_date=2024-1-31", wantStatus: http.StatusBadRequest},
+		{name: "inverted range", path: "/api/v1/domains/example.com/trend?start_date=2024-01-31&end_date=2024-01-01", wantStatus: http.StatusBadRequest},
+		{name: "invalid rollup", path: "/api/v1/domains/example.com/trend?start_date=2024-01-01&end_date=2024-01-31&rollup=tld", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &trendStub{}
+			router := chi.NewRouter()
+			router.Get("/api/v1/domains/{domain}/trend", NewTrendImpl(u).GetTrend)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if u.domain != tt.wantDomain || u.rollup != tt.wantRollup {
+				t.Errorf("expected trend of %q with rollup %q, got %q with %q", tt.wantDomain, tt.wantRollup, u.domain, u.rollup)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("unexpected body\nwant %s\ngot  %s", tt.wantBody, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && rec.Header().Get("ETag") == "" {
+				t.Error("expected an ETag")
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/problem"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// APIKeyHeader is the header an API key can be sent in instead of the Authorization header.
+const APIKeyHeader = "X-API-Key"
+
+type apiKeyContextKey struct{}
+
+// APIKeyFromContext returns the API key the request was authenticated with. ok is false for anonymous requests.
+func APIKeyFromContext(ctx context.Context) (key model.APIKey, ok bool) {
+	key, ok = ctx.Value(apiKeyContextKey{}).(model.APIKey)
+	return key, ok
+}
+
+// Authenticate returns a middleware that resolves the API key sent as a Bearer token or in X-API-Key.
+// Requests without a key pass as anonymous, requests with an invalid key get 401 and keys over their daily quota get 429.
+// The key is stored in the request context and its name is added to the request logger.
+func Authenticate(u usecase.APIKeyUseCase) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			secret := apiKeyFromRequest(r)
+			if secret == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, err := u.Authenticate(r.Context(), secret)
+			switch {
+			case errors.Is(err, usecase.ErrInvalidAPIKey):
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "", "The API key is invalid or revoked")
+				return
+			case errors.Is(err, usecase.ErrQuotaExceeded):
+				w.Header().Set("Retry-After", strconv.Itoa(secondsUntilNextDay(time.Now())))
+				problem.Write(w, r, http.StatusTooManyRequests, problem.CodeQuotaExceeded, "", "The daily quota of the API key is used up")
+				return
+			case err != nil:
+				util.Logger(r.Context()).WithFields(log.Fields{"error": err}).Error("failed to authenticate api key")
+				problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal, "", "")
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), apiKeyContextKey{}, key)
+			ctx = util.WithLogger(ctx, util.Logger(ctx).WithField("api_key", key.Name))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireScope returns a middleware that only lets requests through whose API key has scope.
+// With allowAnonymous, requests without a key are let through as well.
+func RequireScope(scope model.Scope, allowAnonymous bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, ok := APIKeyFromContext(r.Context())
+			if !ok {
+				if allowAnonymous {
+					next.ServeHTTP(w, r)
+					return
+				}
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "", "An API key is required")
+				return
+			}
+			if !key.HasScope(scope) {
+				problem.Write(w, r, http.StatusForbidden, problem.CodeForbidden, "", "The API key lacks the "+string(scope)+" scope")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func apiKeyFromRequest(r *http.Request) string {
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return strings.TrimSpace(r.Header.Get(APIKeyHeader))
+}
+
+// secondsUntilNextDay returns when daily quotas are reset, at the next UTC midnight.
+func secondsUntilNextDay(now time.Time) int {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	return int(math.Ceil(next.Sub(now).Seconds()))
+}
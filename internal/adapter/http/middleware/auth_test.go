@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/problem"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
+)
+
+type apiKeyUseCaseStub struct {
+	keys map[string]model.APIKey
+	errs map[string]error
+}
+
+func (s apiKeyUseCaseStub) Authenticate(ctx context.Context, key string) (model.APIKey, error) {
+	if err, ok := s.errs[key]; ok {
+		return model.APIKey{}, err
+	}
+	k, ok := s.keys[key]
+	if !ok {
+		return model.APIKey{}, usecase.ErrInvalidAPIKey
+	}
+	return k, nil
+}
+
+func (s apiKeyUseCaseStub) Create(ctx context.Context, name string, scopes []model.Scope, dailyQuota int) (model.APIKey, string, error) {
+	return model.APIKey{}, "", errors.New("not implemented")
+}
+
+func (s apiKeyUseCaseStub) List(ctx context.Context) ([]model.APIKey, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s apiKeyUseCaseStub) Revoke(ctx context.Context, name string) error {
+	return errors.New("not implemented")
+}
+
+func TestAuthenticateAndRequireScope(t *testing.T) {
+	u := apiKeyUseCaseStub{
+		keys: map[string]model.APIKey{
+			"reader": {ID: 1, Name: "reader", Scopes: []model.Scope{model.ScopeRead}},
+			"admin":  {ID: 2, Name: "admin", Scopes: []model.Scope{model.ScopeAdmin}},
+		},
+		errs: map[string]error{
+			"exhausted": usecase.ErrQuotaExceeded,
+			"broken":    errors.New("connection refused"),
+		},
+	}
+
+	tests := []struct {
+		name           string
+		header         http.Header
+		allowAnonymous bool
+		wantStatus     int
+		wantCode       problem.Code
+		wantKey        string
+	}{
+		{name: "bearer token", header: http.Header{"Authorization": {"Bearer reader"}}, wantStatus: http.StatusOK, wantKey: "reader"},
+		{name: "lower case scheme", header: http.Header{"Authorization": {"bearer reader"}}, wantStatus: http.StatusOK, wantKey: "reader"},
+		{name: "x-api-key header", header: http.Header{APIKeyHeader: {"reader"}}, wantStatus: http.StatusOK, wantKey: "reader"},
+		{name: "anonymous allowed", allowAnonymous: true, wantStatus: http.StatusOK},
+		{name: "anonymous rejected", wantStatus: http.StatusUnauthorized, wantCode: problem.CodeUnauthorized},
+		{name: "invalid key even if anonymous is allowed", header: http.Header{APIKeyHeader: {"unknown"}}, allowAnonymous: true, wantStatus: http.StatusUnauthorized, wantCode: problem.CodeUnauthorized},
+		{name: "missing scope", header: http.Header{APIKeyHeader: {"admin"}}, wantStatus: http.StatusForbidden, wantCode: problem.CodeForbidden},
+		{name: "quota exceeded", header: http.Header{APIKeyHeader: {"exhausted"}}, wantStatus: http.StatusTooManyRequests, wantCode: problem.CodeQuotaExceeded},
+		{name: "usecase error", header: http.Header{APIKeyHeader: {"broken"}}, wantStatus: http.StatusInternalServerError, wantCode: problem.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotKey string
+			h := Authenticate(u)(RequireScope(model.ScopeRead, tt.allowAnonymous)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if key, ok := APIKeyFromContext(r.Context()); ok {
+					gotKey = key.Name
+				}
+			})))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/rankings/daily", nil)
+			for name, values := range tt.header {
+				req.Header.Set(name, values[0])
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if gotKey != tt.wantKey {
+				t.Errorf("expected key %q in context, got %q", tt.wantKey, gotKey)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+
+			var p problem.Problem
+			if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+				t.Fatal(err)
+			}
+			if p.Code != tt.wantCode {
+				t.Errorf("expected code %s, got %s", tt.wantCode, p.Code)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("expected WWW-Authenticate header")
+			}
+			if tt.wantStatus == http.StatusTooManyRequests {
+				if seconds, err := strconv.Atoi(rec.Header().Get("Retry-After")); err != nil || seconds <= 0 || seconds > 86400 {
+					t.Errorf("expected Retry-After within a day, got %q", rec.Header().Get("Retry-After"))
+				}
+			}
+		})
+	}
+}
+
+func TestSecondsUntilNextDay(t *testing.T) {
+	now := time.Date(2024, 1, 10, 23, 59, 30, 500, time.UTC)
+	if got := secondsUntilNextDay(now); got != 30 {
+		t.Errorf("expected 30 seconds, got %d", got)
+	}
+	jst := time.Date(2024, 1, 11, 8, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	if got := secondsUntilNextDay(jst); got != 60*60 {
+		t.Errorf("expected quota reset at UTC midnight, got %d seconds", got)
+	}
+}
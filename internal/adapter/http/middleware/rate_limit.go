@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/problem"
+)
+
+// RateLimit is a token bucket that refills Rate tokens per second up to Burst tokens. Every request takes one token.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  RateLimit
+}
+
+// RateLimiter throttles clients with one token bucket per route group and client.
+// Clients are identified by their API key when they sent one, otherwise by r.RemoteAddr,
+// which cfconnectingip.SetRemoteAddr sets to the real client IP.
+// Buckets that have refilled completely are evicted every evictionInterval, since they are equal to a new bucket,
+// so memory only grows with the clients that were active recently.
+type RateLimiter struct {
+	mu               sync.Mutex
+	buckets          map[string]*bucket
+	now              func() time.Time
+	evictionInterval time.Duration
+	lastEviction     time.Time
+}
+
+// NewRateLimiter creates a RateLimiter that reads the time from now.
+func NewRateLimiter(now func() time.Time, evictionInterval time.Duration) *RateLimiter {
+	return &RateLimiter{
+		buckets:          map[string]*bucket{},
+		now:              now,
+		evictionInterval: evictionInterval,
+		lastEviction:     now(),
+	}
+}
+
+// Limit returns a middleware that limits each client to limit on the routes it is installed on.
+// name separates the buckets of route groups with different limits.
+// Responses carry X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset, the seconds until the bucket is full again.
+// Throttled requests get 429 with Retry-After.
+func (l *RateLimiter) Limit(name string, limit RateLimit) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, remaining, reset, retryAfter := l.take(name+"|"+clientKey(r), limit)
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit.Burst))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+			w.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+			if !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+				problem.Write(w, r, http.StatusTooManyRequests, problem.CodeRateLimited, "", "Too many requests, retry later")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// take takes a token from the bucket of key. It returns whether a token was available, the tokens left,
+// how long until the bucket is full and, when no token was available, how long until the next one.
+func (l *RateLimiter) take(key string, limit RateLimit) (allowed bool, remaining int, reset, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.evict(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now, limit: limit}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		allowed = true
+	} else {
+		retryAfter = secondsToDuration((1 - b.tokens) / limit.Rate)
+	}
+	return allowed, int(b.tokens), secondsToDuration((float64(limit.Burst) - b.tokens) / limit.Rate), retryAfter
+}
+
+// evict drops the buckets that are full again, at most once every evictionInterval.
+func (l *RateLimiter) evict(now time.Time) {
+	if now.Sub(l.lastEviction) < l.evictionInterval {
+		return
+	}
+	l.lastEviction = now
+
+	for key, b := range l.buckets {
+		refill := secondsToDuration((float64(b.limit.Burst) - b.tokens) / b.limit.Rate)
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Len returns the number of buckets held in memory.
+func (l *RateLimiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// clientKey identifies the client of r by its API key or, for anonymous requests, its IP.
+func clientKey(r *http.Request) string {
+	if key, ok := APIKeyFromContext(r.Context()); ok {
+		return "key:" + key.Name
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
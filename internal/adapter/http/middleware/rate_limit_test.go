@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func limitedRequest(t *testing.T, h http.Handler, remoteAddr string, key string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/rankings/daily", nil)
+	req.RemoteAddr = remoteAddr
+	if key != "" {
+		req = req.WithContext(context.WithValue(req.Context(), apiKeyContextKey{}, model.APIKey{ID: 1, Name: key}))
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimiter_Limit(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(clock.Now, time.Minute)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	// one request per second, bursts of three
+	h := limiter.Limit("rankings", RateLimit{Rate: 1, Burst: 3})(ok)
+
+	for i, wantRemaining := range []string{"2", "1", "0"} {
+		rec := limitedRequest(t, h, "203.0.113.1:1234", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected status %d, got %d", i+1, http.StatusOK, rec.Code)
+		}
+		if got := rec.Header().Get("X-RateLimit-Remaining"); got != wantRemaining {
+			t.Errorf("request %d: expected %s remaining, got %s", i+1, wantRemaining, got)
+		}
+		if got := rec.Header().Get("X-RateLimit-Limit"); got != "3" {
+			t.Errorf("request %d: expected limit 3, got %s", i+1, got)
+		}
+	}
+
+	rec := limitedRequest(t, h, "203.0.113.1:5678", "")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status %d once the burst is used up, got %d", http.StatusTooManyRequests, rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("expected Retry-After 1, got %s", got)
+	}
+	if got := rec.Header().Get("X-RateLimit-Reset"); got != "3" {
+		t.Errorf("expected reset in 3 seconds, got %s", got)
+	}
+
+	// other clients and other route groups have their own buckets
+	if rec := limitedRequest(t, h, "203.0.113.2:1234", ""); rec.Code != http.StatusOK {
+		t.Errorf("expected another ip to be allowed, got %d", rec.Code)
+	}
+	if rec := limitedRequest(t, h, "203.0.113.1:1234", "dashboard"); rec.Code != http.StatusOK {
+		t.Errorf("expected an api key from the same ip to be allowed, got %d", rec.Code)
+	}
+	other := limiter.Limit("default", RateLimit{Rate: 1, Burst: 3})(ok)
+	if rec := limitedRequest(t, other, "203.0.113.1:1234", ""); rec.Code != http.StatusOK {
+		t.Errorf("expected another route group to be allowed, got %d", rec.Code)
+	}
+
+	clock.Advance(1500 * time.Millisecond)
+	if rec := limitedRequest(t, h, "203.0.113.1:1234", ""); rec.Code != http.StatusOK {
+		t.Errorf("expected a refilled token to be allowed, got %d", rec.Code)
+	}
+	if rec := limitedRequest(t, h, "203.0.113.1:1234", ""); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected half a token not to be enough, got %d", rec.Code)
+	}
+}
+
+func TestRateLimiter_Eviction(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(clock.Now, time.Minute)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	fast := limiter.Limit("fast", RateLimit{Rate: 1, Burst: 10})(ok)
+	// refilling a token takes two minutes
+	slow := limiter.Limit("slow", RateLimit{Rate: 1.0 / 120, Burst: 10})(ok)
+
+	limitedRequest(t, fast, "203.0.113.1:1234", "")
+	limitedRequest(t, slow, "203.0.113.1:1234", "")
+	if got := limiter.Len(); got != 2 {
+		t.Fatalf("expected 2 buckets, got %d", got)
+	}
+
+	clock.Advance(30 * time.Second)
+	limitedRequest(t, fast, "203.0.113.2:1234", "")
+	if got := limiter.Len(); got != 3 {
+		t.Errorf("expected no eviction before the interval, got %d buckets", got)
+	}
+
+	clock.Advance(31 * time.Second)
+	limitedRequest(t, fast, "203.0.113.3:1234", "")
+	// the fast buckets refilled and were dropped, the slow one still misses a token
+	if got := limiter.Len(); got != 2 {
+		t.Errorf("expected the full fast buckets to be evicted, got %d buckets", got)
+	}
+
+	clock.Advance(time.Minute)
+	limitedRequest(t, fast, "203.0.113.3:1234", "")
+	if got := limiter.Len(); got != 1 {
+		t.Errorf("expected the refilled slow bucket to be evicted, got %d buckets", got)
+	}
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		wantSame bool
+	}{
+		{name: "valid incoming id is reused", incoming: "abc-123_DEF.4:5", wantSame: true},
+		{name: "missing id is generated", incoming: "", wantSame: false},
+		{name: "id with unsafe characters is replaced", incoming: "abc\ninjected", wantSame: false},
+		{name: "too long id is replaced", incoming: strings.Repeat("a", 129), wantSame: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ctxID string
+			h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctxID = middleware.GetReqID(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(RequestIDHeader)
+			if got == "" {
+				t.Fatal("response has no request id")
+			}
+			if got != ctxID {
+				t.Errorf("response id %q differs from context id %q", got, ctxID)
+			}
+			if (got == tt.incoming) != tt.wantSame {
+				t.Errorf("unexpected id %q for incoming %q", got, tt.incoming)
+			}
+		})
+	}
+}
+
+func TestRequestID_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	out := log.StandardLogger().Out
+	log.SetOutput(&buf)
+	log.SetFormatter(&log.JSONFormatter{})
+	t.Cleanup(func() { log.SetOutput(out) })
+
+	router := RequestID(RequestLoggerMiddleware(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		util.Logger(r.Context()).Info("from handler")
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d: %s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("cannot parse log line %q: %v", line, err)
+		}
+		if entry["request_id"] != "req-1" {
+			t.Errorf("log line %q has no request id", line)
+		}
+	}
+}
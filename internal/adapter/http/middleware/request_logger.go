@@ -5,10 +5,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
 	log "github.com/sirupsen/logrus"
 )
 
 // RequestLoggerMiddleware returns a middleware that logs HTTP requests except for the specified paths.
+// It logs through the request-scoped logger so that the line carries the request ID.
 // It logs method, URL, HTTP version, remote address, status code, response size, and processing duration as structured logs.
 func RequestLoggerMiddleware(skipPaths []string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -25,7 +27,7 @@ func RequestLoggerMiddleware(skipPaths []string) func(http.Handler) http.Handler
 			ww := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 			next.ServeHTTP(ww, r)
 			duration := time.Since(start)
-			log.WithFields(log.Fields{
+			util.Logger(r.Context()).WithFields(log.Fields{
 				"method":        r.Method,
 				"url":           r.URL.String(),
 				"http_version":  r.Proto,
@@ -54,3 +56,10 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	rw.size += size
 	return size, err
 }
+
+// Flush lets handlers stream responses through the wrapped writer.
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
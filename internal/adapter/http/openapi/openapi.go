@@ -0,0 +1,50 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// Document is the OpenAPI 3 document describing every route of the API.
+//
+//go:embed openapi.json
+var Document []byte
+
+const swaggerUI = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Top Sites Ranking API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({url: "/api/v1/openapi.json", dom_id: "#swagger-ui"});
+    };
+  </script>
+</body>
+</html>
+`
+
+// Spec serves the OpenAPI document.
+func Spec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "max-age=3600")
+	if _, err := w.Write(Document); err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err}).Error("cannot write openapi document")
+	}
+}
+
+// SwaggerUI serves a Swagger UI page that renders the OpenAPI document.
+func SwaggerUI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := w.Write([]byte(swaggerUI)); err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err}).Error("cannot write swagger ui")
+	}
+}
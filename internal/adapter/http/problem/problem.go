@@ -0,0 +1,81 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// ContentType is the media type of problem responses defined by RFC 7807.
+const ContentType = "application/problem+json"
+
+// Code is a machine-readable error type that clients can branch on.
+type Code string
+
+const (
+	CodeMissingParameter Code = "missing_parameter"
+	CodeInvalidParameter Code = "invalid_parameter"
+	CodeInvalidRange     Code = "invalid_range"
+	CodeNotFound         Code = "not_found"
+	CodeNotAcceptable    Code = "not_acceptable"
+	CodeRouteNotFound    Code = "route_not_found"
+	CodeMethodNotAllowed Code = "method_not_allowed"
+	CodeUnauthorized     Code = "unauthorized"
+	CodeForbidden        Code = "forbidden"
+	CodeQuotaExceeded    Code = "quota_exceeded"
+	CodeRateLimited      Code = "rate_limited"
+	CodeInvalidBody      Code = "invalid_body"
+	CodeRequestTooLarge  Code = "request_too_large"
+	CodeInternal         Code = "internal_error"
+)
+
+// Problem is the error envelope returned by every endpoint.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      Code   `json:"code"`
+	Param     string `json:"param,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// New builds a Problem for r. param names the offending request parameter and may be empty.
+func New(r *http.Request, status int, code Code, param, detail string) Problem {
+	return Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      code,
+		Param:     param,
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+}
+
+// Write writes a problem response.
+func Write(w http.ResponseWriter, r *http.Request, status int, code Code, param, detail string) {
+	p := New(r, status, code, param, detail)
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		util.Logger(r.Context()).WithFields(log.Fields{"error": err, "code": code}).Error("cannot marshall problem response")
+	}
+}
+
+// NotFound responds to requests for routes that do not exist.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	Write(w, r, http.StatusNotFound, CodeRouteNotFound, "", "No route matches the requested path")
+}
+
+// MethodNotAllowed responds to requests with a method that the route does not support.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	Write(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "", "The route does not support the requested method")
+}
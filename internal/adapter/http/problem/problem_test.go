@@ -0,0 +1,62 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWrite(t *testing.T) {
+	h := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Write(w, r, http.StatusBadRequest, CodeInvalidParameter, "start_date", "start_date must be in YYYY-MM-DD format")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/rankings/daily?start_date=x", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	if c := rec.Header().Get("Content-Type"); c != ContentType {
+		t.Errorf("expected content type %s, got %s", ContentType, c)
+	}
+
+	var got Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := Problem{
+		Type:      "about:blank",
+		Title:     "Bad Request",
+		Status:    http.StatusBadRequest,
+		Detail:    "start_date must be in YYYY-MM-DD format",
+		Instance:  "/api/v1/rankings/daily",
+		Code:      CodeInvalidParameter,
+		Param:     "start_date",
+		RequestID: "req-1",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected problem (-want +got):\n%s", diff)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/unknown", nil)
+	rec := httptest.NewRecorder()
+	NotFound(rec, req)
+
+	var got Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusNotFound || got.Code != CodeRouteNotFound {
+		t.Errorf("unexpected response: status %d, code %s", rec.Code, got.Code)
+	}
+}
@@ -1,9 +1,18 @@
 package http
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/go-chi/cors"
+	"github.com/prometheus/client_golang/prometheus"
 	cfconnectingip "github.com/shigaichi/cf-connecting-ip"
 	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/handler"
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/openapi"
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/problem"
+	"github.com/shigaichi/top-sites-ranking-api/internal/config"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -14,32 +23,139 @@ type Route interface {
 	InitRoute() chi.Route
 }
 
+// Handlers are the handlers of the API. Rankings is required; every other handler is optional, and its routes are only served when it is not nil.
+type Handlers struct {
+	Rankings handler.GetRanking
+	// Lookup looks up ranks of many domains at /api/v1/rankings/lookup.
+	Lookup handler.Lookup
+	// Lists serves the list catalogue at /api/v1/lists with the same authentication as the rankings.
+	Lists handler.Lists
+	// Stats serves TLD statistics at /api/v1/stats with the same authentication as the rankings.
+	Stats handler.Stats
+	// Trend serves rank trends of domains at /api/v1/domains/{domain}/trend with the same authentication as the rankings.
+	Trend handler.Trend
+	// Health serves liveness and readiness at /healthz/live and /healthz/ready.
+	Health handler.Health
+	// Admin is served under /api/v1/admin to API keys with the admin scope. Without API keys there is no admin API.
+	Admin handler.Admin
+}
+
 type RouteImpl struct {
-	h handler.GetRanking
+	handlers Handlers
+	apiKeys  usecase.APIKeyUseCase
+	server   config.ServerConfig
+	auth     config.AuthConfig
+	metrics  *prometheus.Registry
 }
 
-func NewRouteImpl(h handler.GetRanking) *RouteImpl {
-	return &RouteImpl{h: h}
+// NewRouteImpl creates the routes of the API. server.SwaggerUI enables the Swagger UI page at /api/v1/docs.
+// When apiKeys is not nil, the rankings require an API key with the read scope unless auth.AllowAnonymous is set.
+// When metrics is not nil, request metrics are recorded in it. It is not served with the API; see metrics.Handler.
+func NewRouteImpl(handlers Handlers, apiKeys usecase.APIKeyUseCase, server config.ServerConfig, auth config.AuthConfig, metrics *prometheus.Registry) *RouteImpl {
+	return &RouteImpl{handlers: handlers, apiKeys: apiKeys, server: server, auth: auth, metrics: metrics}
 }
 
 func (i RouteImpl) InitRoute() chi.Router {
 	router := chi.NewRouter()
+	router.Use(mymiddleware.RequestID)
+	router.Use(mymiddleware.Tracing())
 	router.Use(cfconnectingip.SetRemoteAddr)
 	//router.Use(middleware.Logger)
-	router.Use(mymiddleware.RequestLoggerMiddleware([]string{"/status"}))
+	router.Use(mymiddleware.RequestLoggerMiddleware(i.server.LogSkipPaths))
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.Heartbeat("/status"))
-	router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowCredentials: false,
-		MaxAge:           600,
-	}))
+	if i.metrics != nil {
+		router.Use(mymiddleware.Metrics(i.metrics))
+	}
+	router.Use(cors.Handler(i.corsOptions()))
 
+	router.NotFound(problem.NotFound)
+	router.MethodNotAllowed(problem.MethodNotAllowed)
+
+	i.routes(router, mymiddleware.NewRateLimiter(time.Now, i.server.RateLimit.EvictionInterval))
+	return router
+}
+
+// routes registers the endpoints of the API on router.
+func (i RouteImpl) routes(router chi.Router, limiter *mymiddleware.RateLimiter) {
 	router.Route("/api/v1/rankings", func(r chi.Router) {
-		r.Get("/daily", i.h.GetDailyRanking)
-		r.Get("/monthly", i.h.GetMonthlyRanking)
+		i.requireRead(r, limiter)
+		// limited after authentication so that clients with an API key get their own bucket
+		r.Use(i.rateLimit(limiter, "rankings", i.server.RateLimit.Rankings))
+		r.Get("/daily", i.handlers.Rankings.GetDailyRanking)
+		r.Get("/monthly", i.handlers.Rankings.GetMonthlyRanking)
+		if i.handlers.Lookup != nil {
+			r.Post("/lookup", i.handlers.Lookup.LookupRanks)
+		}
 	})
 
-	return router
+	if i.handlers.Lists != nil {
+		router.Route("/api/v1/lists", func(r chi.Router) {
+			i.requireRead(r, limiter)
+			r.Use(i.rateLimit(limiter, "default", i.server.RateLimit.Default))
+			r.Get("/", i.handlers.Lists.GetLists)
+			r.Get("/latest", i.handlers.Lists.GetLatestList)
+		})
+	}
+
+	if i.handlers.Stats != nil {
+		router.Route("/api/v1/stats", func(r chi.Router) {
+			i.requireRead(r, limiter)
+			r.Use(i.rateLimit(limiter, "default", i.server.RateLimit.Default))
+			r.Get("/tld", i.handlers.Stats.GetTLDStats)
+			r.Get("/tld/history", i.handlers.Stats.GetTLDHistory)
+		})
+	}
+
+	if i.handlers.Trend != nil {
+		router.Route("/api/v1/domains", func(r chi.Router) {
+			i.requireRead(r, limiter)
+			// computed from the rankings, so limited like them
+			r.Use(i.rateLimit(limiter, "rankings", i.server.RateLimit.Rankings))
+			r.Get("/{domain}/trend", i.handlers.Trend.GetTrend)
+		})
+	}
+
+	if i.apiKeys != nil && i.handlers.Admin != nil {
+		router.Route("/api/v1/admin", func(r chi.Router) {
+			i.requireScope(r, limiter, model.ScopeAdmin, false)
+			r.Use(i.rateLimit(limiter, "default", i.server.RateLimit.Default))
+			r.Post("/ingest", i.handlers.Admin.Ingest)
+			r.Post("/retention", i.handlers.Admin.Retention)
+			r.Delete("/lists/{id}", i.handlers.Admin.DeleteList)
+			r.Get("/jobs/{id}", i.handlers.Admin.GetJob)
+		})
+	}
+
+	router.With(i.rateLimit(limiter, "default", i.server.RateLimit.Default)).Get("/api/v1/openapi.json", openapi.Spec)
+	if i.handlers.Health != nil {
+		router.Get("/healthz/live", i.handlers.Health.Live)
+		router.Get("/healthz/ready", i.handlers.Health.Ready)
+	}
+	if i.server.SwaggerUI {
+		router.With(i.rateLimit(limiter, "default", i.server.RateLimit.Default)).Get("/api/v1/docs", openapi.SwaggerUI)
+	}
+}
+
+// requireRead requires an API key with the read scope on the routes of r, unless anonymous access is allowed.
+func (i RouteImpl) requireRead(r chi.Router, limiter *mymiddleware.RateLimiter) {
+	if i.apiKeys != nil {
+		i.requireScope(r, limiter, model.ScopeRead, i.auth.AllowAnonymous)
+	}
+}
+
+// requireScope requires an API key with scope on the routes of r. Each client IP is limited before its key is looked up,
+// so that requests with invalid keys are throttled too.
+func (i RouteImpl) requireScope(r chi.Router, limiter *mymiddleware.RateLimiter, scope model.Scope, allowAnonymous bool) {
+	r.Use(i.rateLimit(limiter, "ip", i.server.RateLimit.IP))
+	r.Use(mymiddleware.Authenticate(i.apiKeys))
+	r.Use(mymiddleware.RequireScope(scope, allowAnonymous))
+}
+
+// rateLimit returns the middleware that limits a route group to rule, or one that passes every request when rate limiting is disabled.
+func (i RouteImpl) rateLimit(limiter *mymiddleware.RateLimiter, name string, rule config.RateLimitRule) func(http.Handler) http.Handler {
+	if !i.server.RateLimit.Enabled {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return limiter.Limit(name, mymiddleware.RateLimit{Rate: float64(rule.RequestsPerMinute) / 60, Burst: rule.Burst})
 }
@@ -0,0 +1,210 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	mymiddleware "github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/middleware"
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/openapi"
+	"github.com/shigaichi/top-sites-ranking-api/internal/config"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
+)
+
+type getRankingStub struct{}
+
+func (getRankingStub) GetDailyRanking(w http.ResponseWriter, r *http.Request)   {}
+func (getRankingStub) GetMonthlyRanking(w http.ResponseWriter, r *http.Request) {}
+
+type lookupStub struct{}
+
+func (lookupStub) LookupRanks(w http.ResponseWriter, r *http.Request) {}
+
+type listsStub struct{}
+
+func (listsStub) GetLists(w http.ResponseWriter, r *http.Request)      {}
+func (listsStub) GetLatestList(w http.ResponseWriter, r *http.Request) {}
+
+type statsStub struct{}
+
+func (statsStub) GetTLDStats(w http.ResponseWriter, r *http.Request)   {}
+func (statsStub) GetTLDHistory(w http.ResponseWriter, r *http.Request) {}
+
+type trendStub struct{}
+
+func (trendStub) GetTrend(w http.ResponseWriter, r *http.Request) {}
+
+type healthStub struct{}
+
+func (healthStub) Live(w http.ResponseWriter, r *http.Request)  {}
+func (healthStub) Ready(w http.ResponseWriter, r *http.Request) {}
+
+type adminStub struct{}
+
+func (adminStub) Ingest(w http.ResponseWriter, r *http.Request)     {}
+func (adminStub) Retention(w http.ResponseWriter, r *http.Request)  {}
+func (adminStub) DeleteList(w http.ResponseWriter, r *http.Request) {}
+func (adminStub) GetJob(w http.ResponseWriter, r *http.Request)     {}
+
+func specOperations(t *testing.T) map[string]map[string]bool {
+	t.Helper()
+
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openapi.Document, &doc); err != nil {
+		t.Fatalf("openapi document is not valid json: %v", err)
+	}
+
+	operations := map[string]map[string]bool{}
+	for path, item := range doc.Paths {
+		operations[path] = map[string]bool{}
+		for method := range item {
+			operations[path][strings.ToUpper(method)] = true
+		}
+	}
+	return operations
+}
+
+func TestOpenAPICoversEveryRoute(t *testing.T) {
+	operations := specOperations(t)
+	// the admin routes only exist with API key authentication
+	router := NewRouteImpl(allHandlers, apiKeyStub{}, config.Default().Server, config.AuthConfig{Enabled: true}, prometheus.NewRegistry()).InitRoute()
+
+	routes := map[string]map[string]bool{}
+	err := chi.Walk(router, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		route = strings.TrimSuffix(route, "/")
+		if routes[route] == nil {
+			routes[route] = map[string]bool{}
+		}
+		routes[route][method] = true
+
+		if !operations[route][method] {
+			t.Errorf("route %s %s is not documented in openapi.json", method, route)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, methods := range operations {
+		for method := range methods {
+			if !routes[path][method] {
+				t.Errorf("openapi.json documents %s %s, but it is not routed", method, path)
+			}
+		}
+	}
+}
+
+var allHandlers = Handlers{
+	Rankings: getRankingStub{},
+	Lookup:   lookupStub{},
+	Lists:    listsStub{},
+	Stats:    statsStub{},
+	Trend:    trendStub{},
+	Health:   healthStub{},
+	Admin:    adminStub{},
+}
+
+type apiKeyStub struct {
+	usecase.APIKeyUseCase
+}
+
+func (apiKeyStub) Authenticate(ctx context.Context, key string) (model.APIKey, error) {
+	switch key {
+	case "reader":
+		return model.APIKey{ID: 1, Name: "reader", Scopes: []model.Scope{model.ScopeRead}}, nil
+	case "admin":
+		return model.APIKey{ID: 2, Name: "admin", Scopes: []model.Scope{model.ScopeRead, model.ScopeAdmin}}, nil
+	}
+	return model.APIKey{}, usecase.ErrInvalidAPIKey
+}
+
+func TestAuthGuardsRankingsAndAdmin(t *testing.T) {
+	router := NewRouteImpl(allHandlers, apiKeyStub{}, config.Default().Server, config.AuthConfig{Enabled: true}, nil).InitRoute()
+
+	tests := []struct {
+		method     string
+		path       string
+		key        string
+		wantStatus int
+	}{
+		{method: http.MethodGet, path: "/api/v1/rankings/daily", wantStatus: http.StatusUnauthorized},
+		{method: http.MethodGet, path: "/api/v1/rankings/daily", key: "reader", wantStatus: http.StatusOK},
+		{method: http.MethodGet, path: "/api/v1/rankings/monthly", key: "unknown", wantStatus: http.StatusUnauthorized},
+		{method: http.MethodGet, path: "/api/v1/lists", wantStatus: http.StatusUnauthorized},
+		{method: http.MethodGet, path: "/api/v1/lists/latest", key: "reader", wantStatus: http.StatusOK},
+		{method: http.MethodGet, path: "/healthz/live", wantStatus: http.StatusOK},
+		{method: http.MethodGet, path: "/api/v1/openapi.json", wantStatus: http.StatusOK},
+		{method: http.MethodPost, path: "/api/v1/admin/ingest", wantStatus: http.StatusUnauthorized},
+		{method: http.MethodPost, path: "/api/v1/admin/ingest", key: "reader", wantStatus: http.StatusForbidden},
+		{method: http.MethodPost, path: "/api/v1/admin/ingest", key: "admin", wantStatus: http.StatusOK},
+		{method: http.MethodDelete, path: "/api/v1/admin/lists/X5Y7N", key: "reader", wantStatus: http.StatusForbidden},
+		{method: http.MethodGet, path: "/api/v1/admin/jobs/1", key: "admin", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.key != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.key)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s %s with key %q: expected status %d, got %d", tt.method, tt.path, tt.key, tt.wantStatus, rec.Code)
+		}
+	}
+}
+
+// countingAPIKeys counts the keys it is asked to authenticate.
+type countingAPIKeys struct {
+	apiKeyStub
+	calls int
+}
+
+func (c *countingAPIKeys) Authenticate(ctx context.Context, key string) (model.APIKey, error) {
+	c.calls++
+	return c.apiKeyStub.Authenticate(ctx, key)
+}
+
+func TestRateLimitBeforeAuthentication(t *testing.T) {
+	server := config.Default().Server
+	server.RateLimit.IP = config.RateLimitRule{RequestsPerMinute: 1, Burst: 3}
+	keys := &countingAPIKeys{}
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	router := chi.NewRouter()
+	NewRouteImpl(allHandlers, keys, server, config.AuthConfig{Enabled: true}, nil).routes(router, mymiddleware.NewRateLimiter(func() time.Time { return now }, time.Minute))
+
+	for i, want := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/rankings/daily", nil)
+		req.RemoteAddr = "203.0.113.1:1234"
+		req.Header.Set("Authorization", "Bearer unknown")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("request %d: expected status %d, got %d", i+1, want, rec.Code)
+		}
+	}
+	if keys.calls != 3 {
+		t.Errorf("expected the throttled request not to look up its key, got %d lookups", keys.calls)
+	}
+
+	// the IP bucket refills with time
+	now = now.Add(time.Minute)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/rankings/daily", nil)
+	req.RemoteAddr = "203.0.113.1:1234"
+	req.Header.Set("Authorization", "Bearer reader")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status %d once the bucket refilled, got %d", http.StatusOK, rec.Code)
+	}
+}
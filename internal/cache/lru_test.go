@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU[string, int](2, nil)
+	c.Add("a", 1, time.Hour)
+	c.Add("b", 2, time.Hour)
+	// touching a makes b the least recently used.
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	c.Add("c", 3, time.Hour)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	for key, want := range map[string]int{"a": 1, "c": 3} {
+		if got, ok := c.Get(key); !ok || got != want {
+			t.Errorf("expected %s=%d, got %d (present %v)", key, want, got, ok)
+		}
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", c.Len())
+	}
+}
+
+func TestLRU_Expires(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c := NewLRU[string, int](10, clock.now)
+	c.Add("short", 1, time.Minute)
+	c.Add("long", 2, time.Hour)
+
+	clock.t = clock.t.Add(time.Minute)
+
+	if _, ok := c.Get("short"); ok {
+		t.Error("expected short to be expired")
+	}
+	if _, ok := c.Get("long"); !ok {
+		t.Error("expected long to be cached")
+	}
+	if c.Len() != 1 {
+		t.Errorf("expected expired entry to be removed, got %d entries", c.Len())
+	}
+}
+
+func TestLRU_AddReplacesAndPurge(t *testing.T) {
+	c := NewLRU[string, int](10, nil)
+	c.Add("a", 1, time.Hour)
+	c.Add("a", 2, time.Hour)
+
+	if got, _ := c.Get("a"); got != 2 {
+		t.Errorf("expected replaced value 2, got %d", got)
+	}
+	if c.Len() != 1 {
+		t.Errorf("expected 1 entry, got %d", c.Len())
+	}
+
+	c.Purge()
+	if _, ok := c.Get("a"); ok || c.Len() != 0 {
+		t.Error("expected cache to be empty after purge")
+	}
+}
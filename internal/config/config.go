@@ -0,0 +1,326 @@
+// Package config loads the settings of the api server and the batch jobs into one validated struct.
+// Values are taken from defaults, then an optional YAML file, then environment variables and finally flags.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Log       LogConfig       `yaml:"log"`
+	Writer    WriterConfig    `yaml:"writer"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+	Readiness ReadinessConfig `yaml:"readiness"`
+	Cache     CacheConfig     `yaml:"cache"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Lookup    LookupConfig    `yaml:"lookup"`
+}
+
+type ServerConfig struct {
+	Port              int           `yaml:"port"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+	SwaggerUI         bool          `yaml:"swagger_ui"`
+	// LogSkipPaths are path prefixes whose requests are not logged.
+	LogSkipPaths []string        `yaml:"log_skip_paths"`
+	CORS         CORSConfig      `yaml:"cors"`
+	RateLimit    RateLimitConfig `yaml:"rate_limit"`
+}
+
+// CORSConfig configures which browser origins may call the API.
+// The allowed methods and headers are derived from the registered routes.
+type CORSConfig struct {
+	// AllowedOrigins are exact origins such as "https://example.com", origins with a wildcard subdomain
+	// such as "https://*.example.com", or "*" for any origin.
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	// AllowedHeaders are request headers allowed in addition to the ones the API reads.
+	AllowedHeaders   []string `yaml:"allowed_headers"`
+	AllowCredentials bool     `yaml:"allow_credentials"`
+	// MaxAge is how many seconds browsers may cache a preflight response.
+	MaxAge int `yaml:"max_age"`
+}
+
+// RateLimitConfig throttles each client, identified by its API key or IP, with one token bucket per route group.
+type RateLimitConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Rankings limits the ranking routes.
+	Rankings RateLimitRule `yaml:"rankings"`
+	// Default limits the other API routes. Health checks are never limited.
+	Default RateLimitRule `yaml:"default"`
+	// IP limits each client IP across the routes that take an API key, before the key is checked,
+	// so that unknown keys cannot make the server look them up without limit. Only used with authentication.
+	// It is shared by every key used from the IP, so it should be higher than the other rules.
+	IP RateLimitRule `yaml:"ip"`
+	// EvictionInterval is how often buckets of clients that went idle are dropped.
+	EvictionInterval time.Duration `yaml:"eviction_interval"`
+}
+
+// RateLimitRule allows RequestsPerMinute on average and bursts of up to Burst requests.
+type RateLimitRule struct {
+	RequestsPerMinute int `yaml:"requests_per_minute"`
+	Burst             int `yaml:"burst"`
+}
+
+type DatabaseConfig struct {
+	// URL is a full postgres:// connection URL. When set, Host, Port, User, Password and Name are ignored,
+	// and the TLS and timeout options below only fill in parameters the URL does not set.
+	URL      string `yaml:"url"`
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	// SSLMode is left out of the connection when empty, so that libpq's default (prefer) or the sslmode of URL applies.
+	SSLMode string `yaml:"sslmode"`
+	// SSLRootCert is the CA bundle the server certificate is verified against.
+	SSLRootCert string `yaml:"sslrootcert"`
+	// SSLCert and SSLKey are the client certificate and its key.
+	SSLCert string `yaml:"sslcert"`
+	SSLKey  string `yaml:"sslkey"`
+	// ConnectTimeout bounds each connection attempt. Zero waits indefinitely.
+	ConnectTimeout time.Duration `yaml:"connect_timeout"`
+	// StatementTimeout aborts any statement running longer. Zero disables it.
+	StatementTimeout time.Duration `yaml:"statement_timeout"`
+	// MaxOpenConns limits the open connections. Zero means unlimited.
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+	// ConnectRetries is how many times connecting is retried at startup, doubling ConnectRetryInterval each time.
+	ConnectRetries       int           `yaml:"connect_retries"`
+	ConnectRetryInterval time.Duration `yaml:"connect_retry_interval"`
+	// ReplicaURLs are postgres:// URLs of read replicas the api server reads from.
+	// They share the TLS, timeout and pool options above.
+	ReplicaURLs []string `yaml:"replica_urls"`
+	// ReplicaHealthCheckInterval is how often replicas are pinged.
+	ReplicaHealthCheckInterval time.Duration `yaml:"replica_health_check_interval"`
+}
+
+type LogConfig struct {
+	Level string `yaml:"level"`
+}
+
+type WriterConfig struct {
+	// BatchSize is how many rankings are inserted by one statement.
+	BatchSize int `yaml:"batch_size"`
+}
+
+type MetricsConfig struct {
+	// Textfile is where batch jobs write their metrics for the node exporter textfile collector.
+	Textfile string `yaml:"textfile"`
+	// PushgatewayURL is where batch jobs push their metrics.
+	PushgatewayURL string `yaml:"pushgateway_url"`
+	// ListenAddress is where the api server serves /metrics, apart from the API so that it is not exposed with it.
+	// Empty disables the metrics of the api server.
+	ListenAddress string `yaml:"listen_address"`
+}
+
+type ReadinessConfig struct {
+	// Staleness is how old the latest list may be before the api server is reported as not ready.
+	Staleness time.Duration `yaml:"staleness"`
+}
+
+// CacheConfig configures the in-process cache of rank histories in the api server.
+type CacheConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Size is the maximum number of cached results.
+	Size int `yaml:"size"`
+	// TTL is how long results whose range includes today are cached.
+	TTL time.Duration `yaml:"ttl"`
+	// PastTTL is how long results whose range ended before today are cached.
+	PastTTL time.Duration `yaml:"past_ttl"`
+	// VersionCheckInterval is how often the saved lists are checked to drop the cache when a list is saved or deleted.
+	VersionCheckInterval time.Duration `yaml:"version_check_interval"`
+}
+
+// AuthConfig configures API key authentication in the api server.
+type AuthConfig struct {
+	// Enabled checks the API keys sent with requests. Without it every request is anonymous.
+	Enabled bool `yaml:"enabled"`
+	// AllowAnonymous lets requests without an API key read rankings while authentication is enabled.
+	AllowAnonymous bool `yaml:"allow_anonymous"`
+}
+
+// LookupConfig configures the bulk rank lookup endpoint.
+type LookupConfig struct {
+	// MaxDomains is how many domains one request may look up. It also bounds the size of the request body.
+	MaxDomains int `yaml:"max_domains"`
+	// BatchSize is how many domains are looked up by one query.
+	BatchSize int `yaml:"batch_size"`
+}
+
+// maxBatchSize keeps a bulk insert of three columns per ranking under the 65535 parameters Postgres accepts.
+const maxBatchSize = 65535 / 3
+
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// Default returns the configuration used when nothing is overridden.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:              3333,
+			ReadHeaderTimeout: 3 * time.Minute,
+			ShutdownTimeout:   30 * time.Second,
+			SwaggerUI:         true,
+			LogSkipPaths:      []string{"/status", "/healthz", "/metrics"},
+			CORS: CORSConfig{
+				AllowedOrigins:   []string{"*"},
+				AllowCredentials: false,
+				MaxAge:           600,
+			},
+			RateLimit: RateLimitConfig{
+				Enabled:          true,
+				Rankings:         RateLimitRule{RequestsPerMinute: 60, Burst: 30},
+				Default:          RateLimitRule{RequestsPerMinute: 120, Burst: 60},
+				IP:               RateLimitRule{RequestsPerMinute: 600, Burst: 300},
+				EvictionInterval: time.Minute,
+			},
+		},
+		Database: DatabaseConfig{
+			Host:     "localhost",
+			Port:     5432,
+			User:     "user",
+			Password: "password",
+			Name:     "ranking",
+
+			ConnectTimeout:       10 * time.Second,
+			MaxOpenConns:         20,
+			MaxIdleConns:         10,
+			ConnMaxLifetime:      30 * time.Minute,
+			ConnMaxIdleTime:      5 * time.Minute,
+			ConnectRetries:       5,
+			ConnectRetryInterval: time.Second,
+
+			ReplicaHealthCheckInterval: 10 * time.Second,
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+		Writer: WriterConfig{
+			BatchSize: 10000,
+		},
+		Readiness: ReadinessConfig{
+			Staleness: 72 * time.Hour,
+		},
+		Cache: CacheConfig{
+			Enabled:              true,
+			Size:                 10000,
+			TTL:                  5 * time.Minute,
+			PastTTL:              24 * time.Hour,
+			VersionCheckInterval: time.Minute,
+		},
+		Auth: AuthConfig{
+			Enabled:        false,
+			AllowAnonymous: true,
+		},
+		Lookup: LookupConfig{
+			MaxDomains: 10000,
+			BatchSize:  1000,
+		},
+	}
+}
+
+// Validate reports every invalid value of c.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port must be between 1 and 65535, got %d", c.Server.Port)
+	check(c.Server.ReadHeaderTimeout > 0, "server.read_header_timeout must be positive, got %s", c.Server.ReadHeaderTimeout)
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive, got %s", c.Server.ShutdownTimeout)
+
+	check(len(c.Server.CORS.AllowedOrigins) > 0, "server.cors.allowed_origins must not be empty")
+	for _, origin := range c.Server.CORS.AllowedOrigins {
+		check(isValidOrigin(origin), "server.cors.allowed_origins contains invalid origin %q, expected scheme://host[:port], scheme://*.host[:port] or *", origin)
+	}
+	check(!c.Server.CORS.AllowCredentials || !contains(c.Server.CORS.AllowedOrigins, "*"), "server.cors.allow_credentials cannot be combined with the origin *")
+	check(c.Server.CORS.MaxAge >= 0, "server.cors.max_age must not be negative, got %d", c.Server.CORS.MaxAge)
+	if c.Server.RateLimit.Enabled {
+		checkRule := func(name string, rule RateLimitRule) {
+			check(rule.RequestsPerMinute > 0, "server.rate_limit.%s.requests_per_minute must be positive, got %d", name, rule.RequestsPerMinute)
+			check(rule.Burst > 0, "server.rate_limit.%s.burst must be positive, got %d", name, rule.Burst)
+		}
+		checkRule("rankings", c.Server.RateLimit.Rankings)
+		checkRule("default", c.Server.RateLimit.Default)
+		checkRule("ip", c.Server.RateLimit.IP)
+		check(c.Server.RateLimit.EvictionInterval > 0, "server.rate_limit.eviction_interval must be positive, got %s", c.Server.RateLimit.EvictionInterval)
+	}
+
+	if c.Database.URL != "" {
+		u, err := url.Parse(c.Database.URL)
+		check(err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql"), "database.url must be a postgres:// or postgresql:// URL")
+	} else {
+		check(c.Database.Host != "", "database.host must not be empty")
+		check(c.Database.Port > 0 && c.Database.Port <= 65535, "database.port must be between 1 and 65535, got %d", c.Database.Port)
+		check(c.Database.User != "", "database.user must not be empty")
+		check(c.Database.Name != "", "database.name must not be empty")
+	}
+	check(c.Database.SSLMode == "" || contains(sslModes, c.Database.SSLMode), "database.sslmode must be one of %v, got %q", sslModes, c.Database.SSLMode)
+	check((c.Database.SSLCert == "") == (c.Database.SSLKey == ""), "database.sslcert and database.sslkey must be set together")
+	check(c.Database.ConnectTimeout >= 0, "database.connect_timeout must not be negative, got %s", c.Database.ConnectTimeout)
+	check(c.Database.StatementTimeout >= 0, "database.statement_timeout must not be negative, got %s", c.Database.StatementTimeout)
+	check(c.Database.MaxOpenConns >= 0, "database.max_open_conns must not be negative, got %d", c.Database.MaxOpenConns)
+	check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns must not be negative, got %d", c.Database.MaxIdleConns)
+	check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns, "database.max_idle_conns (%d) must not exceed database.max_open_conns (%d)", c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime must not be negative, got %s", c.Database.ConnMaxLifetime)
+	check(c.Database.ConnMaxIdleTime >= 0, "database.conn_max_idle_time must not be negative, got %s", c.Database.ConnMaxIdleTime)
+	check(c.Database.ConnectRetries >= 0, "database.connect_retries must not be negative, got %d", c.Database.ConnectRetries)
+	for _, replica := range c.Database.ReplicaURLs {
+		u, err := url.Parse(replica)
+		check(err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql"), "database.replica_urls must be postgres:// or postgresql:// URLs")
+	}
+	check(c.Database.ReplicaHealthCheckInterval > 0, "database.replica_health_check_interval must be positive, got %s", c.Database.ReplicaHealthCheckInterval)
+	check(c.Database.ConnectRetryInterval > 0, "database.connect_retry_interval must be positive, got %s", c.Database.ConnectRetryInterval)
+
+	_, err := log.ParseLevel(c.Log.Level)
+	check(err == nil, "log.level %q is not a valid level", c.Log.Level)
+
+	check(c.Writer.BatchSize > 0 && c.Writer.BatchSize <= maxBatchSize, "writer.batch_size must be between 1 and %d, got %d", maxBatchSize, c.Writer.BatchSize)
+
+	check(c.Readiness.Staleness > 0, "readiness.staleness must be positive, got %s", c.Readiness.Staleness)
+
+	if c.Cache.Enabled {
+		check(c.Cache.Size > 0, "cache.size must be positive, got %d", c.Cache.Size)
+		check(c.Cache.TTL > 0, "cache.ttl must be positive, got %s", c.Cache.TTL)
+		check(c.Cache.PastTTL > 0, "cache.past_ttl must be positive, got %s", c.Cache.PastTTL)
+		check(c.Cache.VersionCheckInterval > 0, "cache.version_check_interval must be positive, got %s", c.Cache.VersionCheckInterval)
+	}
+
+	check(c.Lookup.MaxDomains > 0, "lookup.max_domains must be positive, got %d", c.Lookup.MaxDomains)
+	check(c.Lookup.BatchSize > 0, "lookup.batch_size must be positive, got %d", c.Lookup.BatchSize)
+
+	return errors.Join(errs...)
+}
+
+// isValidOrigin accepts "*", and origins whose host may start with a "*." wildcard subdomain.
+func isValidOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+	u, err := url.Parse(strings.Replace(origin, "://*.", "://wildcard.", 1))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false
+	}
+	return u.Path == "" && u.RawQuery == "" && u.User == nil && !strings.Contains(u.Host, "*")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
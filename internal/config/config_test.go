@@ -0,0 +1,198 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("cannot write config file: %v", err)
+	}
+	return path
+}
+
+func TestDefault_IsValid(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Errorf("default config is invalid: %v", err)
+	}
+}
+
+func TestValidate_CORSOrigins(t *testing.T) {
+	cfg := Default()
+	cfg.Server.CORS.AllowedOrigins = []string{"https://example.com", "https://*.example.com", "http://localhost:3000"}
+	cfg.Server.CORS.AllowCredentials = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, `
+server:
+  port: 4000
+  read_header_timeout: 10s
+  cors:
+    allowed_origins: ["https://example.com"]
+database:
+  host: db.internal
+  port: 6432
+writer:
+  batch_size: 500
+`)
+	t.Setenv("DB_PORT", "7432")
+	t.Setenv("DB_REPLICA_URLS", "postgres://replica1/ranking,postgres://replica2/ranking")
+	t.Setenv("WRITER_BATCH_SIZE", "600")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg, err := Load(fs, []string{"-config", path, "-batch-size", "700"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Default()
+	want.Server.Port = 4000
+	want.Server.ReadHeaderTimeout = 10 * time.Second
+	want.Server.CORS.AllowedOrigins = []string{"https://example.com"}
+	want.Database.Host = "db.internal"
+	want.Database.Port = 7432
+	want.Database.ReplicaURLs = []string{"postgres://replica1/ranking", "postgres://replica2/ranking"}
+	want.Writer.BatchSize = 700
+	if diff := cmp.Diff(want, cfg); diff != "" {
+		t.Errorf("unexpected config (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoad_ConfigFileFromEnv(t *testing.T) {
+	t.Setenv("CONFIG_FILE", writeFile(t, "readiness:\n  staleness: 24h\n"))
+
+	cfg, err := Load(nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Readiness.Staleness != 24*time.Hour {
+		t.Errorf("expected staleness 24h, got %s", cfg.Readiness.Staleness)
+	}
+}
+
+func TestLoad_DatabaseURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{name: "DATABASE_URL", env: map[string]string{"DATABASE_URL": "postgres://platform/ranking"}, expected: "postgres://platform/ranking"},
+		{name: "DB_URL alias", env: map[string]string{"DB_URL": "postgres://alias/ranking"}, expected: "postgres://alias/ranking"},
+		{
+			name:     "DATABASE_URL wins over the alias",
+			env:      map[string]string{"DATABASE_URL": "postgres://platform/ranking", "DB_URL": "postgres://alias/ranking"},
+			expected: "postgres://platform/ranking",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := Load(nil, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.Database.URL != tt.expected {
+				t.Errorf("expected database url %q, got %q", tt.expected, cfg.Database.URL)
+			}
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		file    string
+		args    []string
+		wantErr []string
+	}{
+		{
+			name:    "malformed env",
+			env:     map[string]string{"SERVER_PORT": "abc"},
+			wantErr: []string{"invalid environment variable SERVER_PORT=\"abc\": must be an integer"},
+		},
+		{
+			name:    "malformed flag",
+			args:    []string{"-readiness-staleness", "3 days"},
+			wantErr: []string{"invalid flag -readiness-staleness=\"3 days\""},
+		},
+		{
+			name:    "unknown key in file",
+			file:    "server:\n  prot: 80\n",
+			wantErr: []string{"failed to parse config file", "field prot not found"},
+		},
+		{
+			name: "invalid cors origins",
+			env:  map[string]string{"CORS_ALLOWED_ORIGINS": "https://example.com/app,example.com,https://*.*.example.com", "CORS_ALLOW_CREDENTIALS": "true"},
+			wantErr: []string{
+				"invalid origin \"https://example.com/app\"",
+				"invalid origin \"example.com\"",
+				"invalid origin \"https://*.*.example.com\"",
+			},
+		},
+		{
+			name:    "credentials with any origin",
+			env:     map[string]string{"CORS_ALLOWED_ORIGINS": "*", "CORS_ALLOW_CREDENTIALS": "true"},
+			wantErr: []string{"server.cors.allow_credentials cannot be combined with the origin *"},
+		},
+		{
+			name: "every invalid value is reported",
+			env:  map[string]string{"SERVER_PORT": "70000", "DB_SSLMODE": "maybe", "WRITER_BATCH_SIZE": "30000", "LOG_LEVEL": "loud"},
+			wantErr: []string{
+				"server.port must be between 1 and 65535, got 70000",
+				"database.sslmode must be one of",
+				"writer.batch_size must be between 1 and 21845, got 30000",
+				"log.level \"loud\" is not a valid level",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeFile(t, tt.file)}, args...)
+			}
+
+			_, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), args)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad_ExampleFile(t *testing.T) {
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-config", "../../config.example.yaml"})
+	if err != nil {
+		t.Fatalf("example config is invalid: %v", err)
+	}
+	if diff := cmp.Diff(Default(), cfg); diff != "" {
+		t.Errorf("example config differs from defaults (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// setting is one value that can be overridden by an environment variable and a flag.
+type setting struct {
+	env string
+	// aliases are other environment variables read for the setting. env wins when both are set.
+	aliases []string
+	flag    string
+	usage   string
+	set     func(c *Config, value string) error
+}
+
+var settings = []setting{
+	intSetting("SERVER_PORT", "port", "Port the api server listens on", func(c *Config) *int { return &c.Server.Port }),
+	durationSetting("SERVER_READ_HEADER_TIMEOUT", "read-header-timeout", "How long the api server waits for request headers", func(c *Config) *time.Duration { return &c.Server.ReadHeaderTimeout }),
+	durationSetting("SERVER_SHUTDOWN_TIMEOUT", "shutdown-timeout", "How long the api server waits for requests on shutdown", func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout }),
+	boolSetting("SWAGGER_UI_ENABLED", "swagger-ui", "Serve the Swagger UI at /api/v1/docs", func(c *Config) *bool { return &c.Server.SwaggerUI }),
+	listSetting("CORS_ALLOWED_ORIGINS", "cors-allowed-origins", "Comma separated origins allowed by CORS", func(c *Config) *[]string { return &c.Server.CORS.AllowedOrigins }),
+	listSetting("CORS_ALLOWED_HEADERS", "cors-allowed-headers", "Comma separated request headers allowed by CORS in addition to the ones the API reads", func(c *Config) *[]string { return &c.Server.CORS.AllowedHeaders }),
+	boolSetting("CORS_ALLOW_CREDENTIALS", "cors-allow-credentials", "Allow credentials in CORS requests", func(c *Config) *bool { return &c.Server.CORS.AllowCredentials }),
+	intSetting("CORS_MAX_AGE", "cors-max-age", "Seconds browsers may cache a CORS preflight response", func(c *Config) *int { return &c.Server.CORS.MaxAge }),
+	boolSetting("RATE_LIMIT_ENABLED", "rate-limit", "Throttle clients by API key or IP", func(c *Config) *bool { return &c.Server.RateLimit.Enabled }),
+	intSetting("RATE_LIMIT_RANKINGS_PER_MINUTE", "rate-limit-rankings-per-minute", "Ranking requests a client may make per minute", func(c *Config) *int { return &c.Server.RateLimit.Rankings.RequestsPerMinute }),
+	intSetting("RATE_LIMIT_RANKINGS_BURST", "rate-limit-rankings-burst", "Ranking requests a client may make in a burst", func(c *Config) *int { return &c.Server.RateLimit.Rankings.Burst }),
+	intSetting("RATE_LIMIT_DEFAULT_PER_MINUTE", "rate-limit-default-per-minute", "Requests to other API routes a client may make per minute", func(c *Config) *int { return &c.Server.RateLimit.Default.RequestsPerMinute }),
+	intSetting("RATE_LIMIT_DEFAULT_BURST", "rate-limit-default-burst", "Requests to other API routes a client may make in a burst", func(c *Config) *int { return &c.Server.RateLimit.Default.Burst }),
+	intSetting("RATE_LIMIT_IP_PER_MINUTE", "rate-limit-ip-per-minute", "Requests to routes that take an API key a client IP may make per minute, before the key is checked", func(c *Config) *int { return &c.Server.RateLimit.IP.RequestsPerMinute }),
+	intSetting("RATE_LIMIT_IP_BURST", "rate-limit-ip-burst", "Requests to routes that take an API key a client IP may make in a burst, before the key is checked", func(c *Config) *int { return &c.Server.RateLimit.IP.Burst }),
+	durationSetting("RATE_LIMIT_EVICTION_INTERVAL", "rate-limit-eviction-interval", "How often idle rate limit buckets are dropped", func(c *Config) *time.Duration { return &c.Server.RateLimit.EvictionInterval }),
+	// DATABASE_URL is the name managed Postgres platforms inject; DB_URL matches the other database settings
+	withAliases(stringSetting("DATABASE_URL", "database-url", "Full postgres:// connection URL, overriding the other DB_ connection settings", func(c *Config) *string { return &c.Database.URL }), "DB_URL"),
+	listSetting("DB_REPLICA_URLS", "db-replica-urls", "Comma separated postgres:// URLs of read replicas the api server reads from", func(c *Config) *[]string { return &c.Database.ReplicaURLs }),
+	durationSetting("DB_REPLICA_HEALTH_CHECK_INTERVAL", "db-replica-health-check-interval", "How often read replicas are pinged", func(c *Config) *time.Duration { return &c.Database.ReplicaHealthCheckInterval }),
+	stringSetting("DB_HOST", "db-host", "Database host", func(c *Config) *string { return &c.Database.Host }),
+	intSetting("DB_PORT", "db-port", "Database port", func(c *Config) *int { return &c.Database.Port }),
+	stringSetting("DB_USER", "db-user", "Database user", func(c *Config) *string { return &c.Database.User }),
+	stringSetting("DB_PASSWORD", "db-password", "Database password", func(c *Config) *string { return &c.Database.Password }),
+	stringSetting("DB_NAME", "db-name", "Database name", func(c *Config) *string { return &c.Database.Name }),
+	stringSetting("DB_SSLMODE", "db-sslmode", "Database sslmode", func(c *Config) *string { return &c.Database.SSLMode }),
+	stringSetting("DB_SSLROOTCERT", "db-sslrootcert", "CA bundle to verify the database certificate with", func(c *Config) *string { return &c.Database.SSLRootCert }),
+	stringSetting("DB_SSLCERT", "db-sslcert", "Client certificate for the database", func(c *Config) *string { return &c.Database.SSLCert }),
+	stringSetting("DB_SSLKEY", "db-sslkey", "Key of the client certificate for the database", func(c *Config) *string { return &c.Database.SSLKey }),
+	durationSetting("DB_CONNECT_TIMEOUT", "db-connect-timeout", "Timeout of each database connection attempt", func(c *Config) *time.Duration { return &c.Database.ConnectTimeout }),
+	durationSetting("DB_STATEMENT_TIMEOUT", "db-statement-timeout", "Timeout of each statement, 0 to disable", func(c *Config) *time.Duration { return &c.Database.StatementTimeout }),
+	intSetting("DB_MAX_OPEN_CONNS", "db-max-open-conns", "Maximum open database connections, 0 for unlimited", func(c *Config) *int { return &c.Database.MaxOpenConns }),
+	intSetting("DB_MAX_IDLE_CONNS", "db-max-idle-conns", "Maximum idle database connections", func(c *Config) *int { return &c.Database.MaxIdleConns }),
+	durationSetting("DB_CONN_MAX_LIFETIME", "db-conn-max-lifetime", "Maximum lifetime of a database connection", func(c *Config) *time.Duration { return &c.Database.ConnMaxLifetime }),
+	durationSetting("DB_CONN_MAX_IDLE_TIME", "db-conn-max-idle-time", "Maximum idle time of a database connection", func(c *Config) *time.Duration { return &c.Database.ConnMaxIdleTime }),
+	intSetting("DB_CONNECT_RETRIES", "db-connect-retries", "Times connecting to the database is retried at startup", func(c *Config) *int { return &c.Database.ConnectRetries }),
+	durationSetting("DB_CONNECT_RETRY_INTERVAL", "db-connect-retry-interval", "Wait before the first connect retry, doubled on each retry", func(c *Config) *time.Duration { return &c.Database.ConnectRetryInterval }),
+	stringSetting("LOG_LEVEL", "log-level", "Log level", func(c *Config) *string { return &c.Log.Level }),
+	listSetting("LOG_SKIP_PATHS", "log-skip-paths", "Comma separated path prefixes whose requests are not logged", func(c *Config) *[]string { return &c.Server.LogSkipPaths }),
+	intSetting("WRITER_BATCH_SIZE", "batch-size", "Rankings inserted by one statement", func(c *Config) *int { return &c.Writer.BatchSize }),
+	stringSetting("METRICS_TEXTFILE", "metrics-textfile", "File batch jobs write their metrics to", func(c *Config) *string { return &c.Metrics.Textfile }),
+	stringSetting("METRICS_LISTEN_ADDRESS", "metrics-listen-address", "Address the api server serves /metrics on, empty to disable", func(c *Config) *string { return &c.Metrics.ListenAddress }),
+	stringSetting("PUSHGATEWAY_URL", "pushgateway-url", "Pushgateway batch jobs push their metrics to", func(c *Config) *string { return &c.Metrics.PushgatewayURL }),
+	durationSetting("READINESS_STALENESS", "readiness-staleness", "Age of the latest list after which the api server is not ready", func(c *Config) *time.Duration { return &c.Readiness.Staleness }),
+	boolSetting("CACHE_ENABLED", "cache", "Cache rank histories in memory", func(c *Config) *bool { return &c.Cache.Enabled }),
+	intSetting("CACHE_SIZE", "cache-size", "Maximum number of cached rank histories", func(c *Config) *int { return &c.Cache.Size }),
+	durationSetting("CACHE_TTL", "cache-ttl", "How long rank histories including today are cached", func(c *Config) *time.Duration { return &c.Cache.TTL }),
+	durationSetting("CACHE_PAST_TTL", "cache-past-ttl", "How long rank histories that ended before today are cached", func(c *Config) *time.Duration { return &c.Cache.PastTTL }),
+	durationSetting("CACHE_VERSION_CHECK_INTERVAL", "cache-version-check-interval", "How often the saved lists are checked to drop the cache when one is saved or deleted", func(c *Config) *time.Duration { return &c.Cache.VersionCheckInterval }),
+	boolSetting("AUTH_ENABLED", "auth", "Authenticate requests with API keys", func(c *Config) *bool { return &c.Auth.Enabled }),
+	boolSetting("AUTH_ALLOW_ANONYMOUS", "auth-allow-anonymous", "Let requests without an API key read rankings", func(c *Config) *bool { return &c.Auth.AllowAnonymous }),
+	intSetting("LOOKUP_MAX_DOMAINS", "lookup-max-domains", "Domains one bulk rank lookup may contain", func(c *Config) *int { return &c.Lookup.MaxDomains }),
+	intSetting("LOOKUP_BATCH_SIZE", "lookup-batch-size", "Domains looked up by one query of a bulk rank lookup", func(c *Config) *int { return &c.Lookup.BatchSize }),
+}
+
+// Load builds the configuration from defaults, the YAML file given by -config or CONFIG_FILE,
+// environment variables and flags, in increasing order of precedence, and validates it.
+// The config flags are registered on fs, which is then parsed with args. When fs is nil no flags are read.
+func Load(fs *flag.FlagSet, args []string) (Config, error) {
+	var path string
+	flagValues := map[string]*string{}
+	if fs != nil {
+		fs.StringVar(&path, "config", "", "Path to a YAML config file (env CONFIG_FILE)")
+		for _, s := range settings {
+			flagValues[s.flag] = fs.String(s.flag, "", fmt.Sprintf("%s (env %s)", s.usage, strings.Join(append([]string{s.env}, s.aliases...), " or ")))
+		}
+		if err := fs.Parse(args); err != nil {
+			return Config{}, err
+		}
+	}
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+
+	cfg := Default()
+	if path != "" {
+		if err := loadFile(path, &cfg); err != nil {
+			return Config{}, err
+		}
+	}
+
+	var errs []error
+	for _, s := range settings {
+		for _, env := range append([]string{s.env}, s.aliases...) {
+			value, ok := os.LookupEnv(env)
+			if !ok {
+				continue
+			}
+			if err := s.set(&cfg, value); err != nil {
+				errs = append(errs, fmt.Errorf("invalid environment variable %s=%q: %w", env, value, err))
+			}
+			break
+		}
+	}
+
+	if fs != nil {
+		set := map[string]bool{}
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		for _, s := range settings {
+			if !set[s.flag] {
+				continue
+			}
+			value := *flagValues[s.flag]
+			if err := s.set(&cfg, value); err != nil {
+				errs = append(errs, fmt.Errorf("invalid flag -%s=%q: %w", s.flag, value, err))
+			}
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// loadFile overrides cfg with the values in the YAML file at path. Unknown keys are rejected to catch typos.
+func loadFile(path string, cfg *Config) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// withAliases makes s also read the environment variables aliases.
+func withAliases(s setting, aliases ...string) setting {
+	s.aliases = aliases
+	return s
+}
+
+func stringSetting(env, flag, usage string, field func(c *Config) *string) setting {
+	return setting{env: env, flag: flag, usage: usage, set: func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}}
+}
+
+func intSetting(env, flag, usage string, field func(c *Config) *int) setting {
+	return setting{env: env, flag: flag, usage: usage, set: func(c *Config, value string) error {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		*field(c) = v
+		return nil
+	}}
+}
+
+func boolSetting(env, flag, usage string, field func(c *Config) *bool) setting {
+	return setting{env: env, flag: flag, usage: usage, set: func(c *Config, value string) error {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		*field(c) = v
+		return nil
+	}}
+}
+
+func durationSetting(env, flag, usage string, field func(c *Config) *time.Duration) setting {
+	return setting{env: env, flag: flag, usage: usage, set: func(c *Config, value string) error {
+		v, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.New("must be a duration such as 30s or 72h")
+		}
+		*field(c) = v
+		return nil
+	}}
+}
+
+func listSetting(env, flag, usage string, field func(c *Config) *[]string) setting {
+	return setting{env: env, flag: flag, usage: usage, set: func(c *Config, value string) error {
+		var values []string
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		*field(c) = values
+		return nil
+	}}
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Scope is a permission granted to an API key.
+type Scope string
+
+const (
+	// ScopeRead allows reading rankings.
+	ScopeRead Scope = "read"
+	// ScopeAdmin allows managing the ingested data.
+	ScopeAdmin Scope = "admin"
+)
+
+// ParseScope converts a scope name into a Scope.
+func ParseScope(s string) (Scope, error) {
+	switch sc := Scope(s); sc {
+	case ScopeRead, ScopeAdmin:
+		return sc, nil
+	default:
+		return "", fmt.Errorf("unknown scope: %s", s)
+	}
+}
+
+// APIKey is a client of the API. The key itself is never stored, only its hash.
+type APIKey struct {
+	ID     int
+	Name   string
+	Scopes []Scope
+	// DailyQuota is the number of requests allowed per UTC day, 0 means unlimited.
+	DailyQuota int
+	CreatedAt  time.Time
+	// RevokedAt is set once the key must no longer be accepted.
+	RevokedAt *time.Time
+}
+
+// HasScope reports whether the key was granted scope.
+func (k APIKey) HasScope(scope Scope) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// Revoked reports whether the key was revoked.
+func (k APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
@@ -1,8 +1,61 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type DailyRank struct {
 	Rank int
 	Date time.Time
+	// ListID is the ID of the Tranco list the rank comes from.
+	ListID string
+}
+
+// FillMode controls how days without a rank are represented in a daily rank history.
+type FillMode string
+
+const (
+	// FillNone returns only the days on which the domain was ranked.
+	FillNone FillMode = ""
+	// FillNull returns every day and leaves the rank empty for days without a rank.
+	FillNull FillMode = "null"
+	// FillPrevious carries the last known rank forward over days without a rank.
+	FillPrevious FillMode = "previous"
+	// FillInterpolate linearly interpolates ranks between the surrounding ranked days.
+	FillInterpolate FillMode = "interpolate"
+)
+
+// ParseFillMode converts a query value into a FillMode.
+func ParseFillMode(s string) (FillMode, error) {
+	switch m := FillMode(s); m {
+	case FillNone, FillNull, FillPrevious, FillInterpolate:
+		return m, nil
+	default:
+		return FillNone, fmt.Errorf("unknown fill mode: %s", s)
+	}
+}
+
+// RankStatus tells why a day in a filled rank history has or lacks a rank.
+type RankStatus string
+
+const (
+	// RankStatusRanked means the domain appears in the list of that day.
+	RankStatusRanked RankStatus = "ranked"
+	// RankStatusNotRanked means the list of that day was ingested but does not contain the domain.
+	RankStatusNotRanked RankStatus = "not_ranked"
+	// RankStatusNotIngested means no list was ingested for that day.
+	RankStatusNotIngested RankStatus = "not_ingested"
+)
+
+// DailyRankEntry is one day of a rank history that contains an entry for every requested day.
+// Rank is nil when the day has no rank and the fill mode could not provide one.
+// Filled is true when Rank was derived from other days instead of being read from the list.
+type DailyRankEntry struct {
+	Rank   *int
+	Date   time.Time
+	Status RankStatus
+	Filled bool
+	// ListID is the ID of the Tranco list of the day, empty when the day is not ingested.
+	ListID string
 }
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// DomainName is a ranked domain together with the parts it is grouped by.
+type DomainName struct {
+	Domain string
+	// Registrable is the public suffix plus one label, e.g. google.com for mail.google.com.
+	// It is the domain itself when the domain is a public suffix or has no known suffix.
+	Registrable string
+	// TLD is the last label of the domain, e.g. com.
+	TLD string
+}
+
+// ParseDomainName splits domain by the public suffix list.
+func ParseDomainName(domain string) DomainName {
+	registrable, err := publicsuffix.EffectiveTLDPlusOne(domain)
+	if err != nil {
+		registrable = domain
+	}
+	return DomainName{
+		Domain:      domain,
+		Registrable: registrable,
+		TLD:         domain[strings.LastIndex(domain, ".")+1:],
+	}
+}
+
+// Rollup controls whether ranks are reported for a host or for its registrable domain.
+type Rollup string
+
+const (
+	// RollupNone reports the ranks of the requested domain only.
+	RollupNone Rollup = ""
+	// RollupRegistrable reports the best rank among every domain that shares the registrable domain of the requested one.
+	RollupRegistrable Rollup = "registrable"
+)
+
+// ParseRollup converts a query value into a Rollup.
+func ParseRollup(s string) (Rollup, error) {
+	switch r := Rollup(s); r {
+	case RollupNone, RollupRegistrable:
+		return r, nil
+	default:
+		return RollupNone, fmt.Errorf("unknown rollup: %s", s)
+	}
+}
@@ -0,0 +1,7 @@
+package model
+
+// DomainRank is the result of looking up one domain in a list. Rank is nil when the domain is not ranked.
+type DomainRank struct {
+	Domain string
+	Rank   *int
+}
@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// JobType is the kind of work an admin job does.
+type JobType string
+
+const (
+	// JobTypeIngest saves the Tranco list of a day.
+	JobTypeIngest JobType = "ingest"
+	// JobTypeRetention deletes the lists older than the retention period, except those of month ends.
+	JobTypeRetention JobType = "retention"
+	// JobTypeDeleteList deletes one list and its rankings.
+	JobTypeDeleteList JobType = "delete_list"
+)
+
+// JobStatus is the state of an admin job.
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusFailed    JobStatus = "failed"
+)
+
+// Job is ingestion or deletion work started through the admin API and run in the background.
+type Job struct {
+	ID     string
+	Type   JobType
+	Status JobStatus
+	// Params are the parameters the job was started with.
+	Params map[string]string
+	// CreatedAt is when the job was queued. StartedAt and FinishedAt are zero until it starts and finishes.
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Rows is how many rankings were written or how many lists were deleted.
+	Rows int
+	// ListIDs are the lists a retention dry run would delete.
+	ListIDs []string
+	// Error is the reason a failed job failed.
+	Error string
+}
+
+// Finished reports whether the job will not change anymore.
+func (j Job) Finished() bool {
+	return j.Status == JobStatusSucceeded || j.Status == JobStatusFailed
+}
@@ -0,0 +1,17 @@
+package model
+
+// RankChange is the movement of a domain between two lists.
+// FromRank or ToRank is nil when the domain is not within the compared range of that list.
+type RankChange struct {
+	Domain   string
+	FromRank *int
+	ToRank   *int
+}
+
+// Change returns how many places the domain climbed. It is zero when the domain entered or left the range.
+func (c RankChange) Change() int {
+	if c.FromRank == nil || c.ToRank == nil {
+		return 0
+	}
+	return *c.FromRank - *c.ToRank
+}
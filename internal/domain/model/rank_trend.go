@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// TrendClass tells how the rank of a domain moved over a range.
+type TrendClass string
+
+const (
+	// TrendRising means the domain climbed, that is its rank number went down.
+	TrendRising TrendClass = "rising"
+	// TrendFalling means the domain dropped, that is its rank number went up.
+	TrendFalling TrendClass = "falling"
+	// TrendStable means the rank hardly moved.
+	TrendStable TrendClass = "stable"
+	// TrendVolatile means the rank swung too much around its trend for the trend to be meaningful.
+	TrendVolatile TrendClass = "volatile"
+)
+
+// RankTrend summarizes the ranks of a domain over a range. Points is zero when the domain was never ranked in the range.
+type RankTrend struct {
+	Points    int
+	FirstRank int
+	LastRank  int
+	// Slope is the daily change of the natural logarithm of the rank, fitted by least squares.
+	// A negative slope means the domain is climbing.
+	Slope float64
+	// StdDev is the population standard deviation of the ranks.
+	StdDev float64
+	// MaxDrawdown is the worst drop in positions, from the best rank so far to a later rank.
+	// DrawdownFrom and DrawdownTo are the days of both ranks, and are zero when the domain never dropped.
+	MaxDrawdown  int
+	DrawdownFrom time.Time
+	DrawdownTo   time.Time
+	Class        TrendClass
+	// ListIDs are the IDs of the Tranco lists the ranks come from.
+	ListIDs []string
+}
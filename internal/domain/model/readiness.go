@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Readiness is the result of checking whether the API can serve up-to-date rankings.
+type Readiness struct {
+	// DatabaseErr is the error of pinging the database, nil when it is reachable.
+	DatabaseErr error
+	// LatestList is the most recently created list, the zero value when none is saved or the database is down.
+	LatestList TrancoList
+	// Age is how long ago the latest list was created.
+	Age time.Duration
+	// Stale is true when no list is saved or the latest list is older than the staleness threshold.
+	Stale bool
+}
+
+// Ready reports whether the database is reachable and the data is fresh.
+func (r Readiness) Ready() bool {
+	return r.DatabaseErr == nil && !r.Stale
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// TLDStatsTops are the top N cut-offs whose TLD counts are precomputed for every list.
+var TLDStatsTops = []int{100, 1000, 10000, 100000, 1000000}
+
+// IsTLDStatsTop reports whether the TLD counts of the top n domains are precomputed.
+func IsTLDStatsTop(n int) bool {
+	return slices.Contains(TLDStatsTops, n)
+}
+
+// TLDCount is how many domains of a TLD appear in the top N of a list.
+type TLDCount struct {
+	TLD     string
+	Domains int
+}
+
+// TLDListCount is a TLDCount of one list.
+type TLDListCount struct {
+	ListID    string    `db:"list_id"`
+	CreatedOn time.Time `db:"created_on"`
+	TLD       string
+	Domains   int
+}
+
+// TLDDistribution is how the top N domains of a list are spread over TLDs.
+type TLDDistribution struct {
+	List TrancoList
+	Top  int
+	// Total is the number of domains in the top N, which is less than Top only for short lists.
+	Total  int
+	Counts []TLDCount
+	// Other is the number of domains whose TLD is not in Counts.
+	Other int
+}
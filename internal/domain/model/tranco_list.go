@@ -6,3 +6,9 @@ type TrancoList struct {
 	ID        string
 	CreatedOn time.Time `db:"created_on"`
 }
+
+// TrancoListSummary is a stored list together with how many rankings it has.
+type TrancoListSummary struct {
+	TrancoList
+	Rows int `db:"row_count"`
+}
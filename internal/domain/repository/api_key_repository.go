@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+type APIKeyRepository interface {
+	// Create saves key under hash and returns it with its ID and creation time.
+	Create(ctx context.Context, key model.APIKey, hash string) (model.APIKey, error)
+	// FindByHash returns the key whose hash is hash, or the zero value when there is none.
+	FindByHash(ctx context.Context, hash string) (model.APIKey, error)
+	// FindAll returns every key ordered by name.
+	FindAll(ctx context.Context) ([]model.APIKey, error)
+	// Revoke marks the key named name as revoked at and reports whether such a key exists.
+	Revoke(ctx context.Context, name string, at time.Time) (bool, error)
+	// IncrementUsage counts one request of the key on day and returns the number of requests of that day.
+	IncrementUsage(ctx context.Context, keyID int, day time.Time) (int, error)
+}
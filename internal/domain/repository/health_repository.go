@@ -0,0 +1,7 @@
+package repository
+
+import "context"
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+}
@@ -1,11 +1,12 @@
 package repository
 
 import (
+	"context"
 	"net/url"
 
 	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
 )
 
 type TrancoCsvRepository interface {
-	Get(url url.URL) ([]model.SiteRanking, error)
+	Get(ctx context.Context, url url.URL) ([]model.SiteRanking, error)
 }
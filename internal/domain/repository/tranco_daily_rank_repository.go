@@ -9,4 +9,8 @@ import (
 
 type TrancoDailyRankRepository interface {
 	GetDailyRanksByDateRange(ctx context.Context, domain string, start time.Time, end time.Time) ([]model.DailyRank, error)
+	// GetRegistrableDailyRanksByDateRange returns, for each list, the best rank among the domains whose registrable domain is registrable.
+	GetRegistrableDailyRanksByDateRange(ctx context.Context, registrable string, start time.Time, end time.Time) ([]model.DailyRank, error)
+	// GetRanksByDomains returns the rankings of the given domains in the list listID. Unranked domains are left out.
+	GetRanksByDomains(ctx context.Context, listID string, domains []string) ([]model.SiteRanking, error)
 }
@@ -2,9 +2,15 @@ package repository
 
 import (
 	"context"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
 )
 
 type TrancoDomainsRepository interface {
 	GetIDByDomain(ctx context.Context, domain string) (int, error)
-	Save(ctx context.Context, domain string) (int, error)
+	Save(ctx context.Context, name model.DomainName) (int, error)
+	// FindUnparsed returns up to limit domains whose registrable domain and TLD are not stored yet.
+	FindUnparsed(ctx context.Context, limit int) ([]string, error)
+	// UpdateNames stores the registrable domains and TLDs of already saved domains.
+	UpdateNames(ctx context.Context, names []model.DomainName) (int, error)
 }
@@ -10,6 +10,18 @@ import (
 type TrancoListsRepository interface {
 	ExistsID(ctx context.Context, id string) (bool, error)
 	Save(ctx context.Context, list model.TrancoList) error
+	// SetRowCount stores how many rankings the list id has, so that its summary does not count them.
+	SetRowCount(ctx context.Context, id string, rows int) error
 	FindByCreatedOnLessThan(ctx context.Context, date time.Time) ([]model.TrancoList, error)
+	FindByCreatedOnBetween(ctx context.Context, start time.Time, end time.Time) ([]model.TrancoList, error)
 	DeleteByID(ctx context.Context, id string) error
+	// FindLatest returns the most recently created list, or the zero value when no list is saved.
+	FindLatest(ctx context.Context) (model.TrancoList, error)
+	// FindVersion returns a fingerprint of the saved lists, which changes whenever a list is saved or deleted.
+	// It is empty when no list is saved.
+	FindVersion(ctx context.Context) (string, error)
+	// FindSummariesByCreatedOnBetween returns the lists created from start to the end of the end date with their row counts, ordered by created_on.
+	FindSummariesByCreatedOnBetween(ctx context.Context, start time.Time, end time.Time) ([]model.TrancoListSummary, error)
+	// FindLatestSummary returns the most recently created list with its row count, or the zero value when no list is saved.
+	FindLatestSummary(ctx context.Context) (model.TrancoListSummary, error)
 }
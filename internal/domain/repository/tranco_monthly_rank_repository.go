@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+// TrancoMonthlyRankRepository keeps a copy of the rankings of month-end lists, which is kept when daily rankings are deleted.
+type TrancoMonthlyRankRepository interface {
+	// Refresh copies the rankings of the month-end list listID into the snapshot.
+	Refresh(ctx context.Context, listID string) error
+	// RefreshMissing copies the rankings of every month-end list that is not in the snapshot yet and returns how many rows were copied.
+	RefreshMissing(ctx context.Context) (int, error)
+	// DeleteByListID removes the rankings of the list listID from the snapshot.
+	DeleteByListID(ctx context.Context, listID string) error
+	// GetMonthlyRanksByDateRange returns the month-end ranks of domain from start to the end of the end date, newest first.
+	GetMonthlyRanksByDateRange(ctx context.Context, domain string, start time.Time, end time.Time) ([]model.DailyRank, error)
+	// GetRegistrableMonthlyRanksByDateRange returns, for each month end, the best rank among the domains whose registrable domain is registrable.
+	GetRegistrableMonthlyRanksByDateRange(ctx context.Context, registrable string, start time.Time, end time.Time) ([]model.DailyRank, error)
+}
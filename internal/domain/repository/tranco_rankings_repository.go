@@ -10,4 +10,5 @@ type TrancoRankingsRepository interface {
 	Save(ctx context.Context, ranking model.TrancoRanking) error
 	BulkSave(ctx context.Context, rankings []model.TrancoRanking) error
 	DeleteByListID(ctx context.Context, listID string) error
+	FindTopByListID(ctx context.Context, listID string, limit int) ([]model.SiteRanking, error)
 }
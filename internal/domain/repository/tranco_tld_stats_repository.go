@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+// TrancoTLDStatsRepository stores how many domains of each TLD appear in the top N of a list, for N in model.TLDStatsTops.
+type TrancoTLDStatsRepository interface {
+	// Refresh counts the domains of each TLD in the list listID for every top N in tops and stores the counts.
+	Refresh(ctx context.Context, listID string, tops []int) error
+	// FindByListID returns the counts of the top N of the list listID, largest first, or nil when none are stored.
+	FindByListID(ctx context.Context, listID string, top int) ([]model.TLDCount, error)
+	// FindLargestTLDs returns the n TLDs with the most domains in the top N of the lists created from start to the end of the end date.
+	FindLargestTLDs(ctx context.Context, top int, start time.Time, end time.Time, n int) ([]string, error)
+	// FindByCreatedOnBetween returns the counts of tlds in the top N of the lists created from start to the end of the end date,
+	// oldest list first. The domains of every other TLD are counted together under the empty TLD.
+	FindByCreatedOnBetween(ctx context.Context, top int, start time.Time, end time.Time, tlds []string) ([]model.TLDListCount, error)
+}
@@ -0,0 +1,160 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+)
+
+// apiKeyColumns selects an api_keys row into apiKeyRow. Scopes are joined so that they scan into a string.
+const apiKeyColumns = `id, name, array_to_string(scopes, ',') AS scopes, daily_quota, created_at, revoked_at`
+
+type apiKeyRow struct {
+	ID         int          `db:"id"`
+	Name       string       `db:"name"`
+	Scopes     string       `db:"scopes"`
+	DailyQuota int          `db:"daily_quota"`
+	CreatedAt  time.Time    `db:"created_at"`
+	RevokedAt  sql.NullTime `db:"revoked_at"`
+}
+
+func (r apiKeyRow) toModel() model.APIKey {
+	key := model.APIKey{
+		ID:         r.ID,
+		Name:       r.Name,
+		DailyQuota: r.DailyQuota,
+		CreatedAt:  r.CreatedAt,
+	}
+	for _, s := range strings.Split(r.Scopes, ",") {
+		if s != "" {
+			key.Scopes = append(key.Scopes, model.Scope(s))
+		}
+	}
+	if r.RevokedAt.Valid {
+		revokedAt := r.RevokedAt.Time
+		key.RevokedAt = &revokedAt
+	}
+	return key
+}
+
+type APIKeyRepositoryImpl struct {
+	db util.Crudable
+}
+
+func NewAPIKeyRepositoryImpl(db util.Crudable) *APIKeyRepositoryImpl {
+	return &APIKeyRepositoryImpl{db: db}
+}
+
+func (a APIKeyRepositoryImpl) Create(ctx context.Context, key model.APIKey, hash string) (model.APIKey, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = a.db
+	}
+
+	scopes := make([]string, len(key.Scopes))
+	for i, s := range key.Scopes {
+		scopes[i] = string(s)
+	}
+
+	var row apiKeyRow
+	query := `INSERT INTO api_keys (name, key_hash, scopes, daily_quota) VALUES ($1, $2, string_to_array($3, ','), $4) RETURNING ` + apiKeyColumns
+	ctx, span := startQuerySpan(ctx, "api_keys.create")
+	err := dao.GetContext(ctx, &row, query, key.Name, hash, strings.Join(scopes, ","), key.DailyQuota)
+	endQuerySpan(span, 1, err)
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("failed to create api key %s: %w", key.Name, err)
+	}
+	return row.toModel(), nil
+}
+
+func (a APIKeyRepositoryImpl) FindByHash(ctx context.Context, hash string) (model.APIKey, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = a.db
+	}
+
+	var row apiKeyRow
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+	ctx, span := startQuerySpan(ctx, "api_keys.find_by_hash")
+	err := dao.GetContext(ctx, &row, query, hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		endQuerySpan(span, 0, nil)
+		return model.APIKey{}, nil
+	}
+	endQuerySpan(span, 1, err)
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("failed to find api key by hash: %w", err)
+	}
+	return row.toModel(), nil
+}
+
+func (a APIKeyRepositoryImpl) FindAll(ctx context.Context) ([]model.APIKey, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = a.db
+	}
+
+	var rows []apiKeyRow
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY name`
+	ctx, span := startQuerySpan(ctx, "api_keys.find_all")
+	err := dao.SelectContext(ctx, &rows, query)
+	endQuerySpan(span, len(rows), err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find api keys: %w", err)
+	}
+
+	keys := make([]model.APIKey, len(rows))
+	for i, row := range rows {
+		keys[i] = row.toModel()
+	}
+	return keys, nil
+}
+
+func (a APIKeyRepositoryImpl) Revoke(ctx context.Context, name string, at time.Time) (bool, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = a.db
+	}
+
+	query := `UPDATE api_keys SET revoked_at = $2 WHERE name = $1 AND revoked_at IS NULL`
+	ctx, span := startQuerySpan(ctx, "api_keys.revoke")
+	res, err := dao.ExecContext(ctx, query, name, at)
+	affected := rowsAffected(res)
+	endQuerySpan(span, affected, err)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke api key %s: %w", name, err)
+	}
+	return affected > 0, nil
+}
+
+func (a APIKeyRepositoryImpl) IncrementUsage(ctx context.Context, keyID int, day time.Time) (int, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = a.db
+	}
+
+	var requests int
+	query := `
+INSERT INTO api_key_usage (key_id, day, requests)
+VALUES ($1, $2, 1)
+ON CONFLICT (key_id, day) DO UPDATE SET requests = api_key_usage.requests + 1
+RETURNING requests`
+	ctx, span := startQuerySpan(ctx, "api_key_usage.increment")
+	err := dao.QueryRowContext(ctx, query, keyID, day.UTC().Format("2006-01-02")).Scan(&requests)
+	endQuerySpan(span, 1, err)
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment usage of api key %d: %w", keyID, err)
+	}
+	return requests, nil
+}
@@ -2,26 +2,133 @@ package infra
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
-	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+	"github.com/shigaichi/top-sites-ranking-api/internal/config"
+	log "github.com/sirupsen/logrus"
 )
 
-func NewDb() (*sqlx.DB, error) {
-	user := util.GetEnvWithDefault("DB_USER", "user")
-	port := util.GetEnvWithDefault("DB_PORT", "5432")
-	host := util.GetEnvWithDefault("DB_HOST", "localhost")
-	password := util.GetEnvWithDefault("DB_PASSWORD", "password")
-	dbname := util.GetEnvWithDefault("DB_NAME", "ranking")
-	dataSourceName := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", host, port, user, password, dbname)
-	db, err := sqlx.Connect("pgx", dataSourceName)
+// maxConnectRetryInterval caps the backoff between connect retries.
+const maxConnectRetryInterval = 30 * time.Second
+
+func NewDb(cfg config.DatabaseConfig) (*sqlx.DB, error) {
+	dataSourceName, err := BuildDSN(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database in db configuration. error: %w", err)
+		return nil, fmt.Errorf("failed to build dsn in db configuration. error: %w", err)
 	}
-	err = db.Ping()
+
+	db, err := connectWithRetry(func() (*sqlx.DB, error) { return sqlx.Connect("pgx", dataSourceName) }, cfg.ConnectRetries, cfg.ConnectRetryInterval, time.Sleep)
 	if err != nil {
-		return nil, fmt.Errorf("failed to ping database in db configuration. error: %w", err)
+		return nil, fmt.Errorf("failed to connect to database in db configuration. error: %w", err)
 	}
+
+	setPool(db, cfg)
 	return db, nil
 }
+
+// NewReplicaDbs opens a connection pool for every replica URL with the options of cfg.
+// Replicas are not pinged here, so that an unreachable replica does not stop startup; RoutingDB checks their health.
+func NewReplicaDbs(cfg config.DatabaseConfig) ([]*sqlx.DB, error) {
+	var dbs []*sqlx.DB
+	for _, replicaURL := range cfg.ReplicaURLs {
+		replicaCfg := cfg
+		replicaCfg.URL = replicaURL
+		dataSourceName, err := BuildDSN(replicaCfg)
+		if err != nil {
+			closeAll(dbs)
+			return nil, fmt.Errorf("failed to build replica dsn in db configuration. error: %w", err)
+		}
+
+		db, err := sqlx.Open("pgx", dataSourceName)
+		if err != nil {
+			closeAll(dbs)
+			return nil, fmt.Errorf("failed to open replica in db configuration. error: %w", err)
+		}
+		setPool(db, cfg)
+		dbs = append(dbs, db)
+	}
+	return dbs, nil
+}
+
+func setPool(db *sqlx.DB, cfg config.DatabaseConfig) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+}
+
+func closeAll(dbs []*sqlx.DB) {
+	for _, db := range dbs {
+		_ = db.Close()
+	}
+}
+
+// BuildDSN returns the postgres:// URL to connect with. It starts from cfg.URL when set, otherwise from
+// the host, port, user, password and name, then adds the TLS and timeout parameters the URL does not set.
+func BuildDSN(cfg config.DatabaseConfig) (string, error) {
+	var u *url.URL
+	if cfg.URL != "" {
+		var err error
+		u, err = url.Parse(cfg.URL)
+		if err != nil {
+			return "", fmt.Errorf("invalid database url: %w", err)
+		}
+		if u.Scheme != "postgres" && u.Scheme != "postgresql" {
+			return "", fmt.Errorf("database url must start with postgres:// or postgresql://, got scheme %q", u.Scheme)
+		}
+	} else {
+		u = &url.URL{
+			Scheme: "postgres",
+			User:   url.UserPassword(cfg.User, cfg.Password),
+			Host:   net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+			Path:   "/" + cfg.Name,
+		}
+	}
+
+	q := u.Query()
+	setDefault := func(key, value string) {
+		if value != "" && q.Get(key) == "" {
+			q.Set(key, value)
+		}
+	}
+	setDefault("sslmode", cfg.SSLMode)
+	setDefault("sslrootcert", cfg.SSLRootCert)
+	setDefault("sslcert", cfg.SSLCert)
+	setDefault("sslkey", cfg.SSLKey)
+	if cfg.ConnectTimeout > 0 {
+		// connect_timeout is in whole seconds, so round up to never wait less than configured.
+		setDefault("connect_timeout", strconv.Itoa(int((cfg.ConnectTimeout+time.Second-1)/time.Second)))
+	}
+	if cfg.StatementTimeout > 0 {
+		// unknown parameters are sent to the server as run-time parameters, statement_timeout is in milliseconds.
+		setDefault("statement_timeout", strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10))
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// connectWithRetry calls connect until it succeeds or retries are exhausted, doubling the wait each time.
+func connectWithRetry(connect func() (*sqlx.DB, error), retries int, interval time.Duration, sleep func(time.Duration)) (*sqlx.DB, error) {
+	var err error
+	for attempt := 0; ; attempt++ {
+		var db *sqlx.DB
+		db, err = connect()
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= retries {
+			break
+		}
+
+		log.WithFields(log.Fields{"error": err, "attempt": attempt + 1, "retry_in": interval.String()}).Warn("failed to connect to database, retrying")
+		sleep(interval)
+		interval = min(interval*2, maxConnectRetryInterval)
+	}
+	return nil, fmt.Errorf("gave up after %d retries: %w", retries, err)
+}
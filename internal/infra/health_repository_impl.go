@@ -0,0 +1,26 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type HealthRepositoryImpl struct {
+	db *sqlx.DB
+}
+
+func NewHealthRepositoryImpl(db *sqlx.DB) *HealthRepositoryImpl {
+	return &HealthRepositoryImpl{db: db}
+}
+
+func (h HealthRepositoryImpl) Ping(ctx context.Context) error {
+	ctx, span := startQuerySpan(ctx, "ping")
+	err := h.db.PingContext(ctx)
+	endQuerySpan(span, 0, err)
+	if err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
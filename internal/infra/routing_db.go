@@ -0,0 +1,154 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// healthCheckTimeout bounds one ping of a replica.
+const healthCheckTimeout = 2 * time.Second
+
+type pingableDB interface {
+	util.Crudable
+	PingContext(ctx context.Context) error
+}
+
+type replica struct {
+	db      pingableDB
+	name    string
+	healthy atomic.Bool
+}
+
+// RoutingDB sends reads to healthy replicas in turn and everything else to the primary.
+// Reads fall back to the primary when no replica is healthy or a replica cannot be reached.
+// Queries inside Transaction.DoInTx never get here because repositories use the transaction from the context.
+type RoutingDB struct {
+	primary  pingableDB
+	replicas []*replica
+	next     atomic.Uint64
+	interval time.Duration
+}
+
+// NewRoutingDB creates a RoutingDB. Every replica starts as healthy until a health check fails.
+func NewRoutingDB(primary *sqlx.DB, replicas []*sqlx.DB, interval time.Duration) *RoutingDB {
+	dbs := make([]pingableDB, len(replicas))
+	for i, r := range replicas {
+		dbs[i] = r
+	}
+	return newRoutingDB(primary, dbs, interval)
+}
+
+func newRoutingDB(primary pingableDB, replicas []pingableDB, interval time.Duration) *RoutingDB {
+	r := &RoutingDB{primary: primary, interval: interval}
+	for i, db := range replicas {
+		rep := &replica{db: db, name: fmt.Sprintf("replica-%d", i)}
+		rep.healthy.Store(true)
+		r.replicas = append(r.replicas, rep)
+	}
+	return r
+}
+
+// Start checks the health of the replicas every interval until ctx is done.
+func (r *RoutingDB) Start(ctx context.Context) {
+	if len(r.replicas) == 0 {
+		return
+	}
+	r.CheckHealth(ctx)
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.CheckHealth(ctx)
+			}
+		}
+	}()
+}
+
+// CheckHealth pings every replica and records whether it answered.
+func (r *RoutingDB) CheckHealth(ctx context.Context) {
+	for _, rep := range r.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		err := rep.db.PingContext(pingCtx)
+		cancel()
+
+		healthy := err == nil
+		if rep.healthy.Swap(healthy) != healthy {
+			if healthy {
+				log.WithFields(log.Fields{"replica": rep.name}).Info("replica is healthy again")
+			} else {
+				log.WithFields(log.Fields{"replica": rep.name, "error": err}).Warn("replica is unhealthy, reads fall back to other replicas or the primary")
+			}
+		}
+	}
+}
+
+// reader returns the next healthy replica, or nil when there is none.
+func (r *RoutingDB) reader() *replica {
+	n := uint64(len(r.replicas))
+	if n == 0 {
+		return nil
+	}
+	start := r.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		rep := r.replicas[(start+i)%n]
+		if rep.healthy.Load() {
+			return rep
+		}
+	}
+	return nil
+}
+
+// read runs query on a replica, retrying on the primary when the replica cannot be reached.
+func (r *RoutingDB) read(ctx context.Context, query func(db util.Crudable) error) error {
+	rep := r.reader()
+	if rep == nil {
+		return query(r.primary)
+	}
+
+	err := query(rep.db)
+	if err == nil || !isConnectionError(err) || ctx.Err() != nil {
+		return err
+	}
+
+	rep.healthy.Store(false)
+	util.Logger(ctx).WithFields(log.Fields{"replica": rep.name, "error": err}).Warn("replica cannot be reached, reading from the primary")
+	return query(r.primary)
+}
+
+func (r *RoutingDB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return r.read(ctx, func(db util.Crudable) error { return db.GetContext(ctx, dest, query, args...) })
+}
+
+func (r *RoutingDB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return r.read(ctx, func(db util.Crudable) error { return db.SelectContext(ctx, dest, query, args...) })
+}
+
+func (r *RoutingDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return r.primary.ExecContext(ctx, query, args...)
+}
+
+// QueryRowContext goes to the primary because it is used for statements such as INSERT ... RETURNING.
+func (r *RoutingDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return r.primary.QueryRowContext(ctx, query, args...)
+}
+
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	var connectErr *pgconn.ConnectError
+	return errors.Is(err, driver.ErrBadConn) || errors.As(err, &netErr) || errors.As(err, &connectErr)
+}
@@ -0,0 +1,155 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeRoutedDB struct {
+	name    string
+	calls   *[]string
+	err     error
+	pingErr error
+}
+
+func (f fakeRoutedDB) record(method string) {
+	*f.calls = append(*f.calls, f.name+"."+method)
+}
+
+func (f fakeRoutedDB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	f.record("get")
+	return f.err
+}
+
+func (f fakeRoutedDB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	f.record("select")
+	return f.err
+}
+
+func (f fakeRoutedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	f.record("exec")
+	return nil, f.err
+}
+
+func (f fakeRoutedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	f.record("query_row")
+	return nil
+}
+
+func (f fakeRoutedDB) PingContext(ctx context.Context) error {
+	return f.pingErr
+}
+
+func TestRoutingDB(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		replicas  []fakeRoutedDB
+		run       func(r *RoutingDB)
+		wantCalls []string
+	}{
+		{
+			name:      "no replica reads from primary",
+			run:       func(r *RoutingDB) { _ = r.SelectContext(ctx, nil, "") },
+			wantCalls: []string{"primary.select"},
+		},
+		{
+			name:     "reads go round robin over replicas",
+			replicas: []fakeRoutedDB{{name: "r0"}, {name: "r1"}},
+			run: func(r *RoutingDB) {
+				_ = r.SelectContext(ctx, nil, "")
+				_ = r.GetContext(ctx, nil, "")
+				_ = r.SelectContext(ctx, nil, "")
+			},
+			wantCalls: []string{"r1.select", "r0.get", "r1.select"},
+		},
+		{
+			name:     "writes go to primary",
+			replicas: []fakeRoutedDB{{name: "r0"}},
+			run: func(r *RoutingDB) {
+				_, _ = r.ExecContext(ctx, "")
+				_ = r.QueryRowContext(ctx, "")
+			},
+			wantCalls: []string{"primary.exec", "primary.query_row"},
+		},
+		{
+			name:     "unhealthy replica is skipped",
+			replicas: []fakeRoutedDB{{name: "r0", pingErr: errors.New("down")}, {name: "r1"}},
+			run: func(r *RoutingDB) {
+				r.CheckHealth(ctx)
+				_ = r.SelectContext(ctx, nil, "")
+				_ = r.SelectContext(ctx, nil, "")
+			},
+			wantCalls: []string{"r1.select", "r1.select"},
+		},
+		{
+			name:     "every replica unhealthy falls back to primary",
+			replicas: []fakeRoutedDB{{name: "r0", pingErr: errors.New("down")}},
+			run: func(r *RoutingDB) {
+				r.CheckHealth(ctx)
+				_ = r.SelectContext(ctx, nil, "")
+			},
+			wantCalls: []string{"primary.select"},
+		},
+		{
+			name:     "connection error retries on primary and marks replica unhealthy",
+			replicas: []fakeRoutedDB{{name: "r0", err: fmt.Errorf("query failed: %w", driver.ErrBadConn)}},
+			run: func(r *RoutingDB) {
+				_ = r.SelectContext(ctx, nil, "")
+				_ = r.SelectContext(ctx, nil, "")
+			},
+			wantCalls: []string{"r0.select", "primary.select", "primary.select"},
+		},
+		{
+			name:     "query error is returned without retry",
+			replicas: []fakeRoutedDB{{name: "r0", err: sql.ErrNoRows}},
+			run: func(r *RoutingDB) {
+				_ = r.GetContext(ctx, nil, "")
+			},
+			wantCalls: []string{"r0.get"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			replicas := make([]pingableDB, len(tt.replicas))
+			for i, rep := range tt.replicas {
+				rep.calls = &calls
+				replicas[i] = rep
+			}
+			r := newRoutingDB(fakeRoutedDB{name: "primary", calls: &calls}, replicas, time.Minute)
+
+			tt.run(r)
+
+			if diff := cmp.Diff(tt.wantCalls, calls); diff != "" {
+				t.Errorf("unexpected calls (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRoutingDB_CheckHealthRecovers(t *testing.T) {
+	var calls []string
+	rep := &fakeRoutedDB{name: "r0", calls: &calls, pingErr: errors.New("down")}
+	r := newRoutingDB(fakeRoutedDB{name: "primary", calls: &calls}, []pingableDB{rep}, time.Minute)
+
+	r.CheckHealth(context.Background())
+	_ = r.SelectContext(context.Background(), nil, "")
+
+	rep.pingErr = nil
+	r.CheckHealth(context.Background())
+	_ = r.SelectContext(context.Background(), nil, "")
+
+	if diff := cmp.Diff([]string{"primary.select", "r0.select"}, calls); diff != "" {
+		t.Errorf("unexpected calls (-want +got):\n%s", diff)
+	}
+}
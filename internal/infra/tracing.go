@@ -0,0 +1,46 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/shigaichi/top-sites-ranking-api/internal/infra")
+
+// startQuerySpan starts a span for the database query name.
+func startQuerySpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.query.name", name),
+		),
+	)
+}
+
+// endQuerySpan records the number of rows the query returned or affected and its error, then ends span.
+func endQuerySpan(span trace.Span, rows int, err error) {
+	span.SetAttributes(attribute.Int("db.rows", rows))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// rowsAffected returns the affected rows of res, or 0 when the driver does not report them.
+func rowsAffected(res sql.Result) int {
+	if res == nil {
+		return 0
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0
+	}
+	return int(n)
+}
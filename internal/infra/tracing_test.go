@@ -0,0 +1,77 @@
+package infra
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var (
+	exporter     = tracetest.NewInMemoryExporter()
+	setupTracing sync.Once
+)
+
+// recordSpans installs an in-memory exporter as the global tracer provider and clears recorded spans.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	setupTracing.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	})
+	exporter.Reset()
+	return exporter
+}
+
+func attributeOf(attrs []attribute.KeyValue, key attribute.Key) (attribute.Value, bool) {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestTrancoDailyRankRepositoryImpl_GetDailyRanksByDateRange_Span(t *testing.T) {
+	tests := []struct {
+		name       string
+		db         MockDailyRankDB
+		wantRows   int64
+		wantStatus codes.Code
+	}{
+		{name: "rows are recorded", db: MockDailyRankDB{}, wantRows: 1, wantStatus: codes.Unset},
+		{name: "error is recorded", db: MockDailyRankDB{shouldError: true}, wantRows: 0, wantStatus: codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp := recordSpans(t)
+
+			repo := TrancoDailyRankRepositoryImpl{db: tt.db}
+			_, _ = repo.GetDailyRanksByDateRange(context.Background(), "example.com", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))
+
+			spans := exp.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+			span := spans[0]
+			if span.Name != "tranco_daily_rank.get_by_date_range" {
+				t.Errorf("unexpected span name %s", span.Name)
+			}
+			if v, _ := attributeOf(span.Attributes, "db.query.name"); v.AsString() != "tranco_daily_rank.get_by_date_range" {
+				t.Errorf("unexpected db.query.name %q", v.AsString())
+			}
+			if v, ok := attributeOf(span.Attributes, "db.rows"); !ok || v.AsInt64() != tt.wantRows {
+				t.Errorf("expected db.rows %d, got %v", tt.wantRows, v.AsInt64())
+			}
+			if span.Status.Code != tt.wantStatus {
+				t.Errorf("expected status %v, got %v", tt.wantStatus, span.Status.Code)
+			}
+		})
+	}
+}
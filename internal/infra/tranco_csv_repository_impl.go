@@ -1,12 +1,19 @@
 package infra
 
 import (
+	"bytes"
+	"context"
 	"encoding/csv"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
 )
 
@@ -17,26 +24,68 @@ func NewTrancoCsvImpl() *TrancoCsvImpl {
 	return &TrancoCsvImpl{}
 }
 
-func (t TrancoCsvImpl) Get(url url.URL) ([]model.SiteRanking, error) {
-	resp, err := http.Get(url.String())
+func (t TrancoCsvImpl) Get(ctx context.Context, url url.URL) ([]model.SiteRanking, error) {
+	body, err := t.download(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.parse(ctx, body)
+}
+
+// download fetches the whole csv so that the download and parse stages are traced separately.
+func (t TrancoCsvImpl) download(ctx context.Context, url url.URL) (body []byte, err error) {
+	ctx, span := tracer.Start(ctx, "tranco_csv.download", trace.WithAttributes(attribute.String("url.full", url.String())))
+	defer func() {
+		span.SetAttributes(attribute.Int("http.response.body.size", len(body)))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request to %s. error: %w", url.String(), err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download csv from %s. error: %w", url.String(), err)
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
 		return nil, fmt.Errorf("failed to download csv from %s. response status: %d", url.String(), resp.StatusCode)
 	}
 
-	defer resp.Body.Close()
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv from %s. error: %w", url.String(), err)
+	}
+
+	return body, nil
+}
+
+func (t TrancoCsvImpl) parse(ctx context.Context, body []byte) (rankings []model.SiteRanking, err error) {
+	_, span := tracer.Start(ctx, "tranco_csv.parse")
+	defer func() {
+		span.SetAttributes(attribute.Int("rows", len(rankings)))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
 
-	r := csv.NewReader(resp.Body)
+	r := csv.NewReader(bytes.NewReader(body))
 
 	records, err := r.ReadAll()
 	if err != nil {
 		return nil, fmt.Errorf("error while parsing CSV: %w", err)
 	}
 
-	var rankings []model.SiteRanking
 	for _, record := range records {
 		rank, err := strconv.Atoi(record[0])
 		if err != nil {
@@ -1,6 +1,7 @@
 package infra
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -53,7 +54,7 @@ func TestTrancoCsvImpl_Get(t *testing.T) {
 			}
 
 			cli := TrancoCsvImpl{}
-			result, err := cli.Get(*p)
+			result, err := cli.Get(context.Background(), *p)
 
 			if err != nil {
 				if !tt.wantErr {
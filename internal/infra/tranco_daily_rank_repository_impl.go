@@ -27,7 +27,7 @@ func (t TrancoDailyRankRepositoryImpl) GetDailyRanksByDateRange(ctx context.Cont
 	var ranks []model.DailyRank
 
 	query := `
-SELECT tr.ranking AS Rank, tl.created_on AS Date
+SELECT tr.ranking AS Rank, tl.created_on AS Date, tl.id AS ListID
 FROM tranco_rankings tr
          INNER JOIN tranco_domains td ON tr.domain_id = td.id
          INNER JOIN public.tranco_lists tl ON tr.list_id = tl.id
@@ -38,9 +38,71 @@ WHERE td.domain = $1
 
 	args := []interface{}{domain, start, end.Add(time.Hour * 24)}
 
-	if err := dao.SelectContext(ctx, &ranks, query, args...); err != nil {
+	ctx, span := startQuerySpan(ctx, "tranco_daily_rank.get_by_date_range")
+	err := dao.SelectContext(ctx, &ranks, query, args...)
+	endQuerySpan(span, len(ranks), err)
+	if err != nil {
 		return nil, fmt.Errorf("failed to fetch daily ranks: %w", err)
 	}
 
 	return ranks, nil
 }
+
+func (t TrancoDailyRankRepositoryImpl) GetRegistrableDailyRanksByDateRange(ctx context.Context, registrable string, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	var ranks []model.DailyRank
+
+	query := `
+SELECT MIN(tr.ranking) AS Rank, tl.created_on AS Date, tl.id AS ListID
+FROM tranco_rankings tr
+         INNER JOIN tranco_domains td ON tr.domain_id = td.id
+         INNER JOIN public.tranco_lists tl ON tr.list_id = tl.id
+WHERE td.registrable = $1
+  AND tl.created_on BETWEEN $2 AND $3
+GROUP BY tl.id, tl.created_on
+  ORDER BY Date DESC
+`
+
+	args := []interface{}{registrable, start, end.Add(time.Hour * 24)}
+
+	ctx, span := startQuerySpan(ctx, "tranco_daily_rank.get_registrable_by_date_range")
+	err := dao.SelectContext(ctx, &ranks, query, args...)
+	endQuerySpan(span, len(ranks), err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch daily ranks of registrable domain %s: %w", registrable, err)
+	}
+
+	return ranks, nil
+}
+
+func (t TrancoDailyRankRepositoryImpl) GetRanksByDomains(ctx context.Context, listID string, domains []string) ([]model.SiteRanking, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	var ranks []model.SiteRanking
+
+	query := `
+SELECT td.domain AS Domain, tr.ranking AS Rank
+FROM tranco_domains td
+         INNER JOIN tranco_rankings tr ON tr.domain_id = td.id
+WHERE tr.list_id = $1
+  AND td.domain = ANY ($2)
+`
+
+	ctx, span := startQuerySpan(ctx, "tranco_daily_rank.get_by_domains")
+	err := dao.SelectContext(ctx, &ranks, query, listID, domains)
+	endQuerySpan(span, len(ranks), err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ranks of %d domains in list %s: %w", len(domains), listID, err)
+	}
+
+	return ranks, nil
+}
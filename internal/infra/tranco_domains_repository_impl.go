@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
 	"github.com/shigaichi/top-sites-ranking-api/internal/util"
 )
 
@@ -26,17 +27,21 @@ func (t TrancoDomainRepositoryImpl) GetIDByDomain(ctx context.Context, domain st
 
 	var id int
 	query := `SELECT id FROM tranco_domains WHERE DOMAIN = $1`
+	ctx, span := startQuerySpan(ctx, "tranco_domains.get_id_by_domain")
 	err := dao.GetContext(ctx, &id, query, domain)
 	if err != nil {
 		if err == sql.ErrNoRows {
+			endQuerySpan(span, 0, nil)
 			return 0, nil
 		}
+		endQuerySpan(span, 0, err)
 		return 0, fmt.Errorf("error retrieving id for domain %s: %w", domain, err)
 	}
+	endQuerySpan(span, 1, nil)
 	return id, nil
 }
 
-func (t TrancoDomainRepositoryImpl) Save(ctx context.Context, domain string) (int, error) {
+func (t TrancoDomainRepositoryImpl) Save(ctx context.Context, name model.DomainName) (int, error) {
 	var dao util.Crudable
 	dao, ok := GetTx(ctx)
 	if !ok {
@@ -44,10 +49,61 @@ func (t TrancoDomainRepositoryImpl) Save(ctx context.Context, domain string) (in
 	}
 
 	var id int
-	query := `INSERT INTO tranco_domains (domain) VALUES ($1) RETURNING id`
-	err := dao.QueryRowContext(ctx, query, domain).Scan(&id)
+	query := `INSERT INTO tranco_domains (domain, registrable, tld) VALUES ($1, $2, $3) RETURNING id`
+	ctx, span := startQuerySpan(ctx, "tranco_domains.save")
+	err := dao.QueryRowContext(ctx, query, name.Domain, name.Registrable, name.TLD).Scan(&id)
+	endQuerySpan(span, 1, err)
 	if err != nil {
-		return 0, fmt.Errorf("error saving domain %s: %w", domain, err)
+		return 0, fmt.Errorf("error saving domain %s: %w", name.Domain, err)
 	}
 	return id, nil
 }
+
+func (t TrancoDomainRepositoryImpl) FindUnparsed(ctx context.Context, limit int) ([]string, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	var domains []string
+	query := `SELECT domain FROM tranco_domains WHERE registrable IS NULL ORDER BY id LIMIT $1`
+	ctx, span := startQuerySpan(ctx, "tranco_domains.find_unparsed")
+	err := dao.SelectContext(ctx, &domains, query, limit)
+	endQuerySpan(span, len(domains), err)
+	if err != nil {
+		return nil, fmt.Errorf("error finding domains without registrable domain: %w", err)
+	}
+	return domains, nil
+}
+
+func (t TrancoDomainRepositoryImpl) UpdateNames(ctx context.Context, names []model.DomainName) (int, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	domains := make([]string, len(names))
+	registrables := make([]string, len(names))
+	tlds := make([]string, len(names))
+	for i, name := range names {
+		domains[i], registrables[i], tlds[i] = name.Domain, name.Registrable, name.TLD
+	}
+
+	query := `
+UPDATE tranco_domains td
+SET registrable = n.registrable,
+    tld         = n.tld
+FROM unnest($1::TEXT[], $2::TEXT[], $3::TEXT[]) AS n(domain, registrable, tld)
+WHERE td.domain = n.domain
+`
+	ctx, span := startQuerySpan(ctx, "tranco_domains.update_names")
+	res, err := dao.ExecContext(ctx, query, domains, registrables, tlds)
+	affected := rowsAffected(res)
+	endQuerySpan(span, affected, err)
+	if err != nil {
+		return 0, fmt.Errorf("error updating names of %d domains: %w", len(names), err)
+	}
+	return affected, nil
+}
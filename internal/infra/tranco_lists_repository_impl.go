@@ -2,10 +2,11 @@ package infra
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
-	"github.com/jmoiron/sqlx"
 	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
 	"github.com/shigaichi/top-sites-ranking-api/internal/util"
 )
@@ -14,7 +15,7 @@ type TrancoListRepositoryImpl struct {
 	db util.Crudable
 }
 
-func NewTrancoListRepositoryImpl(db *sqlx.DB) *TrancoListRepositoryImpl {
+func NewTrancoListRepositoryImpl(db util.Crudable) *TrancoListRepositoryImpl {
 	return &TrancoListRepositoryImpl{db: db}
 }
 
@@ -27,7 +28,9 @@ func (t TrancoListRepositoryImpl) ExistsID(ctx context.Context, id string) (bool
 
 	var count int
 	query := `SELECT COUNT(id) FROM tranco_lists WHERE id = $1`
+	ctx, span := startQuerySpan(ctx, "tranco_lists.exists_id")
 	err := dao.GetContext(ctx, &count, query, id)
+	endQuerySpan(span, count, err)
 	if err != nil {
 		return false, fmt.Errorf("failed to check existence of TrancoList with ID %s: %w", id, err)
 	}
@@ -42,13 +45,32 @@ func (t TrancoListRepositoryImpl) Save(ctx context.Context, list model.TrancoLis
 	}
 
 	query := `INSERT INTO tranco_lists (id, created_on) VALUES ($1, $2)`
-	_, err := dao.ExecContext(ctx, query, list.ID, list.CreatedOn)
+	ctx, span := startQuerySpan(ctx, "tranco_lists.save")
+	res, err := dao.ExecContext(ctx, query, list.ID, list.CreatedOn)
+	endQuerySpan(span, rowsAffected(res), err)
 	if err != nil {
 		return fmt.Errorf("failed to save TrancoList with ID %s: %w", list.ID, err)
 	}
 	return nil
 }
 
+func (t TrancoListRepositoryImpl) SetRowCount(ctx context.Context, id string, rows int) error {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	query := `UPDATE tranco_lists SET row_count = $2 WHERE id = $1`
+	ctx, span := startQuerySpan(ctx, "tranco_lists.set_row_count")
+	res, err := dao.ExecContext(ctx, query, id, rows)
+	endQuerySpan(span, rowsAffected(res), err)
+	if err != nil {
+		return fmt.Errorf("failed to set row count of TrancoList with ID %s: %w", id, err)
+	}
+	return nil
+}
+
 func (t TrancoListRepositoryImpl) FindByCreatedOnLessThan(ctx context.Context, date time.Time) ([]model.TrancoList, error) {
 	var dao util.Crudable
 	dao, ok := GetTx(ctx)
@@ -58,7 +80,9 @@ func (t TrancoListRepositoryImpl) FindByCreatedOnLessThan(ctx context.Context, d
 
 	var lists []model.TrancoList
 	query := "SELECT id, created_on FROM tranco_lists WHERE created_on < $1"
+	ctx, span := startQuerySpan(ctx, "tranco_lists.find_by_created_on_less_than")
 	err := dao.SelectContext(ctx, &lists, query, date)
+	endQuerySpan(span, len(lists), err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find by created on less than %s: %w", date, err)
 	}
@@ -66,6 +90,26 @@ func (t TrancoListRepositoryImpl) FindByCreatedOnLessThan(ctx context.Context, d
 	return lists, nil
 }
 
+// FindByCreatedOnBetween returns the lists created from start to the end of the end date, ordered by created_on.
+func (t TrancoListRepositoryImpl) FindByCreatedOnBetween(ctx context.Context, start time.Time, end time.Time) ([]model.TrancoList, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	var lists []model.TrancoList
+	query := "SELECT id, created_on FROM tranco_lists WHERE created_on >= $1 AND created_on < $2 ORDER BY created_on"
+	ctx, span := startQuerySpan(ctx, "tranco_lists.find_by_created_on_between")
+	err := dao.SelectContext(ctx, &lists, query, start, end.Add(time.Hour*24))
+	endQuerySpan(span, len(lists), err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find by created on between %s and %s: %w", start, end, err)
+	}
+
+	return lists, nil
+}
+
 func (t TrancoListRepositoryImpl) DeleteByID(ctx context.Context, id string) error {
 	var dao util.Crudable
 	dao, ok := GetTx(ctx)
@@ -74,9 +118,96 @@ func (t TrancoListRepositoryImpl) DeleteByID(ctx context.Context, id string) err
 	}
 
 	query := "DELETE FROM tranco_lists WHERE id = $1"
-	_, err := dao.ExecContext(ctx, query, id)
+	ctx, span := startQuerySpan(ctx, "tranco_lists.delete_by_id")
+	res, err := dao.ExecContext(ctx, query, id)
+	endQuerySpan(span, rowsAffected(res), err)
 	if err != nil {
 		return fmt.Errorf("failed to delete by id %s: %w", id, err)
 	}
 	return nil
 }
+
+func (t TrancoListRepositoryImpl) FindLatest(ctx context.Context) (model.TrancoList, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	var list model.TrancoList
+	query := `SELECT id, created_on FROM tranco_lists ORDER BY created_on DESC LIMIT 1`
+	ctx, span := startQuerySpan(ctx, "tranco_lists.find_latest")
+	err := dao.GetContext(ctx, &list, query)
+	if errors.Is(err, sql.ErrNoRows) {
+		endQuerySpan(span, 0, nil)
+		return model.TrancoList{}, nil
+	}
+	endQuerySpan(span, 1, err)
+	if err != nil {
+		return model.TrancoList{}, fmt.Errorf("failed to find latest TrancoList: %w", err)
+	}
+	return list, nil
+}
+
+func (t TrancoListRepositoryImpl) FindVersion(ctx context.Context) (string, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	var version string
+	query := `SELECT COALESCE(md5(string_agg(id, ',' ORDER BY id)), '') FROM tranco_lists`
+	ctx, span := startQuerySpan(ctx, "tranco_lists.find_version")
+	err := dao.GetContext(ctx, &version, query)
+	endQuerySpan(span, 1, err)
+	if err != nil {
+		return "", fmt.Errorf("failed to find version of TrancoLists: %w", err)
+	}
+	return version, nil
+}
+
+// listSummaryColumns selects a list with the number of its rankings, which the writer stores with the list.
+// Only a list saved by an older writer after the schema was migrated lacks it, and is counted on the list_id index.
+const listSummaryColumns = `tl.id, tl.created_on, COALESCE(tl.row_count, (SELECT COUNT(*) FROM tranco_rankings tr WHERE tr.list_id = tl.id)) AS row_count`
+
+func (t TrancoListRepositoryImpl) FindSummariesByCreatedOnBetween(ctx context.Context, start time.Time, end time.Time) ([]model.TrancoListSummary, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	var lists []model.TrancoListSummary
+	query := "SELECT " + listSummaryColumns + " FROM tranco_lists tl WHERE tl.created_on >= $1 AND tl.created_on < $2 ORDER BY tl.created_on"
+	ctx, span := startQuerySpan(ctx, "tranco_lists.find_summaries_by_created_on_between")
+	err := dao.SelectContext(ctx, &lists, query, start, end.Add(time.Hour*24))
+	endQuerySpan(span, len(lists), err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find summaries by created on between %s and %s: %w", start, end, err)
+	}
+
+	return lists, nil
+}
+
+func (t TrancoListRepositoryImpl) FindLatestSummary(ctx context.Context) (model.TrancoListSummary, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	var list model.TrancoListSummary
+	query := "SELECT " + listSummaryColumns + " FROM tranco_lists tl ORDER BY tl.created_on DESC LIMIT 1"
+	ctx, span := startQuerySpan(ctx, "tranco_lists.find_latest_summary")
+	err := dao.GetContext(ctx, &list, query)
+	if errors.Is(err, sql.ErrNoRows) {
+		endQuerySpan(span, 0, nil)
+		return model.TrancoListSummary{}, nil
+	}
+	endQuerySpan(span, 1, err)
+	if err != nil {
+		return model.TrancoListSummary{}, fmt.Errorf("failed to find latest TrancoList summary: %w", err)
+	}
+	return list, nil
+}
@@ -90,6 +90,130 @@ func TestTrancoListRepositoryImpl_FindByCreatedOnLessThan(t1 *testing.T) {
 	}
 }
 
+func TestTrancoListRepositoryImpl_FindByCreatedOnBetween(t1 *testing.T) {
+	start := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2023, 10, 7, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		setupMock func(db *MockListDB)
+		want      []model.TrancoList
+		wantErr   bool
+	}{
+		{
+			name: "Valid range with no errors",
+			setupMock: func(db *MockListDB) {
+				db.MockSelectContext = func(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+					params := args[0].([]interface{})
+					if !params[0].(time.Time).Equal(start) || !params[1].(time.Time).Equal(end.Add(24*time.Hour)) {
+						return errors.New("unexpected parameters")
+					}
+					lists := dest.(*[]model.TrancoList)
+					*lists = []model.TrancoList{
+						{ID: "1", CreatedOn: time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)},
+						{ID: "2", CreatedOn: time.Date(2023, 10, 7, 0, 0, 0, 0, time.UTC)},
+					}
+					return nil
+				}
+			},
+			want: []model.TrancoList{
+				{ID: "1", CreatedOn: time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)},
+				{ID: "2", CreatedOn: time.Date(2023, 10, 7, 0, 0, 0, 0, time.UTC)},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Valid range with error",
+			setupMock: func(db *MockListDB) {
+				db.MockSelectContext = func(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+					return errors.New("mock error")
+				}
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			db := MockListDB{}
+			tt.setupMock(&db)
+			t := TrancoListRepositoryImpl{
+				db: &db,
+			}
+			got, err := t.FindByCreatedOnBetween(context.Background(), start, end)
+			if (err != nil) != tt.wantErr {
+				t1.Errorf("FindByCreatedOnBetween() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t1.Errorf("result is mimatch:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTrancoListRepositoryImpl_FindSummariesByCreatedOnBetween(t1 *testing.T) {
+	start := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2023, 10, 7, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		setupMock func(db *MockListDB)
+		want      []model.TrancoListSummary
+		wantErr   bool
+	}{
+		{
+			name: "Valid range with no errors",
+			setupMock: func(db *MockListDB) {
+				db.MockSelectContext = func(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+					params := args[0].([]interface{})
+					if !params[0].(time.Time).Equal(start) || !params[1].(time.Time).Equal(end.Add(24*time.Hour)) {
+						return errors.New("unexpected parameters")
+					}
+					lists := dest.(*[]model.TrancoListSummary)
+					*lists = []model.TrancoListSummary{
+						{TrancoList: model.TrancoList{ID: "1", CreatedOn: time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)}, Rows: 1000000},
+					}
+					return nil
+				}
+			},
+			want: []model.TrancoListSummary{
+				{TrancoList: model.TrancoList{ID: "1", CreatedOn: time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)}, Rows: 1000000},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Valid range with error",
+			setupMock: func(db *MockListDB) {
+				db.MockSelectContext = func(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+					return errors.New("mock error")
+				}
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			db := MockListDB{}
+			tt.setupMock(&db)
+			t := TrancoListRepositoryImpl{
+				db: &db,
+			}
+			got, err := t.FindSummariesByCreatedOnBetween(context.Background(), start, end)
+			if (err != nil) != tt.wantErr {
+				t1.Errorf("FindSummariesByCreatedOnBetween() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t1.Errorf("result is mimatch:\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestTrancoListRepositoryImpl_DeleteById(t1 *testing.T) {
 	type args struct {
 		ctx context.Context
@@ -0,0 +1,150 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+)
+
+type TrancoMonthlyRankRepositoryImpl struct {
+	db util.Crudable
+}
+
+func NewTrancoMonthlyRankRepositoryImpl(db util.Crudable) *TrancoMonthlyRankRepositoryImpl {
+	return &TrancoMonthlyRankRepositoryImpl{db: db}
+}
+
+// Refresh upserts the rankings, so a list that is ingested again replaces the ranks of its month end.
+func (t TrancoMonthlyRankRepositoryImpl) Refresh(ctx context.Context, listID string) error {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	query := `
+INSERT INTO tranco_monthly_rankings (domain_id, created_on, list_id, ranking)
+SELECT tr.domain_id, tl.created_on, tr.list_id, tr.ranking
+FROM tranco_rankings tr
+         INNER JOIN tranco_lists tl ON tr.list_id = tl.id
+WHERE tr.list_id = $1
+ON CONFLICT (domain_id, created_on) DO UPDATE SET list_id = EXCLUDED.list_id, ranking = EXCLUDED.ranking
+`
+
+	ctx, span := startQuerySpan(ctx, "tranco_monthly_rankings.refresh")
+	res, err := dao.ExecContext(ctx, query, listID)
+	endQuerySpan(span, rowsAffected(res), err)
+	if err != nil {
+		return fmt.Errorf("failed to refresh monthly rankings of list %s: %w", listID, err)
+	}
+	return nil
+}
+
+// RefreshMissing is the same copy as the backfill in init/db-init.sql.
+func (t TrancoMonthlyRankRepositoryImpl) RefreshMissing(ctx context.Context) (int, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	query := `
+INSERT INTO tranco_monthly_rankings (domain_id, created_on, list_id, ranking)
+SELECT tr.domain_id, tl.created_on, tr.list_id, tr.ranking
+FROM tranco_rankings tr
+         INNER JOIN tranco_lists tl ON tr.list_id = tl.id
+WHERE tl.created_on::date = (date_trunc('month', tl.created_on) + INTERVAL '1 month' - INTERVAL '1 day')::date
+  AND NOT EXISTS (SELECT 1 FROM tranco_monthly_rankings tm WHERE tm.list_id = tl.id)
+ON CONFLICT (domain_id, created_on) DO UPDATE SET list_id = EXCLUDED.list_id, ranking = EXCLUDED.ranking
+`
+
+	ctx, span := startQuerySpan(ctx, "tranco_monthly_rankings.refresh_missing")
+	res, err := dao.ExecContext(ctx, query)
+	rows := rowsAffected(res)
+	endQuerySpan(span, rows, err)
+	if err != nil {
+		return 0, fmt.Errorf("failed to refresh missing monthly rankings: %w", err)
+	}
+	return rows, nil
+}
+
+func (t TrancoMonthlyRankRepositoryImpl) DeleteByListID(ctx context.Context, listID string) error {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	query := `DELETE FROM tranco_monthly_rankings WHERE list_id = $1`
+	ctx, span := startQuerySpan(ctx, "tranco_monthly_rankings.delete_by_list_id")
+	res, err := dao.ExecContext(ctx, query, listID)
+	endQuerySpan(span, rowsAffected(res), err)
+	if err != nil {
+		return fmt.Errorf("failed to delete monthly rankings of list %s: %w", listID, err)
+	}
+	return nil
+}
+
+func (t TrancoMonthlyRankRepositoryImpl) GetMonthlyRanksByDateRange(ctx context.Context, domain string, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	var ranks []model.DailyRank
+
+	query := `
+SELECT tm.ranking AS Rank, tm.created_on AS Date, tm.list_id AS ListID
+FROM tranco_monthly_rankings tm
+         INNER JOIN tranco_domains td ON tm.domain_id = td.id
+WHERE td.domain = $1
+  AND tm.created_on BETWEEN $2 AND $3
+  ORDER BY Date DESC
+`
+
+	args := []interface{}{domain, start, end.Add(time.Hour * 24)}
+
+	ctx, span := startQuerySpan(ctx, "tranco_monthly_rankings.get_by_date_range")
+	err := dao.SelectContext(ctx, &ranks, query, args...)
+	endQuerySpan(span, len(ranks), err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch monthly ranks: %w", err)
+	}
+
+	return ranks, nil
+}
+
+func (t TrancoMonthlyRankRepositoryImpl) GetRegistrableMonthlyRanksByDateRange(ctx context.Context, registrable string, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	var ranks []model.DailyRank
+
+	query := `
+SELECT MIN(tm.ranking) AS Rank, tm.created_on AS Date, tm.list_id AS ListID
+FROM tranco_monthly_rankings tm
+         INNER JOIN tranco_domains td ON tm.domain_id = td.id
+WHERE td.registrable = $1
+  AND tm.created_on BETWEEN $2 AND $3
+GROUP BY tm.created_on, tm.list_id
+  ORDER BY Date DESC
+`
+
+	args := []interface{}{registrable, start, end.Add(time.Hour * 24)}
+
+	ctx, span := startQuerySpan(ctx, "tranco_monthly_rankings.get_registrable_by_date_range")
+	err := dao.SelectContext(ctx, &ranks, query, args...)
+	endQuerySpan(span, len(ranks), err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch monthly ranks of registrable domain %s: %w", registrable, err)
+	}
+
+	return ranks, nil
+}
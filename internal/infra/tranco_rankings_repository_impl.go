@@ -29,7 +29,9 @@ func (t TrancoRankingsRepositoryImpl) Save(ctx context.Context, ranking model.Tr
 
 	query := `INSERT INTO tranco_rankings (domain_id, list_id, ranking) VALUES ($1, $2, $3)`
 
-	_, err := dao.ExecContext(ctx, query, ranking.DomainID, ranking.ListID, ranking.Ranking)
+	ctx, span := startQuerySpan(ctx, "tranco_rankings.save")
+	res, err := dao.ExecContext(ctx, query, ranking.DomainID, ranking.ListID, ranking.Ranking)
+	endQuerySpan(span, rowsAffected(res), err)
 	if err != nil {
 		return fmt.Errorf("error saving tranco ranking: %w", err)
 	}
@@ -65,9 +67,11 @@ func (t TrancoRankingsRepositoryImpl) executeBatch(ctx context.Context, rankings
 
 	query := fmt.Sprintf("INSERT INTO tranco_rankings (domain_id, list_id, ranking) VALUES %s", strings.Join(valueStrings, ","))
 
-	log.WithFields(log.Fields{"rank_count": len(rankings)}).Debug("rank data saved")
+	util.Logger(ctx).WithFields(log.Fields{"rank_count": len(rankings)}).Debug("rank data saved")
 
-	_, err := dao.ExecContext(ctx, query, valueArgs...)
+	ctx, span := startQuerySpan(ctx, "tranco_rankings.bulk_save_batch")
+	res, err := dao.ExecContext(ctx, query, valueArgs...)
+	endQuerySpan(span, rowsAffected(res), err)
 	if err != nil {
 		return fmt.Errorf("error executing batch insert: %w", err)
 	}
@@ -83,9 +87,38 @@ func (t TrancoRankingsRepositoryImpl) DeleteByListID(ctx context.Context, listID
 
 	query := `DELETE FROM tranco_rankings WHERE list_id = $1`
 
-	_, err := dao.ExecContext(ctx, query, listID)
+	ctx, span := startQuerySpan(ctx, "tranco_rankings.delete_by_list_id")
+	res, err := dao.ExecContext(ctx, query, listID)
+	endQuerySpan(span, rowsAffected(res), err)
 	if err != nil {
 		return fmt.Errorf("error delete tranco ranking by list id(%s)  : %w", listID, err)
 	}
 	return nil
 }
+
+// FindTopByListID returns the best ranked limit domains of a list, ordered by rank.
+func (t TrancoRankingsRepositoryImpl) FindTopByListID(ctx context.Context, listID string, limit int) ([]model.SiteRanking, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	query := `
+SELECT tr.ranking AS Rank, td.domain AS Domain
+FROM tranco_rankings tr
+         INNER JOIN tranco_domains td ON tr.domain_id = td.id
+WHERE tr.list_id = $1
+ORDER BY tr.ranking
+LIMIT $2
+`
+
+	var rankings []model.SiteRanking
+	ctx, span := startQuerySpan(ctx, "tranco_rankings.find_top_by_list_id")
+	err := dao.SelectContext(ctx, &rankings, query, listID, limit)
+	endQuerySpan(span, len(rankings), err)
+	if err != nil {
+		return nil, fmt.Errorf("error finding top %d rankings by list id(%s): %w", limit, listID, err)
+	}
+	return rankings, nil
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
 )
 
@@ -14,7 +15,13 @@ type MockRankingDB struct {
 }
 
 func (m MockRankingDB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
-	panic("no implementation")
+	if m.shouldError {
+		return errors.New("mock error")
+	}
+
+	rankings := dest.(*[]model.SiteRanking)
+	*rankings = []model.SiteRanking{{Rank: 1, Domain: "google.com"}, {Rank: 2, Domain: "amazonaws.com"}}
+	return nil
 }
 
 func (m MockRankingDB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
@@ -108,3 +115,34 @@ func TestTrancoRankingsRepositoryImpl_DeleteByListID(t *testing.T) {
 		})
 	}
 }
+
+func TestTrancoRankingsRepositoryImpl_FindTopByListID(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    []model.SiteRanking
+		wantErr bool
+	}{
+		{
+			name:    "successful find",
+			want:    []model.SiteRanking{{Rank: 1, Domain: "google.com"}, {Rank: 2, Domain: "amazonaws.com"}},
+			wantErr: false,
+		},
+		{
+			name:    "failed find due to DB error",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewTrancoRankingsRepositoryImpl(2, &MockRankingDB{shouldError: tt.wantErr})
+			got, err := r.FindTopByListID(context.Background(), "list1", 2)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FindTopByListID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("result is mismatch:\n%s", diff)
+			}
+		})
+	}
+}
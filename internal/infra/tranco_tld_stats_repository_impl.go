@@ -0,0 +1,123 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+)
+
+type TrancoTLDStatsRepositoryImpl struct {
+	db util.Crudable
+}
+
+func NewTrancoTLDStatsRepositoryImpl(db util.Crudable) *TrancoTLDStatsRepositoryImpl {
+	return &TrancoTLDStatsRepositoryImpl{db: db}
+}
+
+// Refresh upserts the counts, which is enough to make it idempotent since the rankings of a list never change.
+// The TLD of domains saved before it was stored is taken from the domain itself.
+func (t TrancoTLDStatsRepositoryImpl) Refresh(ctx context.Context, listID string, tops []int) error {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	query := `
+INSERT INTO tranco_tld_stats (list_id, top_n, tld, domains)
+SELECT tr.list_id, t.top_n, COALESCE(td.tld, substring(td.domain FROM '[^.]*$')) AS tld, COUNT(*)
+FROM tranco_rankings tr
+         INNER JOIN tranco_domains td ON tr.domain_id = td.id
+         INNER JOIN unnest($2::INT[]) AS t(top_n) ON tr.ranking <= t.top_n
+WHERE tr.list_id = $1
+GROUP BY 1, 2, 3
+ON CONFLICT (list_id, top_n, tld) DO UPDATE SET domains = EXCLUDED.domains
+`
+
+	ctx, span := startQuerySpan(ctx, "tranco_tld_stats.refresh")
+	res, err := dao.ExecContext(ctx, query, listID, tops)
+	endQuerySpan(span, rowsAffected(res), err)
+	if err != nil {
+		return fmt.Errorf("failed to refresh TLD stats of list %s: %w", listID, err)
+	}
+	return nil
+}
+
+func (t TrancoTLDStatsRepositoryImpl) FindByListID(ctx context.Context, listID string, top int) ([]model.TLDCount, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	var counts []model.TLDCount
+	query := `SELECT tld, domains FROM tranco_tld_stats WHERE list_id = $1 AND top_n = $2 ORDER BY domains DESC, tld`
+	ctx, span := startQuerySpan(ctx, "tranco_tld_stats.find_by_list_id")
+	err := dao.SelectContext(ctx, &counts, query, listID, top)
+	endQuerySpan(span, len(counts), err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find TLD stats of the top %d of list %s: %w", top, listID, err)
+	}
+	return counts, nil
+}
+
+func (t TrancoTLDStatsRepositoryImpl) FindLargestTLDs(ctx context.Context, top int, start time.Time, end time.Time, n int) ([]string, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	var tlds []string
+	query := `
+SELECT s.tld
+FROM tranco_tld_stats s
+         INNER JOIN tranco_lists tl ON s.list_id = tl.id
+WHERE s.top_n = $1
+  AND tl.created_on >= $2
+  AND tl.created_on < $3
+GROUP BY s.tld
+ORDER BY SUM(s.domains) DESC, s.tld
+LIMIT $4
+`
+	ctx, span := startQuerySpan(ctx, "tranco_tld_stats.find_largest_tlds")
+	err := dao.SelectContext(ctx, &tlds, query, top, start, end.Add(time.Hour*24), n)
+	endQuerySpan(span, len(tlds), err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the largest TLDs in the top %d from %s to %s: %w", top, start, end, err)
+	}
+	return tlds, nil
+}
+
+func (t TrancoTLDStatsRepositoryImpl) FindByCreatedOnBetween(ctx context.Context, top int, start time.Time, end time.Time, tlds []string) ([]model.TLDListCount, error) {
+	var dao util.Crudable
+	dao, ok := GetTx(ctx)
+	if !ok {
+		dao = t.db
+	}
+
+	var counts []model.TLDListCount
+	query := `
+SELECT s.list_id,
+       tl.created_on,
+       CASE WHEN s.tld = ANY ($4) THEN s.tld ELSE '' END AS tld,
+       SUM(s.domains)                                    AS domains
+FROM tranco_tld_stats s
+         INNER JOIN tranco_lists tl ON s.list_id = tl.id
+WHERE s.top_n = $1
+  AND tl.created_on >= $2
+  AND tl.created_on < $3
+GROUP BY 1, 2, 3
+ORDER BY tl.created_on, s.list_id
+`
+	ctx, span := startQuerySpan(ctx, "tranco_tld_stats.find_by_created_on_between")
+	err := dao.SelectContext(ctx, &counts, query, top, start, end.Add(time.Hour*24), tlds)
+	endQuerySpan(span, len(counts), err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find TLD stats of the top %d from %s to %s: %w", top, start, end, err)
+	}
+	return counts, nil
+}
@@ -0,0 +1,73 @@
+package injector
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/handler"
+	"github.com/shigaichi/top-sites-ranking-api/internal/config"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/repository"
+	"github.com/shigaichi/top-sites-ranking-api/internal/infra"
+	"github.com/shigaichi/top-sites-ranking-api/internal/metrics"
+	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
+)
+
+func stalenessThreshold(cfg config.ReadinessConfig) usecase.StalenessThreshold {
+	return usecase.StalenessThreshold(cfg.Staleness)
+}
+
+func lookupBatchSize(cfg config.LookupConfig) usecase.LookupBatchSize {
+	return usecase.LookupBatchSize(cfg.BatchSize)
+}
+
+func maxLookupDomains(cfg config.LookupConfig) handler.MaxLookupDomains {
+	return handler.MaxLookupDomains(cfg.MaxDomains)
+}
+
+func batchSize(cfg config.WriterConfig) int {
+	return cfg.BatchSize
+}
+
+// rankHistoryUseCase wraps interactor with the in-process cache when it is enabled.
+// Cache hits and misses are recorded in reg unless it is nil.
+func rankHistoryUseCase(cfg config.CacheConfig, interactor *usecase.RankHistoryInteractor, list repository.TrancoListsRepository, reg *prometheus.Registry) usecase.RankHistoryUseCase {
+	if !cfg.Enabled {
+		return interactor
+	}
+
+	var cached *usecase.CachedRankHistoryInteractor
+	var registerer prometheus.Registerer
+	if reg != nil {
+		registerer = reg
+	}
+	observer := metrics.NewCacheMetrics(registerer, "rank_history", func() int { return cached.Len() })
+	cached = usecase.NewCachedRankHistoryInteractor(interactor, list, usecase.RankHistoryCacheOptions{
+		Size:                 cfg.Size,
+		TTL:                  cfg.TTL,
+		PastTTL:              cfg.PastTTL,
+		VersionCheckInterval: cfg.VersionCheckInterval,
+	}, observer)
+	return cached
+}
+
+// apiKeyUseCase returns nil when authentication is disabled, which leaves the routes open.
+// Keys are looked up on db rather than a replica, since their usage is counted on every request.
+func apiKeyUseCase(cfg config.AuthConfig, db *sqlx.DB) usecase.APIKeyUseCase {
+	if !cfg.Enabled {
+		return nil
+	}
+	return usecase.NewAPIKeyInteractor(infra.NewAPIKeyRepositoryImpl(db))
+}
+
+// NewJobInteractor creates the runner of the admin jobs. Its jobs write to db, never to a replica.
+func NewJobInteractor(db *sqlx.DB, writer config.WriterConfig) *usecase.JobInteractor {
+	write := NewStandardWriteInteractor(infra.NewTransaction(db), db, writer)
+	return usecase.NewJobInteractor(write, NewDeleteInteractor(db, 1))
+}
+
+// adminHandler returns nil when authentication is disabled, since the admin API is only served to API keys with the admin scope.
+func adminHandler(cfg config.AuthConfig, jobs *usecase.JobInteractor) handler.Admin {
+	if !cfg.Enabled || jobs == nil {
+		return nil
+	}
+	return handler.NewAdminImpl(jobs)
+}
@@ -6,6 +6,10 @@ package injector
 import (
 	"github.com/google/wire"
 	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	route "github.com/shigaichi/top-sites-ranking-api/internal/adapter/http"
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/handler"
+	"github.com/shigaichi/top-sites-ranking-api/internal/config"
 	"github.com/shigaichi/top-sites-ranking-api/internal/domain/repository"
 	"github.com/shigaichi/top-sites-ranking-api/internal/infra"
 	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
@@ -17,13 +21,18 @@ func NewRankHistoryInteractor(db util.Crudable) *usecase.RankHistoryInteractor {
 		usecase.NewRankHistoryInteractor,
 		infra.NewTrancoDailyRankRepositoryImpl,
 		wire.Bind(new(repository.TrancoDailyRankRepository), new(*infra.TrancoDailyRankRepositoryImpl)),
+		infra.NewTrancoListRepositoryImpl,
+		wire.Bind(new(repository.TrancoListsRepository), new(*infra.TrancoListRepositoryImpl)),
+		infra.NewTrancoMonthlyRankRepositoryImpl,
+		wire.Bind(new(repository.TrancoMonthlyRankRepository), new(*infra.TrancoMonthlyRankRepositoryImpl)),
 	)
 	return nil
 }
 
-func NewStandardWriteInteractor(transaction repository.Transaction, db *sqlx.DB, batchSize int) *usecase.StandardWriteInteractor {
+func NewStandardWriteInteractor(transaction repository.Transaction, db *sqlx.DB, cfg config.WriterConfig) *usecase.StandardWriteInteractor {
 	wire.Build(
 		usecase.NewStandardWriteInteractor,
+		batchSize,
 		infra.NewTrancoAPIImpl,
 		wire.Bind(new(repository.TrancoAPIRepository), new(*infra.TrancoAPIImpl)),
 		infra.NewTrancoListRepositoryImpl,
@@ -34,6 +43,10 @@ func NewStandardWriteInteractor(transaction repository.Transaction, db *sqlx.DB,
 		wire.Bind(new(repository.TrancoDomainsRepository), new(*infra.TrancoDomainRepositoryImpl)),
 		infra.NewTrancoRankingsRepositoryImpl,
 		wire.Bind(new(repository.TrancoRankingsRepository), new(*infra.TrancoRankingsRepositoryImpl)),
+		infra.NewTrancoTLDStatsRepositoryImpl,
+		wire.Bind(new(repository.TrancoTLDStatsRepository), new(*infra.TrancoTLDStatsRepositoryImpl)),
+		infra.NewTrancoMonthlyRankRepositoryImpl,
+		wire.Bind(new(repository.TrancoMonthlyRankRepository), new(*infra.TrancoMonthlyRankRepositoryImpl)),
 		wire.Bind(new(util.Crudable), new(*sqlx.DB)),
 	)
 	return nil
@@ -46,7 +59,99 @@ func NewDeleteInteractor(db *sqlx.DB, batchSize int) *usecase.DeleteInteractor {
 		wire.Bind(new(repository.TrancoListsRepository), new(*infra.TrancoListRepositoryImpl)),
 		infra.NewTrancoRankingsRepositoryImpl,
 		wire.Bind(new(repository.TrancoRankingsRepository), new(*infra.TrancoRankingsRepositoryImpl)),
+		infra.NewTrancoMonthlyRankRepositoryImpl,
+		wire.Bind(new(repository.TrancoMonthlyRankRepository), new(*infra.TrancoMonthlyRankRepositoryImpl)),
 		wire.Bind(new(util.Crudable), new(*sqlx.DB)),
 	)
 	return nil
 }
+
+func NewTopSitesInteractor(db util.Crudable, batchSize int) *usecase.TopSitesInteractor {
+	wire.Build(
+		usecase.NewTopSitesInteractor,
+		infra.NewTrancoListRepositoryImpl,
+		wire.Bind(new(repository.TrancoListsRepository), new(*infra.TrancoListRepositoryImpl)),
+		infra.NewTrancoRankingsRepositoryImpl,
+		wire.Bind(new(repository.TrancoRankingsRepository), new(*infra.TrancoRankingsRepositoryImpl)),
+	)
+	return nil
+}
+
+func NewDomainNameInteractor(db *sqlx.DB, batchSize int) *usecase.DomainNameInteractor {
+	wire.Build(
+		usecase.NewDomainNameInteractor,
+		infra.NewTrancoDomainRepositoryImpl,
+		wire.Bind(new(repository.TrancoDomainsRepository), new(*infra.TrancoDomainRepositoryImpl)),
+	)
+	return nil
+}
+
+func NewTLDStatsInteractor(db util.Crudable) *usecase.TLDStatsInteractor {
+	wire.Build(
+		usecase.NewTLDStatsInteractor,
+		infra.NewTrancoListRepositoryImpl,
+		wire.Bind(new(repository.TrancoListsRepository), new(*infra.TrancoListRepositoryImpl)),
+		infra.NewTrancoTLDStatsRepositoryImpl,
+		wire.Bind(new(repository.TrancoTLDStatsRepository), new(*infra.TrancoTLDStatsRepositoryImpl)),
+	)
+	return nil
+}
+
+func NewAPIKeyInteractor(db util.Crudable) *usecase.APIKeyInteractor {
+	wire.Build(
+		usecase.NewAPIKeyInteractor,
+		infra.NewAPIKeyRepositoryImpl,
+		wire.Bind(new(repository.APIKeyRepository), new(*infra.APIKeyRepositoryImpl)),
+	)
+	return nil
+}
+
+// NewRoute builds the routes of the api server. Queries go through reader, while readiness and API key usage use the primary db.
+// The admin API runs its jobs on jobs, and is not served when jobs is nil.
+func NewRoute(db *sqlx.DB, reader util.Crudable, cfg config.Config, jobs *usecase.JobInteractor, metrics *prometheus.Registry) *route.RouteImpl {
+	wire.Build(
+		route.NewRouteImpl,
+		wire.Struct(new(route.Handlers), "*"),
+		wire.FieldsOf(new(config.Config), "Server", "Readiness", "Cache", "Auth", "Lookup"),
+		apiKeyUseCase,
+		adminHandler,
+		handler.NewGetRankingImpl,
+		wire.Bind(new(handler.GetRanking), new(*handler.GetRankingImpl)),
+		handler.NewLookupImpl,
+		wire.Bind(new(handler.Lookup), new(*handler.LookupImpl)),
+		maxLookupDomains,
+		usecase.NewLookupInteractor,
+		wire.Bind(new(usecase.LookupUseCase), new(*usecase.LookupInteractor)),
+		lookupBatchSize,
+		handler.NewListsImpl,
+		wire.Bind(new(handler.Lists), new(*handler.ListsImpl)),
+		usecase.NewListCatalogInteractor,
+		wire.Bind(new(usecase.ListCatalogUseCase), new(*usecase.ListCatalogInteractor)),
+		handler.NewStatsImpl,
+		wire.Bind(new(handler.Stats), new(*handler.StatsImpl)),
+		usecase.NewTLDStatsInteractor,
+		wire.Bind(new(usecase.TLDStatsUseCase), new(*usecase.TLDStatsInteractor)),
+		infra.NewTrancoTLDStatsRepositoryImpl,
+		wire.Bind(new(repository.TrancoTLDStatsRepository), new(*infra.TrancoTLDStatsRepositoryImpl)),
+		handler.NewTrendImpl,
+		wire.Bind(new(handler.Trend), new(*handler.TrendImpl)),
+		usecase.NewTrendInteractor,
+		wire.Bind(new(usecase.TrendUseCase), new(*usecase.TrendInteractor)),
+		handler.NewHealthImpl,
+		wire.Bind(new(handler.Health), new(*handler.HealthImpl)),
+		usecase.NewRankHistoryInteractor,
+		rankHistoryUseCase,
+		usecase.NewReadinessInteractor,
+		wire.Bind(new(usecase.ReadinessUseCase), new(*usecase.ReadinessInteractor)),
+		stalenessThreshold,
+		infra.NewTrancoDailyRankRepositoryImpl,
+		wire.Bind(new(repository.TrancoDailyRankRepository), new(*infra.TrancoDailyRankRepositoryImpl)),
+		infra.NewTrancoListRepositoryImpl,
+		wire.Bind(new(repository.TrancoListsRepository), new(*infra.TrancoListRepositoryImpl)),
+		infra.NewTrancoMonthlyRankRepositoryImpl,
+		wire.Bind(new(repository.TrancoMonthlyRankRepository), new(*infra.TrancoMonthlyRankRepositoryImpl)),
+		infra.NewHealthRepositoryImpl,
+		wire.Bind(new(repository.HealthRepository), new(*infra.HealthRepositoryImpl)),
+	)
+	return nil
+}
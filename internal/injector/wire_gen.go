@@ -8,6 +8,10 @@ package injector
 
 import (
 	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http"
+	"github.com/shigaichi/top-sites-ranking-api/internal/adapter/http/handler"
+	"github.com/shigaichi/top-sites-ranking-api/internal/config"
 	"github.com/shigaichi/top-sites-ranking-api/internal/domain/repository"
 	"github.com/shigaichi/top-sites-ranking-api/internal/infra"
 	"github.com/shigaichi/top-sites-ranking-api/internal/usecase"
@@ -18,23 +22,99 @@ import (
 
 func NewRankHistoryInteractor(db util.Crudable) *usecase.RankHistoryInteractor {
 	trancoDailyRankRepositoryImpl := infra.NewTrancoDailyRankRepositoryImpl(db)
-	rankHistoryInteractor := usecase.NewRankHistoryInteractor(trancoDailyRankRepositoryImpl)
+	trancoListRepositoryImpl := infra.NewTrancoListRepositoryImpl(db)
+	trancoMonthlyRankRepositoryImpl := infra.NewTrancoMonthlyRankRepositoryImpl(db)
+	rankHistoryInteractor := usecase.NewRankHistoryInteractor(trancoDailyRankRepositoryImpl, trancoListRepositoryImpl, trancoMonthlyRankRepositoryImpl)
 	return rankHistoryInteractor
 }
 
-func NewStandardWriteInteractor(transaction repository.Transaction, db *sqlx.DB, batchSize int) *usecase.StandardWriteInteractor {
+func NewStandardWriteInteractor(transaction repository.Transaction, db *sqlx.DB, cfg config.WriterConfig) *usecase.StandardWriteInteractor {
 	trancoAPIImpl := infra.NewTrancoAPIImpl()
 	trancoListRepositoryImpl := infra.NewTrancoListRepositoryImpl(db)
 	trancoCsvImpl := infra.NewTrancoCsvImpl()
 	trancoDomainRepositoryImpl := infra.NewTrancoDomainRepositoryImpl(db)
-	trancoRankingsRepositoryImpl := infra.NewTrancoRankingsRepositoryImpl(batchSize, db)
-	standardWriteInteractor := usecase.NewStandardWriteInteractor(trancoAPIImpl, trancoListRepositoryImpl, trancoCsvImpl, transaction, trancoDomainRepositoryImpl, trancoRankingsRepositoryImpl)
+	int2 := batchSize(cfg)
+	trancoRankingsRepositoryImpl := infra.NewTrancoRankingsRepositoryImpl(int2, db)
+	trancoTLDStatsRepositoryImpl := infra.NewTrancoTLDStatsRepositoryImpl(db)
+	trancoMonthlyRankRepositoryImpl := infra.NewTrancoMonthlyRankRepositoryImpl(db)
+	standardWriteInteractor := usecase.NewStandardWriteInteractor(trancoAPIImpl, trancoListRepositoryImpl, trancoCsvImpl, transaction, trancoDomainRepositoryImpl, trancoRankingsRepositoryImpl, trancoTLDStatsRepositoryImpl, trancoMonthlyRankRepositoryImpl)
 	return standardWriteInteractor
 }
 
-func NewDeleteInteractor(db *sqlx.DB, batchSize int) *usecase.DeleteInteractor {
+func NewDeleteInteractor(db *sqlx.DB, batchSize2 int) *usecase.DeleteInteractor {
 	trancoListRepositoryImpl := infra.NewTrancoListRepositoryImpl(db)
-	trancoRankingsRepositoryImpl := infra.NewTrancoRankingsRepositoryImpl(batchSize, db)
-	deleteInteractor := usecase.NewDeleteInteractor(trancoListRepositoryImpl, trancoRankingsRepositoryImpl)
+	trancoRankingsRepositoryImpl := infra.NewTrancoRankingsRepositoryImpl(batchSize2, db)
+	trancoMonthlyRankRepositoryImpl := infra.NewTrancoMonthlyRankRepositoryImpl(db)
+	deleteInteractor := usecase.NewDeleteInteractor(trancoListRepositoryImpl, trancoRankingsRepositoryImpl, trancoMonthlyRankRepositoryImpl)
 	return deleteInteractor
 }
+
+func NewTopSitesInteractor(db util.Crudable, batchSize2 int) *usecase.TopSitesInteractor {
+	trancoListRepositoryImpl := infra.NewTrancoListRepositoryImpl(db)
+	trancoRankingsRepositoryImpl := infra.NewTrancoRankingsRepositoryImpl(batchSize2, db)
+	topSitesInteractor := usecase.NewTopSitesInteractor(trancoListRepositoryImpl, trancoRankingsRepositoryImpl)
+	return topSitesInteractor
+}
+
+func NewDomainNameInteractor(db *sqlx.DB, batchSize2 int) *usecase.DomainNameInteractor {
+	trancoDomainRepositoryImpl := infra.NewTrancoDomainRepositoryImpl(db)
+	domainNameInteractor := usecase.NewDomainNameInteractor(trancoDomainRepositoryImpl, batchSize2)
+	return domainNameInteractor
+}
+
+func NewTLDStatsInteractor(db util.Crudable) *usecase.TLDStatsInteractor {
+	trancoListRepositoryImpl := infra.NewTrancoListRepositoryImpl(db)
+	trancoTLDStatsRepositoryImpl := infra.NewTrancoTLDStatsRepositoryImpl(db)
+	tldStatsInteractor := usecase.NewTLDStatsInteractor(trancoListRepositoryImpl, trancoTLDStatsRepositoryImpl)
+	return tldStatsInteractor
+}
+
+func NewAPIKeyInteractor(db util.Crudable) *usecase.APIKeyInteractor {
+	apiKeyRepositoryImpl := infra.NewAPIKeyRepositoryImpl(db)
+	apiKeyInteractor := usecase.NewAPIKeyInteractor(apiKeyRepositoryImpl)
+	return apiKeyInteractor
+}
+
+// NewRoute builds the routes of the api server. Queries go through reader, while readiness and API key usage use the primary db.
+// The admin API runs its jobs on jobs, and is not served when jobs is nil.
+func NewRoute(db *sqlx.DB, reader util.Crudable, cfg config.Config, jobs *usecase.JobInteractor, metrics *prometheus.Registry) *http.RouteImpl {
+	cacheConfig := cfg.Cache
+	trancoDailyRankRepositoryImpl := infra.NewTrancoDailyRankRepositoryImpl(reader)
+	trancoListRepositoryImpl := infra.NewTrancoListRepositoryImpl(reader)
+	trancoMonthlyRankRepositoryImpl := infra.NewTrancoMonthlyRankRepositoryImpl(reader)
+	rankHistoryInteractor := usecase.NewRankHistoryInteractor(trancoDailyRankRepositoryImpl, trancoListRepositoryImpl, trancoMonthlyRankRepositoryImpl)
+	usecaseRankHistoryUseCase := rankHistoryUseCase(cacheConfig, rankHistoryInteractor, trancoListRepositoryImpl, metrics)
+	getRankingImpl := handler.NewGetRankingImpl(usecaseRankHistoryUseCase)
+	lookupConfig := cfg.Lookup
+	usecaseLookupBatchSize := lookupBatchSize(lookupConfig)
+	lookupInteractor := usecase.NewLookupInteractor(trancoListRepositoryImpl, trancoDailyRankRepositoryImpl, usecaseLookupBatchSize)
+	handlerMaxLookupDomains := maxLookupDomains(lookupConfig)
+	lookupImpl := handler.NewLookupImpl(lookupInteractor, handlerMaxLookupDomains)
+	listCatalogInteractor := usecase.NewListCatalogInteractor(trancoListRepositoryImpl)
+	listsImpl := handler.NewListsImpl(listCatalogInteractor)
+	trancoTLDStatsRepositoryImpl := infra.NewTrancoTLDStatsRepositoryImpl(reader)
+	tldStatsInteractor := usecase.NewTLDStatsInteractor(trancoListRepositoryImpl, trancoTLDStatsRepositoryImpl)
+	statsImpl := handler.NewStatsImpl(tldStatsInteractor)
+	trendInteractor := usecase.NewTrendInteractor(usecaseRankHistoryUseCase)
+	trendImpl := handler.NewTrendImpl(trendInteractor)
+	healthRepositoryImpl := infra.NewHealthRepositoryImpl(db)
+	readinessConfig := cfg.Readiness
+	usecaseStalenessThreshold := stalenessThreshold(readinessConfig)
+	readinessInteractor := usecase.NewReadinessInteractor(healthRepositoryImpl, trancoListRepositoryImpl, usecaseStalenessThreshold)
+	healthImpl := handler.NewHealthImpl(readinessInteractor)
+	authConfig := cfg.Auth
+	admin := adminHandler(authConfig, jobs)
+	handlers := http.Handlers{
+		Rankings: getRankingImpl,
+		Lookup:   lookupImpl,
+		Lists:    listsImpl,
+		Stats:    statsImpl,
+		Trend:    trendImpl,
+		Health:   healthImpl,
+		Admin:    admin,
+	}
+	usecaseAPIKeyUseCase := apiKeyUseCase(authConfig, db)
+	serverConfig := cfg.Server
+	routeImpl := http.NewRouteImpl(handlers, usecaseAPIKeyUseCase, serverConfig, authConfig, metrics)
+	return routeImpl
+}
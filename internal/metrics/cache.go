@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CacheMetrics counts the lookups of an in-process cache by method and whether they hit.
+type CacheMetrics struct {
+	lookups *prometheus.CounterVec
+}
+
+// NewCacheMetrics creates the metrics of the cache called name and registers them in reg unless it is nil.
+// entries reports the current number of cached entries.
+func NewCacheMetrics(reg prometheus.Registerer, name string, entries func() int) *CacheMetrics {
+	labels := prometheus.Labels{"cache": name}
+	m := &CacheMetrics{
+		lookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "cache_lookups_total",
+			Help:        "Cache lookups by method and result (hit or miss).",
+			ConstLabels: labels,
+		}, []string{"method", "result"}),
+	}
+	if reg != nil {
+		reg.MustRegister(
+			m.lookups,
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name:        "cache_entries",
+				Help:        "Entries currently held by the cache.",
+				ConstLabels: labels,
+			}, func() float64 { return float64(entries()) }),
+		)
+	}
+	return m
+}
+
+func (m *CacheMetrics) Hit(method string) {
+	m.lookups.WithLabelValues(method, "hit").Inc()
+}
+
+func (m *CacheMetrics) Miss(method string) {
+	m.lookups.WithLabelValues(method, "miss").Inc()
+}
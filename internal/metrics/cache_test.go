@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCacheMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewCacheMetrics(reg, "rank_history", func() int { return 3 })
+
+	m.Hit("daily")
+	m.Hit("daily")
+	m.Miss("daily")
+	m.Miss("monthly")
+
+	expected := `
+# HELP cache_entries Entries currently held by the cache.
+# TYPE cache_entries gauge
+cache_entries{cache="rank_history"} 3
+# HELP cache_lookups_total Cache lookups by method and result (hit or miss).
+# TYPE cache_lookups_total counter
+cache_lookups_total{cache="rank_history",method="daily",result="hit"} 2
+cache_lookups_total{cache="rank_history",method="daily",result="miss"} 1
+cache_lookups_total{cache="rank_history",method="monthly",result="miss"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,213 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// pushgatewayTimeout bounds reading the previous metrics from a Pushgateway.
+const pushgatewayTimeout = 10 * time.Second
+
+// JobMetrics records the outcome of one run of a batch job such as the writer or the delete job.
+// The metrics are exported either as a node_exporter textfile or by pushing them to a Pushgateway.
+type JobMetrics struct {
+	job         string
+	rows        prometheus.Gauge
+	duration    prometheus.Gauge
+	lastSuccess prometheus.Gauge
+	lastFailure prometheus.Gauge
+	failures    prometheus.Counter
+	succeeded   bool
+}
+
+// NewJobMetrics creates the metrics of job.
+func NewJobMetrics(job string) *JobMetrics {
+	labels := prometheus.Labels{"job_name": job}
+	return &JobMetrics{
+		job: job,
+		rows: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "ranking_job_rows_processed",
+			Help:        "Rows ingested or deleted by the last successful run.",
+			ConstLabels: labels,
+		}),
+		duration: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "ranking_job_duration_seconds",
+			Help:        "Duration of the last run.",
+			ConstLabels: labels,
+		}),
+		lastSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "ranking_job_last_success_timestamp_seconds",
+			Help:        "Unix time of the last successful run.",
+			ConstLabels: labels,
+		}),
+		lastFailure: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "ranking_job_last_failure_timestamp_seconds",
+			Help:        "Unix time of the last failed run.",
+			ConstLabels: labels,
+		}),
+		failures: newFailures(job),
+	}
+}
+
+func newFailures(job string) prometheus.Counter {
+	return prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "ranking_job_failures_total",
+		Help:        "Failed runs.",
+		ConstLabels: prometheus.Labels{"job_name": job},
+	})
+}
+
+// Observe records a run that started at start and processed rows. A non-nil err marks the run as failed.
+func (m *JobMetrics) Observe(start time.Time, rows int, err error) {
+	now := time.Now()
+	m.duration.Set(now.Sub(start).Seconds())
+	if err != nil {
+		m.succeeded = false
+		m.lastFailure.Set(float64(now.Unix()))
+		m.failures.Inc()
+		return
+	}
+	m.succeeded = true
+	m.rows.Set(float64(rows))
+	m.lastSuccess.Set(float64(now.Unix()))
+}
+
+// WriteTextfile writes the metrics to path in the Prometheus text format for the node_exporter textfile collector.
+// Values of the previous run that this run did not update, such as the last success time of a failed run, are kept from the existing file.
+func (m *JobMetrics) WriteTextfile(path string) error {
+	if err := m.restore(path); err != nil {
+		return err
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(m.rows, m.duration, m.lastSuccess, m.lastFailure, m.failures)
+	if err := prometheus.WriteToTextfile(path, reg); err != nil {
+		return fmt.Errorf("failed to write metrics textfile %s: %w", path, err)
+	}
+	return nil
+}
+
+// Push adds the metrics of this run to the job group on the Pushgateway at url.
+// Metrics that this run did not update are not pushed, so the Pushgateway keeps their previous values.
+// The failure counter of a failed run continues from the value on the Pushgateway, as pushing replaces it.
+func (m *JobMetrics) Push(url string) error {
+	var errs []error
+	p := push.New(url, m.job).Collector(m.duration)
+	if m.succeeded {
+		p = p.Collector(m.rows).Collector(m.lastSuccess)
+	} else {
+		p = p.Collector(m.lastFailure)
+		// without the previous value the counter is not pushed rather than reset
+		previous, err := m.pushedFailures(url)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			failures := newFailures(m.job)
+			failures.Add(previous + 1)
+			p = p.Collector(failures)
+		}
+	}
+	if err := p.Add(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to push metrics to %s: %w", url, err))
+	}
+	return errors.Join(errs...)
+}
+
+// pushedFailures reads the failure counter of the job group from the Pushgateway at url. It is 0 when nothing was pushed yet.
+func (m *JobMetrics) pushedFailures(url string) (float64, error) {
+	client := http.Client{Timeout: pushgatewayTimeout}
+	resp, err := client.Get(strings.TrimSuffix(url, "/") + "/metrics")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read metrics from %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to read metrics from %s: status %d", url, resp.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse metrics from %s: %w", url, err)
+	}
+	family, ok := families["ranking_job_failures_total"]
+	if !ok {
+		return 0, nil
+	}
+	for _, metric := range family.GetMetric() {
+		for _, label := range metric.GetLabel() {
+			if label.GetName() == "job" && label.GetValue() == m.job {
+				return metricValue(metric), nil
+			}
+		}
+	}
+	return 0, nil
+}
+
+// restore carries values that this run did not update over from the textfile of the previous run.
+func (m *JobMetrics) restore(path string) error {
+	f, err := os.Open(path) // #nosec G304 -- the path is configured by the operator
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to open metrics textfile %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(f)
+	if err != nil {
+		// a broken file is replaced rather than blocking the job
+		return nil
+	}
+
+	previous := func(name string) float64 {
+		family, ok := families[name]
+		if !ok || len(family.GetMetric()) == 0 {
+			return 0
+		}
+		return metricValue(family.GetMetric()[0])
+	}
+
+	if !m.succeeded {
+		m.rows.Set(previous("ranking_job_rows_processed"))
+		m.lastSuccess.Set(previous("ranking_job_last_success_timestamp_seconds"))
+	} else {
+		m.lastFailure.Set(previous("ranking_job_last_failure_timestamp_seconds"))
+	}
+	m.failures.Add(previous("ranking_job_failures_total"))
+	return nil
+}
+
+func metricValue(metric *dto.Metric) float64 {
+	switch {
+	case metric.GetCounter() != nil:
+		return metric.GetCounter().GetValue()
+	case metric.GetUntyped() != nil:
+		return metric.GetUntyped().GetValue()
+	default:
+		return metric.GetGauge().GetValue()
+	}
+}
+
+// Export writes the metrics to textfile and pushes them to pushgatewayURL. Empty destinations are skipped.
+func (m *JobMetrics) Export(textfile, pushgatewayURL string) error {
+	var errs []error
+	if textfile != "" {
+		errs = append(errs, m.WriteTextfile(textfile))
+	}
+	if pushgatewayURL != "" {
+		errs = append(errs, m.Push(pushgatewayURL))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+func readMetric(t *testing.T, path, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.HasPrefix(line, name+"{") {
+			return line[strings.LastIndex(line, " ")+1:]
+		}
+	}
+	return ""
+}
+
+func TestJobMetrics_WriteTextfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "writer.prom")
+
+	success := NewJobMetrics("standard_writer")
+	success.Observe(time.Now(), 100, nil)
+	if err := success.WriteTextfile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	lastSuccess := readMetric(t, path, "ranking_job_last_success_timestamp_seconds")
+	if lastSuccess == "" || lastSuccess == "0" {
+		t.Fatalf("expected last success timestamp, got %q", lastSuccess)
+	}
+	if rows := readMetric(t, path, "ranking_job_rows_processed"); rows != "100" {
+		t.Errorf("expected 100 rows, got %q", rows)
+	}
+
+	for i := 0; i < 2; i++ {
+		failure := NewJobMetrics("standard_writer")
+		failure.Observe(time.Now(), 0, errors.New("test"))
+		if err := failure.WriteTextfile(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := readMetric(t, path, "ranking_job_last_success_timestamp_seconds"); got != lastSuccess {
+		t.Errorf("expected last success %s to be kept, got %s", lastSuccess, got)
+	}
+	if rows := readMetric(t, path, "ranking_job_rows_processed"); rows != "100" {
+		t.Errorf("expected 100 rows to be kept, got %q", rows)
+	}
+	if failures := readMetric(t, path, "ranking_job_failures_total"); failures != "2" {
+		t.Errorf("expected 2 failures, got %q", failures)
+	}
+	if lastFailure := readMetric(t, path, "ranking_job_last_failure_timestamp_seconds"); lastFailure == "" || lastFailure == "0" {
+		t.Errorf("expected last failure timestamp, got %q", lastFailure)
+	}
+}
+
+// pushgateway records the metric families pushed to it and serves previous as its metrics.
+type pushgateway struct {
+	previous string
+	pushed   map[string]*dto.MetricFamily
+}
+
+func (p *pushgateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		_, _ = w.Write([]byte(p.previous))
+		return
+	}
+	p.pushed = map[string]*dto.MetricFamily{}
+	dec := expfmt.NewDecoder(r.Body, expfmt.ResponseFormat(r.Header))
+	for {
+		var family dto.MetricFamily
+		if err := dec.Decode(&family); err != nil {
+			break
+		}
+		p.pushed[family.GetName()] = &family
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func TestJobMetrics_Push(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		previous     string
+		wantPushed   []string
+		wantFailures float64
+	}{
+		{
+			name:       "success",
+			wantPushed: []string{"ranking_job_duration_seconds", "ranking_job_last_success_timestamp_seconds", "ranking_job_rows_processed"},
+		},
+		{
+			name: "failure continues the pushed counter",
+			err:  errors.New("test"),
+			previous: `ranking_job_failures_total{instance="",job="delete",job_name="delete"} 2
+ranking_job_failures_total{instance="",job="standard_writer",job_name="standard_writer"} 5
+`,
+			wantPushed:   []string{"ranking_job_duration_seconds", "ranking_job_failures_total", "ranking_job_last_failure_timestamp_seconds"},
+			wantFailures: 3,
+		},
+		{
+			name:         "first failure",
+			err:          errors.New("test"),
+			wantPushed:   []string{"ranking_job_duration_seconds", "ranking_job_failures_total", "ranking_job_last_failure_timestamp_seconds"},
+			wantFailures: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := &pushgateway{previous: tt.previous}
+			srv := httptest.NewServer(gateway)
+			defer srv.Close()
+
+			m := NewJobMetrics("delete")
+			m.Observe(time.Now(), 10, tt.err)
+			if err := m.Push(srv.URL); err != nil {
+				t.Fatal(err)
+			}
+
+			var pushed []string
+			for name := range gateway.pushed {
+				pushed = append(pushed, name)
+			}
+			slices.Sort(pushed)
+			if !slices.Equal(pushed, tt.wantPushed) {
+				t.Errorf("expected %v to be pushed, got %v", tt.wantPushed, pushed)
+			}
+			if family, ok := gateway.pushed["ranking_job_failures_total"]; ok {
+				if got := family.GetMetric()[0].GetCounter().GetValue(); got != tt.wantFailures {
+					t.Errorf("expected %v failures, got %v", tt.wantFailures, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// NewRegistry creates the registry the API server serves with Handler.
+// Besides the Go runtime and process metrics, it exposes the connection pool stats of db with the db_name label
+// ranking, and of every read replica with ranking_replica_1, ranking_replica_2 and so on in the order of replicas.
+func NewRegistry(db *sql.DB, replicas []*sql.DB) *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(db, "ranking"),
+	)
+	for i, replica := range replicas {
+		reg.MustRegister(collectors.NewDBStatsCollector(replica, fmt.Sprintf("ranking_replica_%d", i+1)))
+	}
+	return reg
+}
+
+// Handler serves reg at /metrics. The API server listens for it on its own address, so that the metrics are not
+// exposed together with the API.
+func Handler(reg *prometheus.Registry) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	return mux
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestNewRegistry(t *testing.T) {
+	open := func() *sql.DB {
+		// opening does not connect, so the pool stats are available without a database
+		db, err := sql.Open("pgx", "postgres://localhost/ranking")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+		return db
+	}
+
+	families, err := NewRegistry(open(), []*sql.DB{open(), open()}).Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := map[string]bool{}
+	for _, family := range families {
+		if family.GetName() != "go_sql_open_connections" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "db_name" {
+					names[label.GetValue()] = true
+				}
+			}
+		}
+	}
+	for _, name := range []string{"ranking", "ranking_replica_1", "ranking_replica_2"} {
+		if !names[name] {
+			t.Errorf("expected pool stats of %s, got %v", name, names)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{Name: "test_total", Help: "Test."}))
+
+	for path, wantStatus := range map[string]int{"/metrics": http.StatusOK, "/api/v1/rankings/daily": http.StatusNotFound} {
+		rec := httptest.NewRecorder()
+		Handler(reg).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != wantStatus {
+			t.Errorf("expected status %d for %s, got %d", wantStatus, path, rec.Code)
+		}
+		if wantStatus == http.StatusOK && !strings.Contains(rec.Body.String(), "test_total 0") {
+			t.Errorf("expected the registry to be served, got %s", rec.Body.String())
+		}
+	}
+}
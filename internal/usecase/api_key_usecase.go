@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/repository"
+)
+
+// apiKeyPrefix marks API keys so that leaked keys are easy to recognize in logs and secret scanners.
+const apiKeyPrefix = "tsr_"
+
+var (
+	// ErrInvalidAPIKey is returned for keys that are unknown or revoked.
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrQuotaExceeded is returned once a key has used up its daily quota.
+	ErrQuotaExceeded = errors.New("daily quota exceeded")
+)
+
+type APIKeyUseCase interface {
+	// Authenticate resolves key and counts the request against the daily quota of the key.
+	Authenticate(ctx context.Context, key string) (model.APIKey, error)
+	// Create issues a new key and returns it with the secret that the client has to send.
+	Create(ctx context.Context, name string, scopes []model.Scope, dailyQuota int) (model.APIKey, string, error)
+	List(ctx context.Context) ([]model.APIKey, error)
+	// Revoke revokes the key named name. It returns ErrInvalidAPIKey when there is no such active key.
+	Revoke(ctx context.Context, name string) error
+}
+
+type APIKeyInteractor struct {
+	repo repository.APIKeyRepository
+	now  func() time.Time
+}
+
+func NewAPIKeyInteractor(repo repository.APIKeyRepository) *APIKeyInteractor {
+	return &APIKeyInteractor{repo: repo, now: time.Now}
+}
+
+// HashAPIKey returns the hash under which key is stored.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (a APIKeyInteractor) Authenticate(ctx context.Context, key string) (model.APIKey, error) {
+	apiKey, err := a.repo.FindByHash(ctx, HashAPIKey(key))
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("failed to find api key: %w", err)
+	}
+	if apiKey.ID == 0 || apiKey.Revoked() {
+		return model.APIKey{}, ErrInvalidAPIKey
+	}
+
+	requests, err := a.repo.IncrementUsage(ctx, apiKey.ID, a.now().UTC())
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("failed to count usage of api key %s: %w", apiKey.Name, err)
+	}
+	if apiKey.DailyQuota > 0 && requests > apiKey.DailyQuota {
+		return apiKey, ErrQuotaExceeded
+	}
+	return apiKey, nil
+}
+
+func (a APIKeyInteractor) Create(ctx context.Context, name string, scopes []model.Scope, dailyQuota int) (model.APIKey, string, error) {
+	if name == "" {
+		return model.APIKey{}, "", errors.New("api key name must not be empty")
+	}
+	if len(scopes) == 0 {
+		return model.APIKey{}, "", errors.New("api key needs at least one scope")
+	}
+	if dailyQuota < 0 {
+		return model.APIKey{}, "", errors.New("daily quota must not be negative")
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return model.APIKey{}, "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key := apiKeyPrefix + hex.EncodeToString(secret)
+
+	apiKey, err := a.repo.Create(ctx, model.APIKey{Name: name, Scopes: scopes, DailyQuota: dailyQuota}, HashAPIKey(key))
+	if err != nil {
+		return model.APIKey{}, "", err
+	}
+	return apiKey, key, nil
+}
+
+func (a APIKeyInteractor) List(ctx context.Context) ([]model.APIKey, error) {
+	return a.repo.FindAll(ctx)
+}
+
+func (a APIKeyInteractor) Revoke(ctx context.Context, name string) error {
+	revoked, err := a.repo.Revoke(ctx, name, a.now().UTC())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrInvalidAPIKey
+	}
+	return nil
+}
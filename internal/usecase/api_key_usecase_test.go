@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+type mockAPIKeyRepo struct {
+	keys  map[string]model.APIKey
+	usage map[int]int
+	days  []time.Time
+	err   error
+}
+
+func newMockAPIKeyRepo() *mockAPIKeyRepo {
+	return &mockAPIKeyRepo{keys: map[string]model.APIKey{}, usage: map[int]int{}}
+}
+
+func (m *mockAPIKeyRepo) Create(ctx context.Context, key model.APIKey, hash string) (model.APIKey, error) {
+	key.ID = len(m.keys) + 1
+	m.keys[hash] = key
+	return key, nil
+}
+
+func (m *mockAPIKeyRepo) FindByHash(ctx context.Context, hash string) (model.APIKey, error) {
+	return m.keys[hash], m.err
+}
+
+func (m *mockAPIKeyRepo) FindAll(ctx context.Context) ([]model.APIKey, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockAPIKeyRepo) Revoke(ctx context.Context, name string, at time.Time) (bool, error) {
+	for hash, key := range m.keys {
+		if key.Name == name && !key.Revoked() {
+			key.RevokedAt = &at
+			m.keys[hash] = key
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockAPIKeyRepo) IncrementUsage(ctx context.Context, keyID int, day time.Time) (int, error) {
+	m.days = append(m.days, day)
+	m.usage[keyID]++
+	return m.usage[keyID], nil
+}
+
+func TestAPIKeyInteractor_CreateAndAuthenticate(t *testing.T) {
+	repo := newMockAPIKeyRepo()
+	now := time.Date(2024, 1, 10, 23, 30, 0, 0, time.FixedZone("JST", 9*60*60))
+	u := NewAPIKeyInteractor(repo)
+	u.now = func() time.Time { return now }
+
+	created, key, err := u.Create(context.Background(), "dashboard", []model.Scope{model.ScopeRead}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		t.Errorf("expected key with prefix %s, got %s", apiKeyPrefix, key)
+	}
+	if _, ok := repo.keys[key]; ok {
+		t.Errorf("expected key to be stored hashed")
+	}
+
+	for i := 0; i < 2; i++ {
+		got, err := u.Authenticate(context.Background(), key)
+		if err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i+1, err)
+		}
+		if diff := cmp.Diff(created, got); diff != "" {
+			t.Errorf("unexpected key (-want +got):\n%s", diff)
+		}
+	}
+
+	if _, err := u.Authenticate(context.Background(), key); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded, got %v", err)
+	}
+	if want := time.Date(2024, 1, 10, 14, 30, 0, 0, time.UTC); !repo.days[0].Equal(want) || repo.days[0].Location() != time.UTC {
+		t.Errorf("expected usage counted on UTC day of %s, got %s", want, repo.days[0])
+	}
+}
+
+func TestAPIKeyInteractor_Authenticate(t *testing.T) {
+	revokedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dbErr := errors.New("connection refused")
+
+	tests := []struct {
+		name    string
+		key     model.APIKey
+		repoErr error
+		wantErr error
+	}{
+		{name: "unlimited quota", key: model.APIKey{ID: 1, Name: "a", Scopes: []model.Scope{model.ScopeRead}}},
+		{name: "unknown key", wantErr: ErrInvalidAPIKey},
+		{name: "revoked key", key: model.APIKey{ID: 1, Name: "a", RevokedAt: &revokedAt}, wantErr: ErrInvalidAPIKey},
+		{name: "repository error", repoErr: dbErr, wantErr: dbErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockAPIKeyRepo()
+			repo.err = tt.repoErr
+			if tt.key.ID != 0 {
+				repo.keys[HashAPIKey("secret")] = tt.key
+			}
+
+			_, err := NewAPIKeyInteractor(repo).Authenticate(context.Background(), "secret")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil && len(repo.days) != 0 {
+				t.Errorf("expected rejected key not to be counted")
+			}
+		})
+	}
+}
+
+func TestAPIKeyInteractor_Revoke(t *testing.T) {
+	repo := newMockAPIKeyRepo()
+	u := NewAPIKeyInteractor(repo)
+	_, key, err := u.Create(context.Background(), "dashboard", []model.Scope{model.ScopeRead}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := u.Revoke(context.Background(), "dashboard"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := u.Revoke(context.Background(), "dashboard"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("expected ErrInvalidAPIKey for an already revoked key, got %v", err)
+	}
+	if _, err := u.Authenticate(context.Background(), key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("expected revoked key to be rejected, got %v", err)
+	}
+}
+
+func TestAPIKeyInteractor_CreateValidation(t *testing.T) {
+	u := NewAPIKeyInteractor(newMockAPIKeyRepo())
+	if _, _, err := u.Create(context.Background(), "", []model.Scope{model.ScopeRead}, 0); err == nil {
+		t.Errorf("expected error for empty name")
+	}
+	if _, _, err := u.Create(context.Background(), "a", nil, 0); err == nil {
+		t.Errorf("expected error without scopes")
+	}
+	if _, _, err := u.Create(context.Background(), "a", []model.Scope{model.ScopeRead}, -1); err == nil {
+		t.Errorf("expected error for negative quota")
+	}
+}
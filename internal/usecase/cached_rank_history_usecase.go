@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/cache"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/repository"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+// CacheObserver is told whether each cache lookup hit.
+type CacheObserver interface {
+	Hit(method string)
+	Miss(method string)
+}
+
+// RankHistoryCacheOptions configures CachedRankHistoryInteractor.
+type RankHistoryCacheOptions struct {
+	// Size is the maximum number of cached results. It is split evenly between the daily rankings and the rankings with gaps.
+	Size int
+	// TTL is how long a result whose range includes today or a later day is cached.
+	TTL time.Duration
+	// PastTTL is how long a result whose range ended before today is cached.
+	PastTTL time.Duration
+	// VersionCheckInterval is how often the version of the lists is looked up to detect saved and deleted lists.
+	VersionCheckInterval time.Duration
+}
+
+type rankHistoryKey struct {
+	// version is the version of the lists when the result was loaded, so that a result loaded while a list was saved or
+	// deleted is never served.
+	version string
+	method  string
+	domain  string
+	rollup  model.Rollup
+	start   time.Time
+	end     time.Time
+	fill    model.FillMode
+}
+
+// CachedRankHistoryInteractor caches the results of another RankHistoryUseCase in memory.
+// The cache is versioned by the saved lists: when a list is saved or deleted, by any process, every cached result is dropped.
+type CachedRankHistoryInteractor struct {
+	next     RankHistoryUseCase
+	list     repository.TrancoListsRepository
+	opts     RankHistoryCacheOptions
+	observer CacheObserver
+	ranks    *cache.LRU[rankHistoryKey, []model.DailyRank]
+	entries  *cache.LRU[rankHistoryKey, []model.DailyRankEntry]
+	now      func() time.Time
+	// lookups lets a single caller look up the version while the others wait for its result.
+	lookups singleflight.Group
+
+	mu        sync.Mutex
+	version   string
+	checkedAt time.Time
+}
+
+func NewCachedRankHistoryInteractor(next RankHistoryUseCase, list repository.TrancoListsRepository, opts RankHistoryCacheOptions, observer CacheObserver) *CachedRankHistoryInteractor {
+	return newCachedRankHistoryInteractor(next, list, opts, observer, time.Now)
+}
+
+func newCachedRankHistoryInteractor(next RankHistoryUseCase, list repository.TrancoListsRepository, opts RankHistoryCacheOptions, observer CacheObserver, now func() time.Time) *CachedRankHistoryInteractor {
+	return &CachedRankHistoryInteractor{
+		next:     next,
+		list:     list,
+		opts:     opts,
+		observer: observer,
+		ranks:    cache.NewLRU[rankHistoryKey, []model.DailyRank](max(opts.Size-opts.Size/2, 1), now),
+		entries:  cache.NewLRU[rankHistoryKey, []model.DailyRankEntry](max(opts.Size/2, 1), now),
+		now:      now,
+	}
+}
+
+// Len returns the number of cached results.
+func (c *CachedRankHistoryInteractor) Len() int {
+	return c.ranks.Len() + c.entries.Len()
+}
+
+func (c *CachedRankHistoryInteractor) GetDailyRanking(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	return cached(ctx, c, c.ranks, rankHistoryKey{method: "daily", domain: domain, rollup: rollup, start: start, end: end}, func() ([]model.DailyRank, error) {
+		return c.next.GetDailyRanking(ctx, domain, rollup, start, end)
+	})
+}
+
+func (c *CachedRankHistoryInteractor) GetDailyRankingWithGaps(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time, fill model.FillMode) ([]model.DailyRankEntry, error) {
+	return cached(ctx, c, c.entries, rankHistoryKey{method: "daily_with_gaps", domain: domain, rollup: rollup, start: start, end: end, fill: fill}, func() ([]model.DailyRankEntry, error) {
+		return c.next.GetDailyRankingWithGaps(ctx, domain, rollup, start, end, fill)
+	})
+}
+
+func (c *CachedRankHistoryInteractor) GetMonthlyRanking(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	return cached(ctx, c, c.ranks, rankHistoryKey{method: "monthly", domain: domain, rollup: rollup, start: start, end: end}, func() ([]model.DailyRank, error) {
+		return c.next.GetMonthlyRanking(ctx, domain, rollup, start, end)
+	})
+}
+
+// cached returns the cached result of key, or calls load and caches its result unless it fails.
+// Results are copied so that callers cannot change what is cached.
+func cached[T any](ctx context.Context, c *CachedRankHistoryInteractor, lru *cache.LRU[rankHistoryKey, []T], key rankHistoryKey, load func() ([]T, error)) ([]T, error) {
+	version, ok := c.checkVersion(ctx)
+	if !ok {
+		c.observer.Miss(key.method)
+		return load()
+	}
+	key.version = version
+
+	if v, ok := lru.Get(key); ok {
+		c.observer.Hit(key.method)
+		return slices.Clone(v), nil
+	}
+	c.observer.Miss(key.method)
+
+	v, err := load()
+	if err != nil {
+		return nil, err
+	}
+	lru.Add(key, slices.Clone(v), c.ttl(key.end))
+	return v, nil
+}
+
+// ttl keeps results of ranges that ended before today longer, as only a new list can change them.
+func (c *CachedRankHistoryInteractor) ttl(end time.Time) time.Duration {
+	today := c.now().UTC().Truncate(24 * time.Hour)
+	if end.Before(today) {
+		return c.opts.PastTTL
+	}
+	return c.opts.TTL
+}
+
+// checkVersion returns the version of the lists, dropping the cache when a list was saved or deleted since the last check.
+// It reports false when the version cannot be looked up, in which case the cache must not be used.
+// The version is looked up without holding c.mu, so that a slow lookup does not block cache hits. The lookup is shared
+// by the callers waiting for it, so it is not canceled with the request that started it.
+func (c *CachedRankHistoryInteractor) checkVersion(ctx context.Context) (string, bool) {
+	c.mu.Lock()
+	version, checkedAt := c.version, c.checkedAt
+	c.mu.Unlock()
+
+	now := c.now()
+	if !checkedAt.IsZero() && now.Sub(checkedAt) < c.opts.VersionCheckInterval {
+		return version, true
+	}
+
+	v, err, _ := c.lookups.Do("latest", func() (any, error) {
+		version, err := c.list.FindVersion(context.WithoutCancel(ctx))
+		if err != nil {
+			return "", err
+		}
+		c.swapVersion(ctx, version, now)
+		return version, nil
+	})
+	if err != nil {
+		util.Logger(ctx).WithFields(log.Fields{"error": err}).Warn("failed to find version of lists, bypassing rank history cache")
+		return "", false
+	}
+	return v.(string), true
+}
+
+// swapVersion records version as the current version, dropping the cache when it changed.
+func (c *CachedRankHistoryInteractor) swapVersion(ctx context.Context, version string, checkedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if version != c.version {
+		if c.version != "" {
+			util.Logger(ctx).WithFields(log.Fields{"from": c.version, "to": version}).Info("lists changed, dropping rank history cache")
+		}
+		c.ranks.Purge()
+		c.entries.Purge()
+		c.version = version
+	}
+	c.checkedAt = checkedAt
+}
@@ -0,0 +1,262 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+type countingRankHistory struct {
+	calls int
+	ranks []model.DailyRank
+	err   error
+}
+
+func (c *countingRankHistory) GetDailyRanking(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	c.calls++
+	return c.ranks, c.err
+}
+
+func (c *countingRankHistory) GetDailyRankingWithGaps(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time, fill model.FillMode) ([]model.DailyRankEntry, error) {
+	c.calls++
+	return []model.DailyRankEntry{{Date: start, Status: model.RankStatusNotRanked}}, c.err
+}
+
+func (c *countingRankHistory) GetMonthlyRanking(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	c.calls++
+	return c.ranks, c.err
+}
+
+type recordingObserver struct {
+	hits, misses int
+}
+
+func (o *recordingObserver) Hit(method string)  { o.hits++ }
+func (o *recordingObserver) Miss(method string) { o.misses++ }
+
+type mockListRepoForCache struct {
+	MockTrancoListsRepository
+	version string
+	err     error
+	calls   int
+}
+
+func (m *mockListRepoForCache) FindVersion(ctx context.Context) (string, error) {
+	m.calls++
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return m.version, m.err
+}
+
+type cacheFixture struct {
+	next     *countingRankHistory
+	list     *mockListRepoForCache
+	observer *recordingObserver
+	now      time.Time
+	cache    *CachedRankHistoryInteractor
+}
+
+func newCacheFixture() *cacheFixture {
+	f := &cacheFixture{
+		next:     &countingRankHistory{ranks: []model.DailyRank{{Rank: 1, Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}}},
+		list:     &mockListRepoForCache{version: "L1"},
+		observer: &recordingObserver{},
+		now:      time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC),
+	}
+	f.cache = newCachedRankHistoryInteractor(f.next, f.list, RankHistoryCacheOptions{
+		Size:                 10,
+		TTL:                  5 * time.Minute,
+		PastTTL:              24 * time.Hour,
+		VersionCheckInterval: time.Minute,
+	}, f.observer, func() time.Time { return f.now })
+	return f
+}
+
+var (
+	pastStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	pastEnd   = time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)
+	today     = time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+)
+
+func TestCachedRankHistoryInteractor_HitAndMiss(t *testing.T) {
+	f := newCacheFixture()
+	ctx := context.Background()
+
+	first, err := f.cache.GetDailyRanking(ctx, "example.com", model.RollupNone, pastStart, pastEnd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, _ := f.cache.GetDailyRanking(ctx, "example.com", model.RollupNone, pastStart, pastEnd)
+	_, _ = f.cache.GetDailyRanking(ctx, "example.org", model.RollupNone, pastStart, pastEnd)
+	_, _ = f.cache.GetMonthlyRanking(ctx, "example.com", model.RollupNone, pastStart, pastEnd)
+	_, _ = f.cache.GetDailyRankingWithGaps(ctx, "example.com", model.RollupNone, pastStart, pastEnd, model.FillNull)
+	_, _ = f.cache.GetDailyRankingWithGaps(ctx, "example.com", model.RollupNone, pastStart, pastEnd, model.FillNull)
+
+	if diff := cmp.Diff(first, second); diff != "" {
+		t.Errorf("cached result differs:\n%s", diff)
+	}
+	if f.next.calls != 4 {
+		t.Errorf("expected 4 loads, got %d", f.next.calls)
+	}
+	if f.observer.hits != 2 || f.observer.misses != 4 {
+		t.Errorf("expected 2 hits and 4 misses, got %d and %d", f.observer.hits, f.observer.misses)
+	}
+
+	// callers must not be able to change the cached result.
+	first[0].Rank = 100
+	third, _ := f.cache.GetDailyRanking(ctx, "example.com", model.RollupNone, pastStart, pastEnd)
+	if third[0].Rank != 1 {
+		t.Errorf("cached result was modified: %v", third)
+	}
+}
+
+func TestCachedRankHistoryInteractor_TTL(t *testing.T) {
+	f := newCacheFixture()
+	ctx := context.Background()
+
+	_, _ = f.cache.GetDailyRanking(ctx, "example.com", model.RollupNone, pastStart, pastEnd)
+	_, _ = f.cache.GetDailyRanking(ctx, "example.com", model.RollupNone, pastStart, today)
+
+	// after the short TTL only the past range is still cached.
+	f.now = f.now.Add(10 * time.Minute)
+	_, _ = f.cache.GetDailyRanking(ctx, "example.com", model.RollupNone, pastStart, pastEnd)
+	_, _ = f.cache.GetDailyRanking(ctx, "example.com", model.RollupNone, pastStart, today)
+	if f.next.calls != 3 {
+		t.Errorf("expected 3 loads, got %d", f.next.calls)
+	}
+
+	f.now = f.now.Add(24 * time.Hour)
+	_, _ = f.cache.GetDailyRanking(ctx, "example.com", model.RollupNone, pastStart, pastEnd)
+	if f.next.calls != 4 {
+		t.Errorf("expected past range to expire after the long TTL, got %d loads", f.next.calls)
+	}
+}
+
+func TestCachedRankHistoryInteractor_ChangedListsDropCache(t *testing.T) {
+	f := newCacheFixture()
+	ctx := context.Background()
+
+	_, _ = f.cache.GetDailyRanking(ctx, "example.com", model.RollupNone, pastStart, pastEnd)
+
+	// a saved or deleted list is not noticed until the version is checked again.
+	f.list.version = "L2"
+	_, _ = f.cache.GetDailyRanking(ctx, "example.com", model.RollupNone, pastStart, pastEnd)
+	if f.next.calls != 1 {
+		t.Errorf("expected cache hit before version check, got %d loads", f.next.calls)
+	}
+
+	f.now = f.now.Add(time.Minute)
+	_, _ = f.cache.GetDailyRanking(ctx, "example.com", model.RollupNone, pastStart, pastEnd)
+	if f.next.calls != 2 {
+		t.Errorf("expected reload after the lists changed, got %d loads", f.next.calls)
+	}
+	if f.list.calls != 2 {
+		t.Errorf("expected 2 version checks, got %d", f.list.calls)
+	}
+}
+
+func TestCachedRankHistoryInteractor_Errors(t *testing.T) {
+	t.Run("load errors are not cached", func(t *testing.T) {
+		f := newCacheFixture()
+		f.next.err = errors.New("db down")
+
+		for i := 0; i < 2; i++ {
+			if _, err := f.cache.GetDailyRanking(context.Background(), "example.com", model.RollupNone, pastStart, pastEnd); err == nil {
+				t.Fatal("expected error")
+			}
+		}
+		if f.next.calls != 2 {
+			t.Errorf("expected 2 loads, got %d", f.next.calls)
+		}
+	})
+
+	t.Run("canceled request still checks the version", func(t *testing.T) {
+		f := newCacheFixture()
+		// the version check is shared with other requests, so it must not fail with the request that started it
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, ok := f.cache.checkVersion(ctx); !ok {
+			t.Fatal("expected the version check to succeed")
+		}
+	})
+
+	t.Run("version check failure bypasses cache", func(t *testing.T) {
+		f := newCacheFixture()
+		f.list.err = errors.New("db down")
+
+		for i := 0; i < 2; i++ {
+			if _, err := f.cache.GetDailyRanking(context.Background(), "example.com", model.RollupNone, pastStart, pastEnd); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if f.next.calls != 2 || f.cache.Len() != 0 {
+			t.Errorf("expected cache to be bypassed, got %d loads and %d entries", f.next.calls, f.cache.Len())
+		}
+	})
+}
+
+type blockingListRepoForCache struct {
+	MockTrancoListsRepository
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (m *blockingListRepoForCache) FindVersion(ctx context.Context) (string, error) {
+	m.calls.Add(1)
+	<-m.release
+	return "L1", nil
+}
+
+func TestCachedRankHistoryInteractor_ConcurrentVersionCheck(t *testing.T) {
+	list := &blockingListRepoForCache{release: make(chan struct{})}
+	c := NewCachedRankHistoryInteractor(&countingRankHistory{}, list, RankHistoryCacheOptions{Size: 10, TTL: time.Minute, VersionCheckInterval: time.Minute}, &recordingObserver{})
+
+	var wg sync.WaitGroup
+	versions := make([]string, 5)
+	for i := range versions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			versions[i], _ = c.checkVersion(context.Background())
+		}()
+	}
+	// the cache lock must be free while the version is looked up
+	for list.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	c.mu.Lock()
+	c.mu.Unlock()
+	close(list.release)
+	wg.Wait()
+
+	for _, v := range versions {
+		if v != "L1" {
+			t.Errorf("expected version L1, got %q", v)
+		}
+	}
+	if calls := list.calls.Load(); calls > int32(len(versions)) {
+		t.Errorf("expected at most %d lookups, got %d", len(versions), calls)
+	}
+}
+
+func TestCachedRankHistoryInteractor_SplitsSize(t *testing.T) {
+	f := newCacheFixture()
+	ctx := context.Background()
+
+	for d := 1; d <= 10; d++ {
+		end := time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+		_, _ = f.cache.GetDailyRanking(ctx, "example.com", model.RollupNone, pastStart, end)
+		_, _ = f.cache.GetDailyRankingWithGaps(ctx, "example.com", model.RollupNone, pastStart, end, model.FillNull)
+	}
+	if got := f.cache.Len(); got != 10 {
+		t.Errorf("expected at most 10 cached results in total, got %d", got)
+	}
+}
@@ -10,46 +10,55 @@ import (
 
 	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
 	"github.com/shigaichi/top-sites-ranking-api/internal/domain/repository"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
 )
 
 type DeleteUseCase interface {
-	Delete(ctx context.Context, duration time.Duration) error
+	Delete(ctx context.Context, duration time.Duration) (int, error)
+	// Plan returns the lists that Delete would remove for duration, without deleting them.
+	Plan(ctx context.Context, duration time.Duration) ([]model.TrancoList, error)
+	// DeleteList removes the list id, its rankings and its month-end snapshot. It returns ErrListNotFound when there is no such list.
+	DeleteList(ctx context.Context, id string) error
 }
 
 type DeleteInteractor struct {
 	list    repository.TrancoListsRepository
 	ranking repository.TrancoRankingsRepository
+	monthly repository.TrancoMonthlyRankRepository
 }
 
-func NewDeleteInteractor(list repository.TrancoListsRepository, ranking repository.TrancoRankingsRepository) *DeleteInteractor {
-	return &DeleteInteractor{list: list, ranking: ranking}
+func NewDeleteInteractor(list repository.TrancoListsRepository, ranking repository.TrancoRankingsRepository, monthly repository.TrancoMonthlyRankRepository) *DeleteInteractor {
+	return &DeleteInteractor{list: list, ranking: ranking, monthly: monthly}
 }
 
 // Delete removes TrancoLists created before a given duration and their associated TrancoRankings if CreatedOn is not the end of the month.
-func (d DeleteInteractor) Delete(ctx context.Context, duration time.Duration) error {
-	cutoffDate := time.Now().Add(-duration)
+// Month-end lists missing from the monthly snapshot are copied into it first. It returns how many lists were deleted.
+func (d DeleteInteractor) Delete(ctx context.Context, duration time.Duration) (int, error) {
+	refreshed, err := d.monthly.RefreshMissing(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error refreshing monthly rankings: %w", err)
+	}
+	util.Logger(ctx).WithFields(log.Fields{"rows": refreshed}).Info("refreshed missing monthly rankings")
 
-	lists, err := d.list.FindByCreatedOnLessThan(ctx, cutoffDate)
+	lists, err := d.Plan(ctx, duration)
 	if err != nil {
-		return fmt.Errorf("error finding tranco lists: %w", err)
+		return 0, err
 	}
 
 	var wg sync.WaitGroup
 	errs := make(chan error, len(lists))
 
 	for _, list := range lists {
-		if !isEndOfMonth(list.CreatedOn) {
-			wg.Add(1)
-			go func(list model.TrancoList) {
-				defer wg.Done()
+		wg.Add(1)
+		go func(list model.TrancoList) {
+			defer wg.Done()
 
-				log.WithFields(log.Fields{"listID": list.ID, "CreatedOn": list.CreatedOn}).Info("delete list and ranking")
+			util.Logger(ctx).WithFields(log.Fields{"listID": list.ID, "CreatedOn": list.CreatedOn}).Info("delete list and ranking")
 
-				if err := d.deleteListAndRankings(ctx, list.ID); err != nil {
-					errs <- fmt.Errorf("error deleting list and rankings for list ID %s: %w", list.ID, err)
-				}
-			}(list)
-		}
+			if err := d.deleteListAndRankings(ctx, list.ID); err != nil {
+				errs <- fmt.Errorf("error deleting list and rankings for list ID %s: %w", list.ID, err)
+			}
+		}(list)
 	}
 
 	wg.Wait()
@@ -57,10 +66,44 @@ func (d DeleteInteractor) Delete(ctx context.Context, duration time.Duration) er
 
 	for err := range errs {
 		if err != nil {
-			return err
+			return 0, err
 		}
 	}
-	return nil
+	return len(lists), nil
+}
+
+func (d DeleteInteractor) Plan(ctx context.Context, duration time.Duration) ([]model.TrancoList, error) {
+	cutoffDate := time.Now().Add(-duration)
+
+	lists, err := d.list.FindByCreatedOnLessThan(ctx, cutoffDate)
+	if err != nil {
+		return nil, fmt.Errorf("error finding tranco lists: %w", err)
+	}
+
+	var expired []model.TrancoList
+	for _, list := range lists {
+		if !isEndOfMonth(list.CreatedOn) {
+			expired = append(expired, list)
+		}
+	}
+	return expired, nil
+}
+
+func (d DeleteInteractor) DeleteList(ctx context.Context, id string) error {
+	exists, err := d.list.ExistsID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error checking tranco list %s: %w", id, err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrListNotFound, id)
+	}
+
+	util.Logger(ctx).WithFields(log.Fields{"listID": id}).Info("delete list and ranking")
+	// unlike retention, deleting a single list also drops its month-end snapshot
+	if err := d.monthly.DeleteByListID(ctx, id); err != nil {
+		return fmt.Errorf("error deleting monthly rankings by list ID %s: %w", id, err)
+	}
+	return d.deleteListAndRankings(ctx, id)
 }
 
 func (d DeleteInteractor) deleteListAndRankings(ctx context.Context, listID string) error {
@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -14,12 +15,17 @@ type MockTrancoListsRepositoryForDelete struct {
 	infra.TrancoListRepositoryImpl
 	MockFindByCreatedOnLessThan func(ctx context.Context, date time.Time) ([]model.TrancoList, error)
 	MockDeleteById              func(ctx context.Context, id string) error
+	MockExistsID                func(ctx context.Context, id string) (bool, error)
 }
 
 func (m MockTrancoListsRepositoryForDelete) FindByCreatedOnLessThan(ctx context.Context, date time.Time) ([]model.TrancoList, error) {
 	return m.MockFindByCreatedOnLessThan(ctx, date)
 }
 
+func (m MockTrancoListsRepositoryForDelete) ExistsID(ctx context.Context, id string) (bool, error) {
+	return m.MockExistsID(ctx, id)
+}
+
 func (m MockTrancoListsRepositoryForDelete) DeleteByID(ctx context.Context, id string) error {
 	return m.MockDeleteById(ctx, id)
 }
@@ -33,12 +39,28 @@ func (m MockTrancoRankingsRepositoryForDelete) DeleteByListID(ctx context.Contex
 	return m.MockDeleteByListID(ctx, listID)
 }
 
+type MockTrancoMonthlyRankRepositoryForDelete struct {
+	infra.TrancoMonthlyRankRepositoryImpl
+	MockRefreshMissing func(ctx context.Context) (int, error)
+	MockDeleteByListID func(ctx context.Context, listID string) error
+}
+
+func (m MockTrancoMonthlyRankRepositoryForDelete) RefreshMissing(ctx context.Context) (int, error) {
+	return m.MockRefreshMissing(ctx)
+}
+
+func (m MockTrancoMonthlyRankRepositoryForDelete) DeleteByListID(ctx context.Context, listID string) error {
+	return m.MockDeleteByListID(ctx, listID)
+}
+
 func TestDeleteInteractor_Delete(t *testing.T) {
 	tests := []struct {
-		name      string
-		duration  time.Duration
-		setupMock func(*MockTrancoListsRepositoryForDelete, *MockTrancoRankingsRepositoryForDelete)
-		wantErr   bool
+		name        string
+		duration    time.Duration
+		setupMock   func(*MockTrancoListsRepositoryForDelete, *MockTrancoRankingsRepositoryForDelete)
+		refreshErr  error
+		wantDeleted int
+		wantErr     bool
 	}{
 		{
 			name:     "Successful deletion",
@@ -54,7 +76,8 @@ func TestDeleteInteractor_Delete(t *testing.T) {
 					return nil
 				}
 			},
-			wantErr: false,
+			wantDeleted: 1,
+			wantErr:     false,
 		},
 		{
 			name:     "No delete",
@@ -76,6 +99,13 @@ func TestDeleteInteractor_Delete(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:       "Error in refresh monthly rankings",
+			duration:   24 * time.Hour,
+			setupMock:  func(mList *MockTrancoListsRepositoryForDelete, mRankings *MockTrancoRankingsRepositoryForDelete) {},
+			refreshErr: errors.New("mock error"),
+			wantErr:    true,
+		},
 		{
 			name:     "Error in find list",
 			duration: 24 * time.Hour,
@@ -120,17 +150,97 @@ func TestDeleteInteractor_Delete(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockListRepo := &MockTrancoListsRepositoryForDelete{}
 			mockRankingRepo := &MockTrancoRankingsRepositoryForDelete{}
+			var refreshed bool
+			mockMonthlyRepo := &MockTrancoMonthlyRankRepositoryForDelete{
+				MockRefreshMissing: func(ctx context.Context) (int, error) {
+					refreshed = true
+					return 0, tt.refreshErr
+				},
+			}
 			tt.setupMock(mockListRepo, mockRankingRepo)
 
 			d := DeleteInteractor{
 				list:    mockListRepo,
 				ranking: mockRankingRepo,
+				monthly: mockMonthlyRepo,
 			}
 
-			err := d.Delete(context.Background(), tt.duration)
+			deleted, err := d.Delete(context.Background(), tt.duration)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Delete() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if deleted != tt.wantDeleted {
+				t.Errorf("Delete() deleted = %d, wantDeleted %d", deleted, tt.wantDeleted)
+			}
+			if !refreshed {
+				t.Error("Delete() did not refresh the monthly rankings")
+			}
+		})
+	}
+}
+
+func TestDeleteInteractor_DeleteList(t *testing.T) {
+	tests := []struct {
+		name        string
+		setupMock   func(*MockTrancoListsRepositoryForDelete, *MockTrancoRankingsRepositoryForDelete)
+		wantDeleted []string
+		wantErr     error
+	}{
+		{
+			name: "Successful deletion",
+			setupMock: func(mList *MockTrancoListsRepositoryForDelete, mRankings *MockTrancoRankingsRepositoryForDelete) {
+				mList.MockExistsID = func(ctx context.Context, id string) (bool, error) {
+					return true, nil
+				}
+			},
+			wantDeleted: []string{"monthly Q94V4", "rankings Q94V4", "list Q94V4"},
+		},
+		{
+			name: "List not found",
+			setupMock: func(mList *MockTrancoListsRepositoryForDelete, mRankings *MockTrancoRankingsRepositoryForDelete) {
+				mList.MockExistsID = func(ctx context.Context, id string) (bool, error) {
+					return false, nil
+				}
+			},
+			wantErr: ErrListNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deleted []string
+			mockListRepo := &MockTrancoListsRepositoryForDelete{
+				MockDeleteById: func(ctx context.Context, id string) error {
+					deleted = append(deleted, "list "+id)
+					return nil
+				},
+			}
+			mockRankingRepo := &MockTrancoRankingsRepositoryForDelete{
+				MockDeleteByListID: func(ctx context.Context, listID string) error {
+					deleted = append(deleted, "rankings "+listID)
+					return nil
+				},
+			}
+			mockMonthlyRepo := &MockTrancoMonthlyRankRepositoryForDelete{
+				MockDeleteByListID: func(ctx context.Context, listID string) error {
+					deleted = append(deleted, "monthly "+listID)
+					return nil
+				},
+			}
+			tt.setupMock(mockListRepo, mockRankingRepo)
+
+			d := DeleteInteractor{
+				list:    mockListRepo,
+				ranking: mockRankingRepo,
+				monthly: mockMonthlyRepo,
+			}
+
+			err := d.DeleteList(context.Background(), "Q94V4")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeleteList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(deleted, tt.wantDeleted) {
+				t.Errorf("DeleteList() deleted %v, want %v", deleted, tt.wantDeleted)
+			}
 		})
 	}
 }
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/repository"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+)
+
+type DomainNameUseCase interface {
+	// Normalize stores the registrable domain and TLD of every saved domain that lacks them and returns how many were updated.
+	Normalize(ctx context.Context) (int, error)
+}
+
+type DomainNameInteractor struct {
+	domain    repository.TrancoDomainsRepository
+	batchSize int
+}
+
+func NewDomainNameInteractor(domain repository.TrancoDomainsRepository, batchSize int) *DomainNameInteractor {
+	return &DomainNameInteractor{domain: domain, batchSize: batchSize}
+}
+
+func (d DomainNameInteractor) Normalize(ctx context.Context) (updated int, err error) {
+	ctx, span := tracer.Start(ctx, "domain_name.normalize")
+	defer func() { endSpan(span, err) }()
+
+	for {
+		domains, err := d.domain.FindUnparsed(ctx, d.batchSize)
+		if err != nil {
+			return updated, fmt.Errorf("failed to find domains to normalize: %w", err)
+		}
+		if len(domains) == 0 {
+			return updated, nil
+		}
+
+		names := make([]model.DomainName, len(domains))
+		for i, domain := range domains {
+			names[i] = model.ParseDomainName(domain)
+		}
+
+		n, err := d.domain.UpdateNames(ctx, names)
+		if err != nil {
+			return updated, fmt.Errorf("failed to normalize domains: %w", err)
+		}
+		if n == 0 {
+			// the same domains would be found again
+			return updated, fmt.Errorf("none of %d domains could be normalized", len(domains))
+		}
+		updated += n
+		util.Logger(ctx).WithFields(log.Fields{"updated": updated}).Info("normalized domains")
+	}
+}
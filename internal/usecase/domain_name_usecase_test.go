@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+type mockUnparsedDomains struct {
+	MockTrancoDomainsRepository
+	unparsed  []string
+	updateErr error
+	updated   []model.DomainName
+}
+
+func (m *mockUnparsedDomains) FindUnparsed(ctx context.Context, limit int) ([]string, error) {
+	return m.unparsed[:min(limit, len(m.unparsed))], nil
+}
+
+func (m *mockUnparsedDomains) UpdateNames(ctx context.Context, names []model.DomainName) (int, error) {
+	if m.updateErr != nil {
+		return 0, m.updateErr
+	}
+	m.updated = append(m.updated, names...)
+	m.unparsed = m.unparsed[len(names):]
+	return len(names), nil
+}
+
+func TestDomainNameInteractor_Normalize(t *testing.T) {
+	repo := &mockUnparsedDomains{unparsed: []string{"mail.google.com", "www.bbc.co.uk", "github.io", "localhost"}}
+
+	updated, err := NewDomainNameInteractor(repo, 3).Normalize(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated != 4 {
+		t.Errorf("expected 4 updated domains, got %d", updated)
+	}
+
+	want := []model.DomainName{
+		{Domain: "mail.google.com", Registrable: "google.com", TLD: "com"},
+		{Domain: "www.bbc.co.uk", Registrable: "bbc.co.uk", TLD: "uk"},
+		{Domain: "github.io", Registrable: "github.io", TLD: "io"},
+		{Domain: "localhost", Registrable: "localhost", TLD: "localhost"},
+	}
+	if diff := cmp.Diff(want, repo.updated); diff != "" {
+		t.Errorf("unexpected names (-want +got):\n%s", diff)
+	}
+}
+
+func TestDomainNameInteractor_NormalizeError(t *testing.T) {
+	repo := &mockUnparsedDomains{unparsed: []string{"google.com"}, updateErr: errors.New("mock error")}
+
+	_, err := NewDomainNameInteractor(repo, 10).Normalize(context.Background())
+	if err == nil || err.Error() != "failed to normalize domains: mock error" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
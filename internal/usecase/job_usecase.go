@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxJobs bounds how many jobs are remembered. The oldest finished jobs are forgotten first.
+const maxJobs = 100
+
+// ErrJobNotFound is returned for job IDs that are unknown or were forgotten.
+var ErrJobNotFound = errors.New("job not found")
+
+type JobUseCase interface {
+	// StartIngest queues the ingestion of the list of date.
+	StartIngest(ctx context.Context, date time.Time) (model.Job, error)
+	// StartRetention queues the deletion of the lists older than since. A dry run only reports the lists it would delete.
+	StartRetention(ctx context.Context, since time.Duration, dryRun bool) (model.Job, error)
+	// StartDeleteList queues the deletion of the list id.
+	StartDeleteList(ctx context.Context, id string) (model.Job, error)
+	Get(ctx context.Context, id string) (model.Job, error)
+}
+
+// JobInteractor runs admin jobs in the background of the api server, one at a time, so that ingestion
+// and deletion never touch the same lists concurrently. Jobs live in memory only and are lost on restart;
+// a job interrupted by a shutdown leaves the database as its use case leaves it on any other failure.
+type JobInteractor struct {
+	write WriteUseCase
+	del   DeleteUseCase
+	now   func() time.Time
+
+	// run serializes the jobs.
+	run sync.Mutex
+
+	mu    sync.Mutex
+	jobs  map[string]*model.Job
+	order []string
+	wg    sync.WaitGroup
+}
+
+func NewJobInteractor(write WriteUseCase, del DeleteUseCase) *JobInteractor {
+	return &JobInteractor{write: write, del: del, now: time.Now, jobs: map[string]*model.Job{}}
+}
+
+// jobResult is what a finished job reports.
+type jobResult struct {
+	rows    int
+	listIDs []string
+}
+
+func (j *JobInteractor) StartIngest(ctx context.Context, date time.Time) (model.Job, error) {
+	params := map[string]string{"date": date.Format("2006-01-02")}
+	return j.start(ctx, model.JobTypeIngest, params, func(ctx context.Context) (jobResult, error) {
+		written, err := j.write.Write(ctx, date)
+		return jobResult{rows: written}, err
+	})
+}
+
+func (j *JobInteractor) StartRetention(ctx context.Context, since time.Duration, dryRun bool) (model.Job, error) {
+	params := map[string]string{"since": since.String(), "dry_run": strconv.FormatBool(dryRun)}
+	return j.start(ctx, model.JobTypeRetention, params, func(ctx context.Context) (jobResult, error) {
+		if !dryRun {
+			deleted, err := j.del.Delete(ctx, since)
+			return jobResult{rows: deleted}, err
+		}
+
+		lists, err := j.del.Plan(ctx, since)
+		if err != nil {
+			return jobResult{}, err
+		}
+		ids := make([]string, len(lists))
+		for i, list := range lists {
+			ids[i] = list.ID
+		}
+		return jobResult{rows: len(lists), listIDs: ids}, nil
+	})
+}
+
+func (j *JobInteractor) StartDeleteList(ctx context.Context, id string) (model.Job, error) {
+	params := map[string]string{"list_id": id}
+	return j.start(ctx, model.JobTypeDeleteList, params, func(ctx context.Context) (jobResult, error) {
+		if err := j.del.DeleteList(ctx, id); err != nil {
+			return jobResult{}, err
+		}
+		return jobResult{rows: 1}, nil
+	})
+}
+
+func (j *JobInteractor) Get(ctx context.Context, id string) (model.Job, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	job, ok := j.jobs[id]
+	if !ok {
+		return model.Job{}, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	return cloneJob(job), nil
+}
+
+// Wait blocks until every started job has finished or ctx is done.
+func (j *JobInteractor) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// start queues fn as a job and runs it in the background.
+// The job keeps the values of ctx, such as the request logger, but is not canceled with it.
+func (j *JobInteractor) start(ctx context.Context, typ model.JobType, params map[string]string, fn func(ctx context.Context) (jobResult, error)) (model.Job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return model.Job{}, err
+	}
+	job := &model.Job{ID: id, Type: typ, Status: model.JobStatusQueued, Params: params, CreatedAt: j.now()}
+
+	j.mu.Lock()
+	j.jobs[id] = job
+	j.order = append(j.order, id)
+	j.forget()
+	queued := cloneJob(job)
+	j.mu.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+	ctx = util.WithLogger(ctx, util.Logger(ctx).WithFields(log.Fields{"job_id": id, "job_type": typ}))
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		j.run.Lock()
+		defer j.run.Unlock()
+
+		j.update(id, func(job *model.Job) {
+			job.Status = model.JobStatusRunning
+			job.StartedAt = j.now()
+		})
+		util.Logger(ctx).Info("admin job started")
+
+		result, err := fn(ctx)
+
+		j.update(id, func(job *model.Job) {
+			job.FinishedAt = j.now()
+			job.Rows = result.rows
+			job.ListIDs = result.listIDs
+			job.Status = model.JobStatusSucceeded
+			if err != nil {
+				job.Status = model.JobStatusFailed
+				job.Error = err.Error()
+			}
+		})
+		if err != nil {
+			util.Logger(ctx).WithFields(log.Fields{"error": err}).Error("admin job failed")
+			return
+		}
+		util.Logger(ctx).WithFields(log.Fields{"rows": result.rows}).Info("admin job succeeded")
+	}()
+
+	return queued, nil
+}
+
+func (j *JobInteractor) update(id string, fn func(job *model.Job)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if job, ok := j.jobs[id]; ok {
+		fn(job)
+	}
+}
+
+// forget drops the oldest finished jobs beyond maxJobs. Unfinished jobs are always kept.
+func (j *JobInteractor) forget() {
+	for i := 0; len(j.order) > maxJobs && i < len(j.order); {
+		id := j.order[i]
+		if !j.jobs[id].Finished() {
+			i++
+			continue
+		}
+		delete(j.jobs, id)
+		j.order = slices.Delete(j.order, i, i+1)
+	}
+}
+
+func cloneJob(job *model.Job) model.Job {
+	c := *job
+	c.ListIDs = slices.Clone(job.ListIDs)
+	return c
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
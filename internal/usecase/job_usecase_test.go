@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+type writeUseCaseStub struct {
+	write func(ctx context.Context, date time.Time) (int, error)
+}
+
+func (s writeUseCaseStub) Write(ctx context.Context, date time.Time) (int, error) {
+	return s.write(ctx, date)
+}
+
+type deleteUseCaseStub struct {
+	DeleteUseCase
+	plan       func(ctx context.Context, duration time.Duration) ([]model.TrancoList, error)
+	deleteList func(ctx context.Context, id string) error
+}
+
+func (s deleteUseCaseStub) Plan(ctx context.Context, duration time.Duration) ([]model.TrancoList, error) {
+	return s.plan(ctx, duration)
+}
+
+func (s deleteUseCaseStub) DeleteList(ctx context.Context, id string) error {
+	return s.deleteList(ctx, id)
+}
+
+func waitJobs(t *testing.T, j *JobInteractor) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := j.Wait(ctx); err != nil {
+		t.Fatalf("jobs did not finish: %v", err)
+	}
+}
+
+func TestJobInteractor_Ingest(t *testing.T) {
+	release := make(chan struct{})
+	write := writeUseCaseStub{write: func(ctx context.Context, date time.Time) (int, error) {
+		<-release
+		if date.Day() == 2 {
+			return 0, errors.New("mock error")
+		}
+		return 1000, nil
+	}}
+	j := NewJobInteractor(write, nil)
+
+	// the request context is canceled once the response is written, the job must not be
+	ctx, cancel := context.WithCancel(context.Background())
+	ok, err := j.StartIngest(ctx, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	failing, err := j.StartIngest(ctx, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	if ok.Status != model.JobStatusQueued || ok.Type != model.JobTypeIngest {
+		t.Errorf("expected a queued ingest job, got %s %s", ok.Status, ok.Type)
+	}
+	if diff := cmp.Diff(map[string]string{"date": "2024-01-01"}, ok.Params); diff != "" {
+		t.Errorf("params mismatch (-want +got):\n%s", diff)
+	}
+
+	close(release)
+	waitJobs(t, j)
+
+	got, err := j.Get(context.Background(), ok.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != model.JobStatusSucceeded || got.Rows != 1000 || got.StartedAt.IsZero() || got.FinishedAt.IsZero() {
+		t.Errorf("expected a finished job with 1000 rows, got %+v", got)
+	}
+
+	got, err = j.Get(context.Background(), failing.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != model.JobStatusFailed || got.Error != "mock error" {
+		t.Errorf("expected a failed job, got %+v", got)
+	}
+}
+
+func TestJobInteractor_RunsOneJobAtATime(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	write := writeUseCaseStub{write: func(ctx context.Context, date time.Time) (int, error) {
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return 0, nil
+	}}
+	del := deleteUseCaseStub{deleteList: func(ctx context.Context, id string) error {
+		_, err := write.Write(ctx, time.Time{})
+		return err
+	}}
+	j := NewJobInteractor(write, del)
+
+	for i := 0; i < 5; i++ {
+		if _, err := j.StartIngest(context.Background(), time.Date(2024, 1, i+1, 0, 0, 0, 0, time.UTC)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := j.StartDeleteList(context.Background(), "Q94V4"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitJobs(t, j)
+
+	if maxRunning != 1 {
+		t.Errorf("expected jobs to run one at a time, %d ran concurrently", maxRunning)
+	}
+}
+
+func TestJobInteractor_Retention(t *testing.T) {
+	del := deleteUseCaseStub{
+		plan: func(ctx context.Context, duration time.Duration) ([]model.TrancoList, error) {
+			if duration != 30*24*time.Hour {
+				return nil, fmt.Errorf("unexpected duration %s", duration)
+			}
+			return []model.TrancoList{{ID: "Q94V4"}, {ID: "X5Y7N"}}, nil
+		},
+	}
+	j := NewJobInteractor(nil, del)
+
+	job, err := j.StartRetention(context.Background(), 30*24*time.Hour, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]string{"since": "720h0m0s", "dry_run": "true"}, job.Params); diff != "" {
+		t.Errorf("params mismatch (-want +got):\n%s", diff)
+	}
+	waitJobs(t, j)
+
+	got, err := j.Get(context.Background(), job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != model.JobStatusSucceeded || got.Rows != 2 {
+		t.Errorf("expected a dry run planning 2 lists, got %+v", got)
+	}
+	if diff := cmp.Diff([]string{"Q94V4", "X5Y7N"}, got.ListIDs); diff != "" {
+		t.Errorf("list ids mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestJobInteractor_Get(t *testing.T) {
+	write := writeUseCaseStub{write: func(ctx context.Context, date time.Time) (int, error) { return 0, nil }}
+	j := NewJobInteractor(write, nil)
+
+	if _, err := j.Get(context.Background(), "unknown"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("expected ErrJobNotFound, got %v", err)
+	}
+
+	first, err := j.StartIngest(context.Background(), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitJobs(t, j)
+	for i := 0; i < maxJobs; i++ {
+		if _, err := j.StartIngest(context.Background(), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitJobs(t, j)
+
+	if _, err := j.Get(context.Background(), first.ID); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("expected the oldest finished job to be forgotten, got %v", err)
+	}
+}
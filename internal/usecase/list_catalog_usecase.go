@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/repository"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ListCatalogUseCase tells clients which days have a stored list.
+type ListCatalogUseCase interface {
+	// GetLists returns the lists created from start to the end of the end date with their row counts, oldest first.
+	GetLists(ctx context.Context, start time.Time, end time.Time) ([]model.TrancoListSummary, error)
+	// GetLatestList returns the most recently created list. It returns ErrListNotFound when no list is stored.
+	GetLatestList(ctx context.Context) (model.TrancoListSummary, error)
+}
+
+type ListCatalogInteractor struct {
+	list repository.TrancoListsRepository
+}
+
+func NewListCatalogInteractor(list repository.TrancoListsRepository) *ListCatalogInteractor {
+	return &ListCatalogInteractor{list: list}
+}
+
+func (l ListCatalogInteractor) GetLists(ctx context.Context, start time.Time, end time.Time) (lists []model.TrancoListSummary, err error) {
+	ctx, span := tracer.Start(ctx, "list_catalog.get_lists", trace.WithAttributes(
+		attribute.String("start", start.Format("2006-01-02")),
+		attribute.String("end", end.Format("2006-01-02")),
+	))
+	defer func() {
+		span.SetAttributes(attribute.Int("rows", len(lists)))
+		endSpan(span, err)
+	}()
+
+	lists, err = l.list.FindSummariesByCreatedOnBetween(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find lists: %w", err)
+	}
+	return lists, nil
+}
+
+func (l ListCatalogInteractor) GetLatestList(ctx context.Context) (list model.TrancoListSummary, err error) {
+	ctx, span := tracer.Start(ctx, "list_catalog.get_latest_list")
+	defer func() {
+		span.SetAttributes(attribute.String("list_id", list.ID))
+		endSpan(span, err)
+	}()
+
+	list, err = l.list.FindLatestSummary(ctx)
+	if err != nil {
+		return model.TrancoListSummary{}, fmt.Errorf("failed to find latest list: %w", err)
+	}
+	if list.ID == "" {
+		return model.TrancoListSummary{}, ErrListNotFound
+	}
+	return list, nil
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+type mockListRepoForCatalog struct {
+	MockTrancoListsRepository
+	latest model.TrancoListSummary
+	err    error
+}
+
+func (m *mockListRepoForCatalog) FindLatestSummary(ctx context.Context) (model.TrancoListSummary, error) {
+	return m.latest, m.err
+}
+
+func TestListCatalogInteractor_GetLatestList(t *testing.T) {
+	latest := model.TrancoListSummary{TrancoList: model.TrancoList{ID: "X5Y7N", CreatedOn: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)}, Rows: 1000000}
+
+	tests := []struct {
+		name    string
+		repo    *mockListRepoForCatalog
+		want    model.TrancoListSummary
+		wantErr error
+	}{
+		{name: "latest list", repo: &mockListRepoForCatalog{latest: latest}, want: latest},
+		{name: "no list", repo: &mockListRepoForCatalog{}, wantErr: ErrListNotFound},
+		{name: "database error", repo: &mockListRepoForCatalog{err: errors.New("mock error")}, wantErr: errors.New("mock error")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewListCatalogInteractor(tt.repo).GetLatestList(context.Background())
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("GetLatestList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(tt.wantErr, ErrListNotFound) && !errors.Is(err, ErrListNotFound) {
+				t.Errorf("expected ErrListNotFound, got %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("list mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
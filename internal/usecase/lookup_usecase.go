@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/repository"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LookupUseCase looks up the ranks of many domains on one day.
+type LookupUseCase interface {
+	// FindList returns the list of date. It returns ErrListNotFound when there is none.
+	FindList(ctx context.Context, date time.Time) (model.TrancoList, error)
+	// Lookup looks up domains in list one batch at a time and passes the ranks of every batch to emit,
+	// in the order of domains. It stops at the first error of a query or of emit.
+	Lookup(ctx context.Context, list model.TrancoList, domains []string, emit func(ranks []model.DomainRank) error) error
+}
+
+// LookupBatchSize is how many domains are looked up by one query.
+type LookupBatchSize int
+
+type LookupInteractor struct {
+	list      repository.TrancoListsRepository
+	rank      repository.TrancoDailyRankRepository
+	batchSize int
+}
+
+func NewLookupInteractor(list repository.TrancoListsRepository, rank repository.TrancoDailyRankRepository, batchSize LookupBatchSize) *LookupInteractor {
+	return &LookupInteractor{list: list, rank: rank, batchSize: int(batchSize)}
+}
+
+func (l LookupInteractor) FindList(ctx context.Context, date time.Time) (model.TrancoList, error) {
+	lists, err := l.list.FindByCreatedOnBetween(ctx, date, date)
+	if err != nil {
+		return model.TrancoList{}, fmt.Errorf("failed to get list of %s: %w", date.Format("2006-01-02"), err)
+	}
+	if len(lists) == 0 {
+		return model.TrancoList{}, fmt.Errorf("%w: %s", ErrListNotFound, date.Format("2006-01-02"))
+	}
+	return lists[0], nil
+}
+
+func (l LookupInteractor) Lookup(ctx context.Context, list model.TrancoList, domains []string, emit func(ranks []model.DomainRank) error) (err error) {
+	ctx, span := tracer.Start(ctx, "lookup.lookup", trace.WithAttributes(
+		attribute.String("list_id", list.ID),
+		attribute.Int("domains", len(domains)),
+	))
+	defer func() { endSpan(span, err) }()
+
+	for start := 0; start < len(domains); start += l.batchSize {
+		batch := domains[start:min(start+l.batchSize, len(domains))]
+
+		// Tranco lists lowercase domains, so the lookup is case-insensitive while the response keeps the domains as given.
+		keys := make([]string, len(batch))
+		for i, domain := range batch {
+			keys[i] = normalizeDomain(domain)
+		}
+
+		found, err := l.rank.GetRanksByDomains(ctx, list.ID, keys)
+		if err != nil {
+			return fmt.Errorf("failed to look up domains %d to %d: %w", start, start+len(batch), err)
+		}
+		ranks := make(map[string]int, len(found))
+		for _, r := range found {
+			ranks[r.Domain] = r.Rank
+		}
+
+		result := make([]model.DomainRank, len(batch))
+		for i, domain := range batch {
+			result[i] = model.DomainRank{Domain: domain}
+			if rank, ok := ranks[keys[i]]; ok {
+				result[i].Rank = &rank
+			}
+		}
+		if err := emit(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
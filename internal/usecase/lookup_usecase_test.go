@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+type mockRepoForLookup struct {
+	mockRepo
+	ranks   map[string]int
+	queries [][]string
+	err     error
+}
+
+func (m *mockRepoForLookup) GetRanksByDomains(ctx context.Context, listID string, domains []string) ([]model.SiteRanking, error) {
+	if listID != "X5Y7N" {
+		return nil, errors.New("unexpected list")
+	}
+	m.queries = append(m.queries, domains)
+	var found []model.SiteRanking
+	for _, domain := range domains {
+		if rank, ok := m.ranks[domain]; ok {
+			found = append(found, model.SiteRanking{Domain: domain, Rank: rank})
+		}
+	}
+	return found, m.err
+}
+
+func intPtr(i int) *int { return &i }
+
+func TestLookupInteractor_Lookup(t *testing.T) {
+	repo := &mockRepoForLookup{ranks: map[string]int{"google.com": 1, "example.com": 300}}
+	l := NewLookupInteractor(nil, repo, 2)
+
+	var got []model.DomainRank
+	batches := 0
+	err := l.Lookup(context.Background(), model.TrancoList{ID: "X5Y7N"}, []string{"Google.com.", "unranked.test", "example.com"}, func(ranks []model.DomainRank) error {
+		batches++
+		got = append(got, ranks...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []model.DomainRank{
+		{Domain: "Google.com.", Rank: intPtr(1)},
+		{Domain: "unranked.test"},
+		{Domain: "example.com", Rank: intPtr(300)},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ranks mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([][]string{{"google.com", "unranked.test"}, {"example.com"}}, repo.queries); diff != "" {
+		t.Errorf("queries mismatch (-want +got):\n%s", diff)
+	}
+	if batches != 2 {
+		t.Errorf("expected 2 batches, got %d", batches)
+	}
+}
+
+func TestLookupInteractor_LookupStopsOnError(t *testing.T) {
+	repo := &mockRepoForLookup{err: errors.New("mock error")}
+	l := NewLookupInteractor(nil, repo, 1)
+
+	err := l.Lookup(context.Background(), model.TrancoList{ID: "X5Y7N"}, []string{"a.test", "b.test"}, func(ranks []model.DomainRank) error {
+		t.Error("expected nothing to be emitted")
+		return nil
+	})
+	if err == nil {
+		t.Error("expected an error")
+	}
+	if len(repo.queries) != 1 {
+		t.Errorf("expected to stop after the failed query, got %d queries", len(repo.queries))
+	}
+}
+
+func TestLookupInteractor_FindList(t *testing.T) {
+	date := time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)
+
+	l := NewLookupInteractor(&mockListRepo{lists: []model.TrancoList{{ID: "X5Y7N", CreatedOn: date}}}, nil, 1)
+	list, err := l.FindList(context.Background(), date)
+	if err != nil || list.ID != "X5Y7N" {
+		t.Errorf("expected list X5Y7N, got %v %v", list, err)
+	}
+
+	l = NewLookupInteractor(&mockListRepo{}, nil, 1)
+	if _, err := l.FindList(context.Background(), date); !errors.Is(err, ErrListNotFound) {
+		t.Errorf("expected ErrListNotFound, got %v", err)
+	}
+}
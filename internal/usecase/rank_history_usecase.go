@@ -3,51 +3,224 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
 	"github.com/shigaichi/top-sites-ranking-api/internal/domain/repository"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// RankHistoryUseCase reads the rank history of a domain. With model.RollupRegistrable, the history is that of the
+// registrable domain of domain, taking the best rank among all of its subdomains on each day.
 type RankHistoryUseCase interface {
-	GetDailyRanking(ctx context.Context, domain string, start time.Time, end time.Time) ([]model.DailyRank, error)
-	GetMonthlyRanking(ctx context.Context, domain string, start time.Time, end time.Time) ([]model.DailyRank, error)
+	GetDailyRanking(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time) ([]model.DailyRank, error)
+	GetDailyRankingWithGaps(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time, fill model.FillMode) ([]model.DailyRankEntry, error)
+	GetMonthlyRanking(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time) ([]model.DailyRank, error)
 }
 
 type RankHistoryInteractor struct {
-	repo repository.TrancoDailyRankRepository
+	repo    repository.TrancoDailyRankRepository
+	list    repository.TrancoListsRepository
+	monthly repository.TrancoMonthlyRankRepository
 }
 
-func NewRankHistoryInteractor(repo repository.TrancoDailyRankRepository) *RankHistoryInteractor {
-	return &RankHistoryInteractor{repo: repo}
+func NewRankHistoryInteractor(repo repository.TrancoDailyRankRepository, list repository.TrancoListsRepository, monthly repository.TrancoMonthlyRankRepository) *RankHistoryInteractor {
+	return &RankHistoryInteractor{repo: repo, list: list, monthly: monthly}
 }
 
-func (r RankHistoryInteractor) GetDailyRanking(ctx context.Context, domain string, start time.Time, end time.Time) ([]model.DailyRank, error) {
-	ranks, err := r.repo.GetDailyRanksByDateRange(ctx, domain, start, end)
+func (r RankHistoryInteractor) GetDailyRanking(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time) (ranks []model.DailyRank, err error) {
+	ctx, span := startRankHistorySpan(ctx, "rank_history.get_daily_ranking", domain, rollup, start, end)
+	defer func() {
+		span.SetAttributes(attribute.Int("rows", len(ranks)))
+		endSpan(span, err)
+	}()
+
+	ranks, err = r.dailyRanks(ctx, domain, rollup, start, end)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get daily ranks: %w", err)
 	}
 	return ranks, nil
 }
 
-func (r RankHistoryInteractor) GetMonthlyRanking(ctx context.Context, domain string, start time.Time, end time.Time) ([]model.DailyRank, error) {
-	ranks, err := r.repo.GetDailyRanksByDateRange(ctx, domain, start, end)
+// dailyRanks returns the ranks of domain, or of its registrable domain when rollup asks for it.
+func (r RankHistoryInteractor) dailyRanks(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	if rollup == model.RollupRegistrable {
+		return r.repo.GetRegistrableDailyRanksByDateRange(ctx, model.ParseDomainName(domain).Registrable, start, end)
+	}
+	return r.repo.GetDailyRanksByDateRange(ctx, domain, start, end)
+}
+
+func startRankHistorySpan(ctx context.Context, name string, domain string, rollup model.Rollup, start time.Time, end time.Time) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("domain", domain),
+		attribute.String("start_date", start.Format("2006-01-02")),
+		attribute.String("end_date", end.Format("2006-01-02")),
+	}
+	if rollup != model.RollupNone {
+		attrs = append(attrs, attribute.String("rollup", string(rollup)))
+	}
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// GetDailyRankingWithGaps returns one entry per day from start to end, newest first.
+// Days without a rank are marked as not ranked or not ingested by consulting the stored lists,
+// and their rank is filled according to fill.
+func (r RankHistoryInteractor) GetDailyRankingWithGaps(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time, fill model.FillMode) (entries []model.DailyRankEntry, err error) {
+	ctx, span := startRankHistorySpan(ctx, "rank_history.get_daily_ranking_with_gaps", domain, rollup, start, end)
+	span.SetAttributes(attribute.String("fill", string(fill)))
+	defer func() {
+		span.SetAttributes(attribute.Int("rows", len(entries)))
+		endSpan(span, err)
+	}()
+
+	ranks, err := r.dailyRanks(ctx, domain, rollup, start, end)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get daily ranks: %w", err)
 	}
 
-	var monthlyRanks []model.DailyRank
+	lists, err := r.list.FindByCreatedOnBetween(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lists: %w", err)
+	}
+
+	return fillDailyRanks(start, end, ranks, lists, fill), nil
+}
+
+func (r RankHistoryInteractor) GetMonthlyRanking(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time) (monthlyRanks []model.DailyRank, err error) {
+	ctx, span := startRankHistorySpan(ctx, "rank_history.get_monthly_ranking", domain, rollup, start, end)
+	defer func() {
+		span.SetAttributes(attribute.Int("rows", len(monthlyRanks)))
+		endSpan(span, err)
+	}()
+
+	// month ends are read from the snapshot, which outlives the daily rankings
+	if rollup == model.RollupRegistrable {
+		monthlyRanks, err = r.monthly.GetRegistrableMonthlyRanksByDateRange(ctx, model.ParseDomainName(domain).Registrable, start, end)
+	} else {
+		monthlyRanks, err = r.monthly.GetMonthlyRanksByDateRange(ctx, domain, start, end)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monthly ranks: %w", err)
+	}
+
+	return monthlyRanks, nil
+}
+
+// RefreshMonthly copies the rankings of the month-end lists created from start to the end of the end date into the
+// monthly snapshot and returns how many lists were copied. It backfills lists saved before the snapshot existed.
+func (r RankHistoryInteractor) RefreshMonthly(ctx context.Context, start time.Time, end time.Time) (refreshed int, err error) {
+	ctx, span := tracer.Start(ctx, "rank_history.refresh_monthly", trace.WithAttributes(
+		attribute.String("start_date", start.Format("2006-01-02")),
+		attribute.String("end_date", end.Format("2006-01-02")),
+	))
+	defer func() {
+		span.SetAttributes(attribute.Int("rows", refreshed))
+		endSpan(span, err)
+	}()
+
+	lists, err := r.list.FindByCreatedOnBetween(ctx, start, end)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get lists: %w", err)
+	}
+
+	for _, list := range lists {
+		if !isLastDayOfMonth(list.CreatedOn) {
+			continue
+		}
+		if err := r.monthly.Refresh(ctx, list.ID); err != nil {
+			return refreshed, fmt.Errorf("failed to refresh monthly rankings of list %s: %w", list.ID, err)
+		}
+		refreshed++
+		util.Logger(ctx).WithFields(log.Fields{"list_id": list.ID, "created_on": list.CreatedOn}).Info("refreshed monthly rankings")
+	}
+	return refreshed, nil
+}
+
+// fillDailyRanks builds an entry for every day between start and end, newest first.
+func fillDailyRanks(start, end time.Time, ranks []model.DailyRank, lists []model.TrancoList, fill model.FillMode) []model.DailyRankEntry {
+	ranked := make(map[string]int, len(ranks))
+	ingested := make(map[string]string, len(lists)+len(ranks))
 	for _, rank := range ranks {
-		if isLastDayOfMonth(rank.Date) {
-			monthlyRanks = append(monthlyRanks, rank)
+		ranked[dayKey(rank.Date)] = rank.Rank
+		ingested[dayKey(rank.Date)] = rank.ListID
+	}
+	for _, list := range lists {
+		ingested[dayKey(list.CreatedOn)] = list.ID
+	}
+
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	last := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+
+	// entries are built oldest first so that fill modes can look backwards and forwards.
+	var entries []model.DailyRankEntry
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		listID, isIngested := ingested[dayKey(day)]
+		entry := model.DailyRankEntry{Date: day, ListID: listID}
+		if rank, ok := ranked[dayKey(day)]; ok {
+			entry.Rank = &rank
+			entry.Status = model.RankStatusRanked
+		} else if isIngested {
+			entry.Status = model.RankStatusNotRanked
+		} else {
+			entry.Status = model.RankStatusNotIngested
 		}
+		entries = append(entries, entry)
 	}
 
-	return monthlyRanks, nil
+	switch fill {
+	case model.FillPrevious:
+		fillPrevious(entries)
+	case model.FillInterpolate:
+		fillInterpolate(entries)
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries
+}
+
+// fillPrevious carries the last known rank forward. Days before the first ranked day stay empty.
+func fillPrevious(entries []model.DailyRankEntry) {
+	var previous *int
+	for i := range entries {
+		if entries[i].Rank != nil {
+			previous = entries[i].Rank
+			continue
+		}
+		if previous != nil {
+			rank := *previous
+			entries[i].Rank = &rank
+			entries[i].Filled = true
+		}
+	}
+}
+
+// fillInterpolate linearly interpolates ranks between two ranked days.
+// Days that are not surrounded by ranked days on both sides stay empty.
+func fillInterpolate(entries []model.DailyRankEntry) {
+	prev := -1
+	for i := range entries {
+		if entries[i].Rank == nil {
+			continue
+		}
+		if prev >= 0 && i-prev > 1 {
+			from, to := float64(*entries[prev].Rank), float64(*entries[i].Rank)
+			for j := prev + 1; j < i; j++ {
+				ratio := float64(j-prev) / float64(i-prev)
+				rank := int(math.Round(from + (to-from)*ratio))
+				entries[j].Rank = &rank
+				entries[j].Filled = true
+			}
+		}
+		prev = i
+	}
 }
 
-// Helper function to check if the given date is the last day of its month
-func isLastDayOfMonth(t time.Time) bool {
-	nextDay := t.AddDate(0, 0, 1)
-	return t.Month() != nextDay.Month()
+func dayKey(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
 }
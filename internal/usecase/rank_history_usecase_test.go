@@ -14,12 +14,50 @@ import (
 type mockRepo struct {
 	data []model.DailyRank
 	err  error
+	// registrable is the registrable domain whose ranks were read, if any.
+	registrable string
 }
 
 func (m *mockRepo) GetDailyRanksByDateRange(ctx context.Context, domain string, start time.Time, end time.Time) ([]model.DailyRank, error) {
 	return m.data, m.err
 }
 
+func (m *mockRepo) GetRegistrableDailyRanksByDateRange(ctx context.Context, registrable string, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	m.registrable = registrable
+	return m.data, m.err
+}
+
+func (m *mockRepo) GetRanksByDomains(ctx context.Context, listID string, domains []string) ([]model.SiteRanking, error) {
+	return nil, errors.New("not implemented")
+}
+
+type mockMonthlyRepo struct {
+	MockTrancoMonthlyRankRepository
+	data []model.DailyRank
+	err  error
+	// registrable is the registrable domain whose ranks were read, if any.
+	registrable string
+}
+
+func (m *mockMonthlyRepo) GetMonthlyRanksByDateRange(ctx context.Context, domain string, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	return m.data, m.err
+}
+
+func (m *mockMonthlyRepo) GetRegistrableMonthlyRanksByDateRange(ctx context.Context, registrable string, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	m.registrable = registrable
+	return m.data, m.err
+}
+
+type mockListRepo struct {
+	MockTrancoListsRepository
+	lists []model.TrancoList
+	err   error
+}
+
+func (m *mockListRepo) FindByCreatedOnBetween(ctx context.Context, start time.Time, end time.Time) ([]model.TrancoList, error) {
+	return m.lists, m.err
+}
+
 func TestRankHistoryInteractor_GetDailyRanking(t *testing.T) {
 	startTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
 	endTime := time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)
@@ -59,7 +97,7 @@ func TestRankHistoryInteractor_GetDailyRanking(t *testing.T) {
 				repo: &mockRepo{data: tt.repoData, err: tt.repoErr},
 			}
 
-			got, err := interactor.GetDailyRanking(context.TODO(), "example.com", startTime, endTime)
+			got, err := interactor.GetDailyRanking(context.TODO(), "example.com", model.RollupNone, startTime, endTime)
 
 			if diff := cmp.Diff(tt.expected, got); diff != "" {
 				t.Errorf("unexpected result (-want +got):\n%s", diff)
@@ -78,54 +116,245 @@ func TestRankHistoryInteractor_GetDailyRanking(t *testing.T) {
 	}
 }
 
+func TestRankHistoryInteractor_GetDailyRanking_Rollup(t *testing.T) {
+	tests := []struct {
+		name            string
+		domain          string
+		rollup          model.Rollup
+		wantRegistrable string
+	}{
+		{name: "host", domain: "mail.google.com", rollup: model.RollupNone, wantRegistrable: ""},
+		{name: "subdomain rolled up", domain: "mail.google.com", rollup: model.RollupRegistrable, wantRegistrable: "google.com"},
+		{name: "multi-label suffix", domain: "www.bbc.co.uk", rollup: model.RollupRegistrable, wantRegistrable: "bbc.co.uk"},
+		{name: "registrable domain", domain: "google.com", rollup: model.RollupRegistrable, wantRegistrable: "google.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{data: []model.DailyRank{{Rank: 1, Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}}}
+			interactor := RankHistoryInteractor{repo: repo}
+
+			got, err := interactor.GetDailyRanking(context.Background(), tt.domain, tt.rollup, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != 1 {
+				t.Errorf("expected 1 rank, got %d", len(got))
+			}
+			if repo.registrable != tt.wantRegistrable {
+				t.Errorf("expected ranks of registrable domain %q, got %q", tt.wantRegistrable, repo.registrable)
+			}
+		})
+	}
+}
+
 func TestRankHistoryInteractor_GetMonthlyRanking(t *testing.T) {
 	startTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
 	endTime := time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)
 
 	tests := []struct {
-		name     string
-		repoData []model.DailyRank
-		repoErr  error
-		expected []model.DailyRank
-		err      error
+		name            string
+		rollup          model.Rollup
+		repoData        []model.DailyRank
+		repoErr         error
+		expected        []model.DailyRank
+		wantRegistrable string
+		err             error
 	}{
 		{
 			name: "Successful case",
 			repoData: []model.DailyRank{
-				{Rank: 1, Date: time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)},
-				{Rank: 2, Date: time.Date(2023, 2, 15, 0, 0, 0, 0, time.UTC)},
 				{Rank: 3, Date: time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC)},
+				{Rank: 1, Date: time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)},
 			},
 			expected: []model.DailyRank{
-				{Rank: 1, Date: time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)},
 				{Rank: 3, Date: time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC)},
+				{Rank: 1, Date: time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)},
 			},
 		},
+		{
+			name:            "Rolled up",
+			rollup:          model.RollupRegistrable,
+			repoData:        []model.DailyRank{{Rank: 1, Date: time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)}},
+			expected:        []model.DailyRank{{Rank: 1, Date: time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)}},
+			wantRegistrable: "testdomain.com",
+		},
 		{
 			name:     "Empty data from repository",
-			repoData: []model.DailyRank{},
+			repoData: nil,
 			expected: nil,
 		},
 		{
 			name:    "Repository error",
 			repoErr: errors.New("some error"),
-			err:     errors.New("failed to get daily ranks: some error"),
+			err:     errors.New("failed to get monthly ranks: some error"),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			r := &RankHistoryInteractor{
-				repo: &mockRepo{data: tt.repoData, err: tt.repoErr},
-			}
+			monthly := &mockMonthlyRepo{data: tt.repoData, err: tt.repoErr}
+			r := &RankHistoryInteractor{monthly: monthly}
 
-			result, err := r.GetMonthlyRanking(context.Background(), "testdomain.com", startTime, endTime)
+			result, err := r.GetMonthlyRanking(context.Background(), "www.testdomain.com", tt.rollup, startTime, endTime)
 			if diff := cmp.Diff(tt.expected, result); diff != "" {
 				t.Errorf("unexpected result (-want +got):\n%s", diff)
 			}
 			if (err != nil || tt.err != nil) && err.Error() != tt.err.Error() {
 				t.Errorf("expected error %v, but got %v", tt.err, err)
 			}
+			if monthly.registrable != tt.wantRegistrable {
+				t.Errorf("expected ranks of registrable domain %q, got %q", tt.wantRegistrable, monthly.registrable)
+			}
+		})
+	}
+}
+
+func TestRankHistoryInteractor_GetDailyRankingWithGaps(t *testing.T) {
+	startTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(2023, 1, 5, 0, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2023, 1, d, 0, 0, 0, 0, time.UTC) }
+	rank := func(r int) *int { return &r }
+
+	// 2023-01-01 and 2023-01-04 are ranked, 2023-01-02 and 2023-01-05 have a list without the domain
+	// and 2023-01-03 has no list at all.
+	repoData := []model.DailyRank{
+		{Rank: 40, Date: day(4), ListID: "D"},
+		{Rank: 10, Date: day(1), ListID: "A"},
+	}
+	lists := []model.TrancoList{
+		{ID: "A", CreatedOn: day(1)},
+		{ID: "B", CreatedOn: day(2)},
+		{ID: "D", CreatedOn: day(4)},
+		{ID: "E", CreatedOn: day(5)},
+	}
+
+	tests := []struct {
+		name     string
+		fill     model.FillMode
+		repoErr  error
+		listErr  error
+		expected []model.DailyRankEntry
+		err      error
+	}{
+		{
+			name: "fill with null",
+			fill: model.FillNull,
+			expected: []model.DailyRankEntry{
+				{Date: day(5), Status: model.RankStatusNotRanked, ListID: "E"},
+				{Rank: rank(40), Date: day(4), Status: model.RankStatusRanked, ListID: "D"},
+				{Date: day(3), Status: model.RankStatusNotIngested},
+				{Date: day(2), Status: model.RankStatusNotRanked, ListID: "B"},
+				{Rank: rank(10), Date: day(1), Status: model.RankStatusRanked, ListID: "A"},
+			},
+		},
+		{
+			name: "fill with previous",
+			fill: model.FillPrevious,
+			expected: []model.DailyRankEntry{
+				{Rank: rank(40), Date: day(5), Status: model.RankStatusNotRanked, Filled: true, ListID: "E"},
+				{Rank: rank(40), Date: day(4), Status: model.RankStatusRanked, ListID: "D"},
+				{Rank: rank(10), Date: day(3), Status: model.RankStatusNotIngested, Filled: true},
+				{Rank: rank(10), Date: day(2), Status: model.RankStatusNotRanked, Filled: true, ListID: "B"},
+				{Rank: rank(10), Date: day(1), Status: model.RankStatusRanked, ListID: "A"},
+			},
+		},
+		{
+			name: "fill with interpolate",
+			fill: model.FillInterpolate,
+			expected: []model.DailyRankEntry{
+				{Date: day(5), Status: model.RankStatusNotRanked, ListID: "E"},
+				{Rank: rank(40), Date: day(4), Status: model.RankStatusRanked, ListID: "D"},
+				{Rank: rank(30), Date: day(3), Status: model.RankStatusNotIngested, Filled: true},
+				{Rank: rank(20), Date: day(2), Status: model.RankStatusNotRanked, Filled: true, ListID: "B"},
+				{Rank: rank(10), Date: day(1), Status: model.RankStatusRanked, ListID: "A"},
+			},
+		},
+		{
+			name:    "Repository error",
+			fill:    model.FillNull,
+			repoErr: errors.New("some error"),
+			err:     errors.New("failed to get daily ranks: some error"),
+		},
+		{
+			name:    "List repository error",
+			fill:    model.FillNull,
+			listErr: errors.New("some error"),
+			err:     errors.New("failed to get lists: some error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interactor := NewRankHistoryInteractor(&mockRepo{data: repoData, err: tt.repoErr}, &mockListRepo{lists: lists, err: tt.listErr}, nil)
+
+			got, err := interactor.GetDailyRankingWithGaps(context.Background(), "example.com", model.RollupNone, startTime, endTime, tt.fill)
+
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+
+			if tt.err != nil {
+				if err == nil {
+					t.Errorf("expected error, got nil")
+				} else if err.Error() != tt.err.Error() {
+					t.Errorf("expected error: %v, got: %v", tt.err, err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRankHistoryInteractor_RefreshMonthly(t *testing.T) {
+	lists := []model.TrancoList{
+		{ID: "A", CreatedOn: time.Date(2023, 1, 30, 0, 0, 0, 0, time.UTC)},
+		{ID: "B", CreatedOn: time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{ID: "C", CreatedOn: time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		name          string
+		listErr       error
+		refreshErr    error
+		wantRefreshed []string
+		want          int
+		err           error
+	}{
+		{
+			name:          "only month ends are refreshed",
+			wantRefreshed: []string{"B", "C"},
+			want:          2,
+		},
+		{
+			name:    "List repository error",
+			listErr: errors.New("some error"),
+			err:     errors.New("failed to get lists: some error"),
+		},
+		{
+			name:          "Refresh error",
+			refreshErr:    errors.New("some error"),
+			wantRefreshed: []string{"B"},
+			err:           errors.New("failed to refresh monthly rankings of list B: some error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monthly := &MockTrancoMonthlyRankRepository{Err: tt.refreshErr}
+			interactor := NewRankHistoryInteractor(nil, &mockListRepo{lists: lists, err: tt.listErr}, monthly)
+
+			got, err := interactor.RefreshMonthly(context.Background(), time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC))
+			if got != tt.want {
+				t.Errorf("expected %d refreshed lists, got %d", tt.want, got)
+			}
+			if diff := cmp.Diff(tt.wantRefreshed, monthly.Refreshed); diff != "" {
+				t.Errorf("unexpected refreshed lists (-want +got):\n%s", diff)
+			}
+			if (err != nil || tt.err != nil) && (err == nil || tt.err == nil || err.Error() != tt.err.Error()) {
+				t.Errorf("expected error %v, but got %v", tt.err, err)
+			}
 		})
 	}
 }
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/repository"
+)
+
+// pingTimeout bounds how long a readiness check waits for the database.
+const pingTimeout = 2 * time.Second
+
+type ReadinessUseCase interface {
+	Check(ctx context.Context) model.Readiness
+}
+
+// StalenessThreshold is how old the latest list may be before the API is reported as not ready.
+type StalenessThreshold time.Duration
+
+type ReadinessInteractor struct {
+	health    repository.HealthRepository
+	list      repository.TrancoListsRepository
+	staleness time.Duration
+	now       func() time.Time
+}
+
+func NewReadinessInteractor(health repository.HealthRepository, list repository.TrancoListsRepository, staleness StalenessThreshold) *ReadinessInteractor {
+	return &ReadinessInteractor{health: health, list: list, staleness: time.Duration(staleness), now: time.Now}
+}
+
+// Check pings the database and compares the creation date of the latest list with the staleness threshold.
+func (r ReadinessInteractor) Check(ctx context.Context) model.Readiness {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	if err := r.health.Ping(ctx); err != nil {
+		return model.Readiness{DatabaseErr: err, Stale: true}
+	}
+
+	latest, err := r.list.FindLatest(ctx)
+	if err != nil {
+		return model.Readiness{DatabaseErr: fmt.Errorf("failed to find latest list: %w", err), Stale: true}
+	}
+
+	if latest.ID == "" {
+		return model.Readiness{Stale: true}
+	}
+
+	age := r.now().Sub(latest.CreatedOn)
+	return model.Readiness{LatestList: latest, Age: age, Stale: age > r.staleness}
+}
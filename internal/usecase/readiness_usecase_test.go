@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+type mockHealthRepo struct {
+	err error
+}
+
+func (m mockHealthRepo) Ping(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		return errors.New("ping has no timeout")
+	}
+	return m.err
+}
+
+type mockListRepoForReadiness struct {
+	MockTrancoListsRepository
+	latest model.TrancoList
+	err    error
+}
+
+func (m *mockListRepoForReadiness) FindLatest(ctx context.Context) (model.TrancoList, error) {
+	return m.latest, m.err
+}
+
+func TestReadinessInteractor_Check(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	pingErr := errors.New("connection refused")
+
+	tests := []struct {
+		name      string
+		pingErr   error
+		latest    model.TrancoList
+		listErr   error
+		want      model.Readiness
+		wantReady bool
+	}{
+		{
+			name:      "fresh list",
+			latest:    model.TrancoList{ID: "X5Y7N", CreatedOn: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)},
+			want:      model.Readiness{LatestList: model.TrancoList{ID: "X5Y7N", CreatedOn: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)}, Age: 36 * time.Hour},
+			wantReady: true,
+		},
+		{
+			name:      "stale list",
+			latest:    model.TrancoList{ID: "X5Y7N", CreatedOn: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+			want:      model.Readiness{LatestList: model.TrancoList{ID: "X5Y7N", CreatedOn: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)}, Age: 132 * time.Hour, Stale: true},
+			wantReady: false,
+		},
+		{
+			name:      "no list",
+			want:      model.Readiness{Stale: true},
+			wantReady: false,
+		},
+		{
+			name:      "database down",
+			pingErr:   pingErr,
+			want:      model.Readiness{DatabaseErr: pingErr, Stale: true},
+			wantReady: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := ReadinessInteractor{
+				health:    mockHealthRepo{err: tt.pingErr},
+				list:      &mockListRepoForReadiness{latest: tt.latest, err: tt.listErr},
+				staleness: 72 * time.Hour,
+				now:       func() time.Time { return now },
+			}
+
+			got := r.Check(context.Background())
+			if diff := cmp.Diff(tt.want, got, cmp.Comparer(func(a, b error) bool { return errors.Is(a, b) })); diff != "" {
+				t.Errorf("unexpected readiness (-want +got):\n%s", diff)
+			}
+			if got.Ready() != tt.wantReady {
+				t.Errorf("expected ready %v, got %v", tt.wantReady, got.Ready())
+			}
+		})
+	}
+}
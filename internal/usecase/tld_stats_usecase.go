@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/repository"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+)
+
+// defaultHistoryTLDs is how many TLDs a history reports when none are requested.
+const defaultHistoryTLDs = 5
+
+// TLDStatsUseCase reports how the top N domains of lists are spread over TLDs. top must be one of model.TLDStatsTops.
+type TLDStatsUseCase interface {
+	// GetDistribution returns the TLD counts of the top N of the list of date, or of the latest list when date is zero.
+	// It returns ErrListNotFound when there is no such list. Counts is empty when the counts of the list are not stored.
+	GetDistribution(ctx context.Context, date time.Time, top int) (model.TLDDistribution, error)
+	// GetHistory returns the counts of tlds in the top N of every list created from start to the end of the end date, oldest first.
+	// Without tlds, the TLDs with the most domains over the range are reported. The TLDs reported are returned with the history.
+	GetHistory(ctx context.Context, start time.Time, end time.Time, top int, tlds []string) ([]model.TLDDistribution, []string, error)
+	// Refresh stores the counts of the lists created from start to the end of the end date and returns how many lists were counted.
+	Refresh(ctx context.Context, start time.Time, end time.Time) (int, error)
+}
+
+type TLDStatsInteractor struct {
+	list  repository.TrancoListsRepository
+	stats repository.TrancoTLDStatsRepository
+}
+
+func NewTLDStatsInteractor(list repository.TrancoListsRepository, stats repository.TrancoTLDStatsRepository) *TLDStatsInteractor {
+	return &TLDStatsInteractor{list: list, stats: stats}
+}
+
+func (t TLDStatsInteractor) GetDistribution(ctx context.Context, date time.Time, top int) (dist model.TLDDistribution, err error) {
+	ctx, span := tracer.Start(ctx, "tld_stats.get_distribution", trace.WithAttributes(
+		attribute.String("date", date.Format("2006-01-02")),
+		attribute.Int("top", top),
+	))
+	defer func() {
+		span.SetAttributes(attribute.String("list_id", dist.List.ID), attribute.Int("rows", len(dist.Counts)))
+		endSpan(span, err)
+	}()
+
+	list, err := t.findList(ctx, date)
+	if err != nil {
+		return model.TLDDistribution{}, err
+	}
+
+	counts, err := t.stats.FindByListID(ctx, list.ID, top)
+	if err != nil {
+		return model.TLDDistribution{}, fmt.Errorf("failed to get TLD stats: %w", err)
+	}
+
+	dist = model.TLDDistribution{List: list, Top: top, Counts: counts}
+	for _, count := range counts {
+		dist.Total += count.Domains
+	}
+	return dist, nil
+}
+
+// findList returns the list of date, or the latest list when date is zero.
+func (t TLDStatsInteractor) findList(ctx context.Context, date time.Time) (model.TrancoList, error) {
+	if date.IsZero() {
+		list, err := t.list.FindLatest(ctx)
+		if err != nil {
+			return model.TrancoList{}, fmt.Errorf("failed to get latest list: %w", err)
+		}
+		if list.ID == "" {
+			return model.TrancoList{}, ErrListNotFound
+		}
+		return list, nil
+	}
+
+	lists, err := t.list.FindByCreatedOnBetween(ctx, date, date)
+	if err != nil {
+		return model.TrancoList{}, fmt.Errorf("failed to get list of %s: %w", date.Format("2006-01-02"), err)
+	}
+	if len(lists) == 0 {
+		return model.TrancoList{}, fmt.Errorf("%w: %s", ErrListNotFound, date.Format("2006-01-02"))
+	}
+	return lists[0], nil
+}
+
+func (t TLDStatsInteractor) GetHistory(ctx context.Context, start time.Time, end time.Time, top int, tlds []string) (history []model.TLDDistribution, reported []string, err error) {
+	ctx, span := tracer.Start(ctx, "tld_stats.get_history", trace.WithAttributes(
+		attribute.String("start_date", start.Format("2006-01-02")),
+		attribute.String("end_date", end.Format("2006-01-02")),
+		attribute.Int("top", top),
+	))
+	defer func() {
+		span.SetAttributes(attribute.Int("rows", len(history)))
+		endSpan(span, err)
+	}()
+
+	if len(tlds) == 0 {
+		tlds, err = t.stats.FindLargestTLDs(ctx, top, start, end, defaultHistoryTLDs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get largest TLDs: %w", err)
+		}
+	}
+
+	counts, err := t.stats.FindByCreatedOnBetween(ctx, top, start, end, tlds)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get TLD stats: %w", err)
+	}
+	return buildTLDHistory(counts, top, tlds), tlds, nil
+}
+
+// buildTLDHistory groups counts, which are ordered by list, into one distribution per list with a count for each of tlds in order.
+func buildTLDHistory(counts []model.TLDListCount, top int, tlds []string) []model.TLDDistribution {
+	index := make(map[string]int, len(tlds))
+	for i, tld := range tlds {
+		index[tld] = i
+	}
+
+	var history []model.TLDDistribution
+	for _, count := range counts {
+		if len(history) == 0 || history[len(history)-1].List.ID != count.ListID {
+			dist := model.TLDDistribution{
+				List:   model.TrancoList{ID: count.ListID, CreatedOn: count.CreatedOn},
+				Top:    top,
+				Counts: make([]model.TLDCount, len(tlds)),
+			}
+			for i, tld := range tlds {
+				dist.Counts[i].TLD = tld
+			}
+			history = append(history, dist)
+		}
+
+		dist := &history[len(history)-1]
+		dist.Total += count.Domains
+		if i, ok := index[count.TLD]; ok {
+			dist.Counts[i].Domains = count.Domains
+		} else {
+			dist.Other += count.Domains
+		}
+	}
+	return history
+}
+
+func (t TLDStatsInteractor) Refresh(ctx context.Context, start time.Time, end time.Time) (refreshed int, err error) {
+	ctx, span := tracer.Start(ctx, "tld_stats.refresh", trace.WithAttributes(
+		attribute.String("start_date", start.Format("2006-01-02")),
+		attribute.String("end_date", end.Format("2006-01-02")),
+	))
+	defer func() {
+		span.SetAttributes(attribute.Int("rows", refreshed))
+		endSpan(span, err)
+	}()
+
+	lists, err := t.list.FindByCreatedOnBetween(ctx, start, end)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get lists: %w", err)
+	}
+
+	for _, list := range lists {
+		if err := t.stats.Refresh(ctx, list.ID, model.TLDStatsTops); err != nil {
+			return refreshed, err
+		}
+		refreshed++
+		util.Logger(ctx).WithFields(log.Fields{"list_id": list.ID, "created_on": list.CreatedOn}).Info("refreshed TLD stats")
+	}
+	return refreshed, nil
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+type mockListRepoForStats struct {
+	MockTrancoListsRepository
+	latest model.TrancoList
+	lists  []model.TrancoList
+}
+
+func (m *mockListRepoForStats) FindLatest(ctx context.Context) (model.TrancoList, error) {
+	return m.latest, nil
+}
+
+func (m *mockListRepoForStats) FindByCreatedOnBetween(ctx context.Context, start time.Time, end time.Time) ([]model.TrancoList, error) {
+	return m.lists, nil
+}
+
+type mockTLDStatsRepo struct {
+	MockTrancoTLDStatsRepository
+	counts     map[string][]model.TLDCount
+	largest    []string
+	listCounts []model.TLDListCount
+	tlds       []string
+	refreshed  []string
+}
+
+func (m *mockTLDStatsRepo) Refresh(ctx context.Context, listID string, tops []int) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	m.refreshed = append(m.refreshed, listID)
+	return nil
+}
+
+func (m *mockTLDStatsRepo) FindByListID(ctx context.Context, listID string, top int) ([]model.TLDCount, error) {
+	return m.counts[listID], nil
+}
+
+func (m *mockTLDStatsRepo) FindLargestTLDs(ctx context.Context, top int, start time.Time, end time.Time, n int) ([]string, error) {
+	return m.largest[:min(n, len(m.largest))], nil
+}
+
+func (m *mockTLDStatsRepo) FindByCreatedOnBetween(ctx context.Context, top int, start time.Time, end time.Time, tlds []string) ([]model.TLDListCount, error) {
+	m.tlds = tlds
+	return m.listCounts, nil
+}
+
+func TestTLDStatsInteractor_GetDistribution(t *testing.T) {
+	jan1 := model.TrancoList{ID: "Q94V4", CreatedOn: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	jan2 := model.TrancoList{ID: "X5Y7N", CreatedOn: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+	stats := &mockTLDStatsRepo{counts: map[string][]model.TLDCount{
+		"Q94V4": {{TLD: "com", Domains: 60}, {TLD: "org", Domains: 40}},
+		"X5Y7N": {{TLD: "com", Domains: 70}, {TLD: "io", Domains: 30}},
+	}}
+
+	tests := []struct {
+		name    string
+		list    *mockListRepoForStats
+		date    time.Time
+		want    model.TLDDistribution
+		wantErr error
+	}{
+		{
+			name: "list of the date",
+			list: &mockListRepoForStats{latest: jan2, lists: []model.TrancoList{jan1}},
+			date: jan1.CreatedOn,
+			want: model.TLDDistribution{List: jan1, Top: 100, Total: 100, Counts: []model.TLDCount{{TLD: "com", Domains: 60}, {TLD: "org", Domains: 40}}},
+		},
+		{
+			name: "latest list",
+			list: &mockListRepoForStats{latest: jan2},
+			want: model.TLDDistribution{List: jan2, Top: 100, Total: 100, Counts: []model.TLDCount{{TLD: "com", Domains: 70}, {TLD: "io", Domains: 30}}},
+		},
+		{name: "no list of the date", list: &mockListRepoForStats{latest: jan2}, date: jan1.CreatedOn, wantErr: ErrListNotFound},
+		{name: "no list", list: &mockListRepoForStats{}, wantErr: ErrListNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTLDStatsInteractor(tt.list, stats).GetDistribution(context.Background(), tt.date, 100)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected distribution (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTLDStatsInteractor_GetHistory(t *testing.T) {
+	jan1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jan2 := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	stats := &mockTLDStatsRepo{
+		largest: []string{"com", "io"},
+		listCounts: []model.TLDListCount{
+			{ListID: "Q94V4", CreatedOn: jan1, TLD: "", Domains: 10},
+			{ListID: "Q94V4", CreatedOn: jan1, TLD: "com", Domains: 90},
+			{ListID: "X5Y7N", CreatedOn: jan2, TLD: "", Domains: 5},
+			{ListID: "X5Y7N", CreatedOn: jan2, TLD: "com", Domains: 80},
+			{ListID: "X5Y7N", CreatedOn: jan2, TLD: "io", Domains: 15},
+		},
+	}
+
+	history, tlds, err := NewTLDStatsInteractor(&mockListRepoForStats{}, stats).GetHistory(context.Background(), jan1, jan2, 100, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"com", "io"}, tlds); diff != "" {
+		t.Errorf("unexpected tlds (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"com", "io"}, stats.tlds); diff != "" {
+		t.Errorf("unexpected tlds queried (-want +got):\n%s", diff)
+	}
+	want := []model.TLDDistribution{
+		{List: model.TrancoList{ID: "Q94V4", CreatedOn: jan1}, Top: 100, Total: 100, Counts: []model.TLDCount{{TLD: "com", Domains: 90}, {TLD: "io", Domains: 0}}, Other: 10},
+		{List: model.TrancoList{ID: "X5Y7N", CreatedOn: jan2}, Top: 100, Total: 100, Counts: []model.TLDCount{{TLD: "com", Domains: 80}, {TLD: "io", Domains: 15}}, Other: 5},
+	}
+	if diff := cmp.Diff(want, history); diff != "" {
+		t.Errorf("unexpected history (-want +got):\n%s", diff)
+	}
+}
+
+func TestTLDStatsInteractor_Refresh(t *testing.T) {
+	list := &mockListRepoForStats{lists: []model.TrancoList{{ID: "Q94V4"}, {ID: "X5Y7N"}}}
+
+	stats := &mockTLDStatsRepo{}
+	refreshed, err := NewTLDStatsInteractor(list, stats).Refresh(context.Background(), time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if refreshed != 2 {
+		t.Errorf("expected 2 refreshed lists, got %d", refreshed)
+	}
+	if diff := cmp.Diff([]string{"Q94V4", "X5Y7N"}, stats.refreshed); diff != "" {
+		t.Errorf("unexpected lists (-want +got):\n%s", diff)
+	}
+
+	failing := &mockTLDStatsRepo{MockTrancoTLDStatsRepository: MockTrancoTLDStatsRepository{Err: errors.New("mock error")}}
+	if _, err := NewTLDStatsInteractor(list, failing).Refresh(context.Background(), time.Time{}, time.Time{}); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
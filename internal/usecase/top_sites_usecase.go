@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/repository"
+)
+
+// ErrListNotFound is returned when no list was ingested for the requested date or with the requested ID.
+var ErrListNotFound = errors.New("list not found")
+
+type TopSitesUseCase interface {
+	GetTop(ctx context.Context, date time.Time, n int) (model.TrancoList, []model.SiteRanking, error)
+	GetLists(ctx context.Context, start time.Time, end time.Time) ([]model.TrancoList, error)
+	Diff(ctx context.Context, from time.Time, to time.Time, n int) ([]model.RankChange, error)
+}
+
+type TopSitesInteractor struct {
+	list    repository.TrancoListsRepository
+	ranking repository.TrancoRankingsRepository
+}
+
+func NewTopSitesInteractor(list repository.TrancoListsRepository, ranking repository.TrancoRankingsRepository) *TopSitesInteractor {
+	return &TopSitesInteractor{list: list, ranking: ranking}
+}
+
+// GetTop returns the list of the date and its best ranked n domains.
+func (t TopSitesInteractor) GetTop(ctx context.Context, date time.Time, n int) (model.TrancoList, []model.SiteRanking, error) {
+	list, err := t.findList(ctx, date)
+	if err != nil {
+		return model.TrancoList{}, nil, err
+	}
+
+	rankings, err := t.ranking.FindTopByListID(ctx, list.ID, n)
+	if err != nil {
+		return model.TrancoList{}, nil, fmt.Errorf("failed to get top %d rankings of list %s: %w", n, list.ID, err)
+	}
+	return list, rankings, nil
+}
+
+// GetLists returns the lists created from start to end, oldest first.
+func (t TopSitesInteractor) GetLists(ctx context.Context, start time.Time, end time.Time) ([]model.TrancoList, error) {
+	lists, err := t.list.FindByCreatedOnBetween(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lists: %w", err)
+	}
+	return lists, nil
+}
+
+// Diff compares the top n domains of the lists of two dates.
+// Domains that entered or left the top n are included with the missing rank left nil.
+// The result is ordered by the rank in the later list, followed by the domains that left.
+func (t TopSitesInteractor) Diff(ctx context.Context, from time.Time, to time.Time, n int) ([]model.RankChange, error) {
+	_, before, err := t.GetTop(ctx, from, n)
+	if err != nil {
+		return nil, err
+	}
+	_, after, err := t.GetTop(ctx, to, n)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]*model.RankChange, len(before)+len(after))
+	for _, r := range before {
+		rank := r.Rank
+		changes[r.Domain] = &model.RankChange{Domain: r.Domain, FromRank: &rank}
+	}
+	for _, r := range after {
+		rank := r.Rank
+		if c, ok := changes[r.Domain]; ok {
+			c.ToRank = &rank
+		} else {
+			changes[r.Domain] = &model.RankChange{Domain: r.Domain, ToRank: &rank}
+		}
+	}
+
+	result := make([]model.RankChange, 0, len(changes))
+	for _, c := range changes {
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if (a.ToRank == nil) != (b.ToRank == nil) {
+			return a.ToRank != nil
+		}
+		if a.ToRank != nil {
+			return *a.ToRank < *b.ToRank
+		}
+		return *a.FromRank < *b.FromRank
+	})
+	return result, nil
+}
+
+func (t TopSitesInteractor) findList(ctx context.Context, date time.Time) (model.TrancoList, error) {
+	lists, err := t.list.FindByCreatedOnBetween(ctx, date, date)
+	if err != nil {
+		return model.TrancoList{}, fmt.Errorf("failed to get list of %s: %w", date.Format("2006-01-02"), err)
+	}
+	if len(lists) == 0 {
+		return model.TrancoList{}, fmt.Errorf("%w: %s", ErrListNotFound, date.Format("2006-01-02"))
+	}
+	return lists[0], nil
+}
@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+type mockListRepoForTopSites struct {
+	MockTrancoListsRepository
+	lists map[string]model.TrancoList
+	err   error
+}
+
+func (m *mockListRepoForTopSites) FindByCreatedOnBetween(ctx context.Context, start time.Time, end time.Time) ([]model.TrancoList, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if list, ok := m.lists[start.Format("2006-01-02")]; ok {
+		return []model.TrancoList{list}, nil
+	}
+	return []model.TrancoList{}, nil
+}
+
+type mockRankingRepoForTopSites struct {
+	MockTrancoRankingsRepository
+	rankings map[string][]model.SiteRanking
+	err      error
+}
+
+func (m *mockRankingRepoForTopSites) FindTopByListID(ctx context.Context, listID string, limit int) ([]model.SiteRanking, error) {
+	return m.rankings[listID], m.err
+}
+
+func TestTopSitesInteractor_GetTop(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	lists := map[string]model.TrancoList{"2024-05-01": {ID: "A", CreatedOn: date}}
+	rankings := map[string][]model.SiteRanking{"A": {{Rank: 1, Domain: "google.com"}}}
+
+	tests := []struct {
+		name         string
+		date         time.Time
+		listErr      error
+		rankingErr   error
+		expectedList model.TrancoList
+		expected     []model.SiteRanking
+		wantErr      error
+	}{
+		{
+			name:         "Successful case",
+			date:         date,
+			expectedList: model.TrancoList{ID: "A", CreatedOn: date},
+			expected:     []model.SiteRanking{{Rank: 1, Domain: "google.com"}},
+		},
+		{
+			name:    "No list for the date",
+			date:    date.AddDate(0, 0, 1),
+			wantErr: ErrListNotFound,
+		},
+		{
+			name:    "List repository error",
+			date:    date,
+			listErr: errors.New("some error"),
+			wantErr: errors.New("failed to get list of 2024-05-01: some error"),
+		},
+		{
+			name:       "Ranking repository error",
+			date:       date,
+			rankingErr: errors.New("some error"),
+			wantErr:    errors.New("failed to get top 10 rankings of list A: some error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interactor := NewTopSitesInteractor(&mockListRepoForTopSites{lists: lists, err: tt.listErr}, &mockRankingRepoForTopSites{rankings: rankings, err: tt.rankingErr})
+
+			list, got, err := interactor.GetTop(context.Background(), tt.date, 10)
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				if !errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error() {
+					t.Errorf("expected error: %v, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expectedList, list); diff != "" {
+				t.Errorf("unexpected list (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTopSitesInteractor_Diff(t *testing.T) {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	rank := func(r int) *int { return &r }
+
+	lists := map[string]model.TrancoList{
+		"2024-05-01": {ID: "A", CreatedOn: from},
+		"2024-05-02": {ID: "B", CreatedOn: to},
+	}
+	rankings := map[string][]model.SiteRanking{
+		"A": {{Rank: 1, Domain: "google.com"}, {Rank: 2, Domain: "facebook.com"}, {Rank: 3, Domain: "old.example"}},
+		"B": {{Rank: 1, Domain: "facebook.com"}, {Rank: 2, Domain: "google.com"}, {Rank: 3, Domain: "new.example"}},
+	}
+
+	interactor := NewTopSitesInteractor(&mockListRepoForTopSites{lists: lists}, &mockRankingRepoForTopSites{rankings: rankings})
+
+	got, err := interactor.Diff(context.Background(), from, to, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []model.RankChange{
+		{Domain: "facebook.com", FromRank: rank(2), ToRank: rank(1)},
+		{Domain: "google.com", FromRank: rank(1), ToRank: rank(2)},
+		{Domain: "new.example", ToRank: rank(3)},
+		{Domain: "old.example", FromRank: rank(3)},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("unexpected result (-want +got):\n%s", diff)
+	}
+	if got[0].Change() != 1 || got[2].Change() != 0 {
+		t.Errorf("unexpected changes: %d, %d", got[0].Change(), got[2].Change())
+	}
+}
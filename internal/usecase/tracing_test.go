@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"github.com/shigaichi/tranco"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var (
+	exporter     = tracetest.NewInMemoryExporter()
+	setupTracing sync.Once
+)
+
+// recordSpans installs an in-memory exporter as the global tracer provider and clears recorded spans.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	setupTracing.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	})
+	exporter.Reset()
+	return exporter
+}
+
+func spanNames(spans tracetest.SpanStubs) []string {
+	names := make([]string, len(spans))
+	for i, span := range spans {
+		names[i] = span.Name
+	}
+	return names
+}
+
+func TestRankHistoryInteractor_GetDailyRanking_Span(t *testing.T) {
+	exp := recordSpans(t)
+
+	r := RankHistoryInteractor{repo: &mockRepo{data: []model.DailyRank{{Rank: 1, Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}}}}
+	_, err := r.GetDailyRanking(context.Background(), "example.com", model.RollupNone, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := exp.GetSpans()
+	if diff := cmp.Diff([]string{"rank_history.get_daily_ranking"}, spanNames(spans)); diff != "" {
+		t.Fatalf("span names mismatch:\n%s", diff)
+	}
+
+	want := []attribute.KeyValue{
+		attribute.String("domain", "example.com"),
+		attribute.String("start_date", "2023-01-01"),
+		attribute.String("end_date", "2023-01-02"),
+		attribute.Int("rows", 1),
+	}
+	if diff := cmp.Diff(want, spans[0].Attributes, cmp.Comparer(func(a, b attribute.Value) bool { return a == b })); diff != "" {
+		t.Errorf("attributes mismatch:\n%s", diff)
+	}
+}
+
+func TestStandardWriteInteractor_Write_Spans(t *testing.T) {
+	exp := recordSpans(t)
+
+	i := StandardWriteInteractor{
+		api:         &MockTrancoAPIRepository{Metadata: tranco.ListMetadata{ListID: "X5Y7N", Download: "https://tranco-list.eu/download/X5Y7N/1000000", CreatedOn: time.Date(2023, 10, 17, 0, 0, 0, 0, time.UTC)}},
+		list:        &MockTrancoListsRepository{IsExist: false},
+		csv:         &MockTrancoCsvRepository{SiteRankings: []model.SiteRanking{{Domain: "example.com", Rank: 1}}},
+		transaction: &MockTransaction{},
+		domain:      &MockTrancoDomainsRepository{ID: 0},
+		ranking:     &MockTrancoRankingsRepository{ExpectedRankings: []model.TrancoRanking{{DomainID: 10, ListID: "X5Y7N", Ranking: 1}}},
+		stats:       &MockTrancoTLDStatsRepository{},
+	}
+	if _, err := i.Write(context.Background(), time.Date(2023, 10, 17, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := exp.GetSpans()
+	// spans are exported when they end, so children come before their parent.
+	if diff := cmp.Diff([]string{"standard_write.resolve_list", "standard_write.insert", "standard_write"}, spanNames(spans)); diff != "" {
+		t.Fatalf("span names mismatch:\n%s", diff)
+	}
+
+	root := spans[2].SpanContext.SpanID()
+	for _, span := range spans[:2] {
+		if span.Parent.SpanID() != root {
+			t.Errorf("span %s is not a child of standard_write", span.Name)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// trendThreshold is how much the fitted log-rank has to change over the range for a trend to be rising or falling.
+	// 0.1 is a change of about 10% of the rank.
+	trendThreshold = 0.1
+	// volatileThreshold is the standard deviation of the log-rank around the fit above which a trend is volatile.
+	volatileThreshold = 0.25
+)
+
+// TrendUseCase analyzes the rank history of a domain.
+type TrendUseCase interface {
+	// GetTrend summarizes the daily ranks of domain from start to end. With model.RollupRegistrable, the ranks are
+	// those of the registrable domain of domain.
+	GetTrend(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time) (model.RankTrend, error)
+}
+
+type TrendInteractor struct {
+	history RankHistoryUseCase
+}
+
+func NewTrendInteractor(history RankHistoryUseCase) *TrendInteractor {
+	return &TrendInteractor{history: history}
+}
+
+func (t TrendInteractor) GetTrend(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time) (trend model.RankTrend, err error) {
+	ctx, span := startRankHistorySpan(ctx, "trend.get_trend", domain, rollup, start, end)
+	defer func() {
+		span.SetAttributes(attribute.Int("rows", trend.Points), attribute.String("class", string(trend.Class)))
+		endSpan(span, err)
+	}()
+
+	ranks, err := t.history.GetDailyRanking(ctx, domain, rollup, start, end)
+	if err != nil {
+		return model.RankTrend{}, fmt.Errorf("failed to get rank history: %w", err)
+	}
+	return analyzeTrend(ranks), nil
+}
+
+// analyzeTrend summarizes ranks, which may be in any order.
+func analyzeTrend(ranks []model.DailyRank) model.RankTrend {
+	if len(ranks) == 0 {
+		return model.RankTrend{}
+	}
+
+	ranks = slices.Clone(ranks)
+	slices.SortFunc(ranks, func(a, b model.DailyRank) int { return a.Date.Compare(b.Date) })
+
+	trend := model.RankTrend{
+		Points:    len(ranks),
+		FirstRank: ranks[0].Rank,
+		LastRank:  ranks[len(ranks)-1].Rank,
+		ListIDs:   make([]string, len(ranks)),
+	}
+
+	// least squares fit of the log-rank against the days since the first rank
+	n := float64(len(ranks))
+	xs := make([]float64, len(ranks))
+	ys := make([]float64, len(ranks))
+	var meanX, meanY, meanRank float64
+	for i, rank := range ranks {
+		xs[i] = rank.Date.Sub(ranks[0].Date).Hours() / 24
+		ys[i] = math.Log(float64(rank.Rank))
+		meanX += xs[i] / n
+		meanY += ys[i] / n
+		meanRank += float64(rank.Rank) / n
+		trend.ListIDs[i] = rank.ListID
+	}
+	var sxx, sxy float64
+	for i := range xs {
+		sxx += (xs[i] - meanX) * (xs[i] - meanX)
+		sxy += (xs[i] - meanX) * (ys[i] - meanY)
+	}
+	if sxx > 0 {
+		trend.Slope = sxy / sxx
+	}
+
+	var rankVariance, residualVariance float64
+	for i, rank := range ranks {
+		rankVariance += (float64(rank.Rank) - meanRank) * (float64(rank.Rank) - meanRank) / n
+		residual := ys[i] - (meanY + trend.Slope*(xs[i]-meanX))
+		residualVariance += residual * residual / n
+	}
+	trend.StdDev = math.Sqrt(rankVariance)
+
+	best := ranks[0]
+	for _, rank := range ranks[1:] {
+		if drop := rank.Rank - best.Rank; drop > trend.MaxDrawdown {
+			trend.MaxDrawdown, trend.DrawdownFrom, trend.DrawdownTo = drop, best.Date, rank.Date
+		}
+		if rank.Rank < best.Rank {
+			best = rank
+		}
+	}
+
+	change := trend.Slope * xs[len(xs)-1]
+	switch {
+	case math.Sqrt(residualVariance) > volatileThreshold:
+		trend.Class = model.TrendVolatile
+	case change < -trendThreshold:
+		trend.Class = model.TrendRising
+	case change > trendThreshold:
+		trend.Class = model.TrendFalling
+	default:
+		trend.Class = model.TrendStable
+	}
+	return trend
+}
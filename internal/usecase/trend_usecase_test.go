@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
+)
+
+type mockRankHistoryForTrend struct {
+	RankHistoryUseCase
+	ranks []model.DailyRank
+	err   error
+}
+
+func (m mockRankHistoryForTrend) GetDailyRanking(ctx context.Context, domain string, rollup model.Rollup, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	return m.ranks, m.err
+}
+
+func TestTrendInteractor_GetTrend(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		ranks    []model.DailyRank
+		repoErr  error
+		expected model.RankTrend
+		err      error
+	}{
+		{
+			name: "falling, newest first like the history",
+			ranks: []model.DailyRank{
+				{Rank: 400, Date: day(3), ListID: "C"},
+				{Rank: 200, Date: day(2), ListID: "B"},
+				{Rank: 100, Date: day(1), ListID: "A"},
+			},
+			expected: model.RankTrend{
+				Points:       3,
+				FirstRank:    100,
+				LastRank:     400,
+				Slope:        0.693147180559945,
+				StdDev:       124.72191289246472,
+				MaxDrawdown:  300,
+				DrawdownFrom: day(1),
+				DrawdownTo:   day(3),
+				Class:        model.TrendFalling,
+				ListIDs:      []string{"A", "B", "C"},
+			},
+		},
+		{
+			name: "rising with gaps between the days",
+			ranks: []model.DailyRank{
+				{Rank: 400, Date: day(1), ListID: "A"},
+				{Rank: 200, Date: day(3), ListID: "C"},
+				{Rank: 100, Date: day(5), ListID: "E"},
+			},
+			expected: model.RankTrend{
+				Points:    3,
+				FirstRank: 400,
+				LastRank:  100,
+				Slope:     -0.3465735902799725,
+				StdDev:    124.72191289246472,
+				Class:     model.TrendRising,
+				ListIDs:   []string{"A", "C", "E"},
+			},
+		},
+		{
+			name: "stable",
+			ranks: []model.DailyRank{
+				{Rank: 100, Date: day(1), ListID: "A"},
+				{Rank: 101, Date: day(2), ListID: "B"},
+				{Rank: 100, Date: day(3), ListID: "C"},
+				{Rank: 99, Date: day(4), ListID: "D"},
+			},
+			expected: model.RankTrend{
+				Points:       4,
+				FirstRank:    100,
+				LastRank:     99,
+				Slope:        -0.0040101338413673915,
+				StdDev:       0.7071067811865476,
+				MaxDrawdown:  1,
+				DrawdownFrom: day(1),
+				DrawdownTo:   day(2),
+				Class:        model.TrendStable,
+				ListIDs:      []string{"A", "B", "C", "D"},
+			},
+		},
+		{
+			name: "volatile",
+			ranks: []model.DailyRank{
+				{Rank: 100, Date: day(1), ListID: "A"},
+				{Rank: 1000, Date: day(2), ListID: "B"},
+				{Rank: 100, Date: day(3), ListID: "C"},
+				{Rank: 1000, Date: day(4), ListID: "D"},
+			},
+			expected: model.RankTrend{
+				Points:       4,
+				FirstRank:    100,
+				LastRank:     1000,
+				Slope:        0.460517018598809,
+				StdDev:       450,
+				MaxDrawdown:  900,
+				DrawdownFrom: day(1),
+				DrawdownTo:   day(2),
+				Class:        model.TrendVolatile,
+				ListIDs:      []string{"A", "B", "C", "D"},
+			},
+		},
+		{
+			name:  "single rank",
+			ranks: []model.DailyRank{{Rank: 50, Date: day(1), ListID: "A"}},
+			expected: model.RankTrend{
+				Points:    1,
+				FirstRank: 50,
+				LastRank:  50,
+				Class:     model.TrendStable,
+				ListIDs:   []string{"A"},
+			},
+		},
+		{
+			name:     "not ranked",
+			expected: model.RankTrend{},
+		},
+		{
+			name:    "history error",
+			repoErr: errors.New("some error"),
+			err:     errors.New("failed to get rank history: some error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interactor := NewTrendInteractor(mockRankHistoryForTrend{ranks: tt.ranks, err: tt.repoErr})
+
+			got, err := interactor.GetTrend(context.Background(), "example.com", model.RollupNone, day(1), day(31))
+
+			if diff := cmp.Diff(tt.expected, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+			if tt.err != nil {
+				if err == nil {
+					t.Errorf("expected error, got nil")
+				} else if err.Error() != tt.err.Error() {
+					t.Errorf("expected error: %v, got: %v", tt.err, err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -10,11 +10,14 @@ import (
 
 	"github.com/shigaichi/top-sites-ranking-api/internal/domain/model"
 	"github.com/shigaichi/top-sites-ranking-api/internal/domain/repository"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type WriteUseCase interface {
-	Write(ctx context.Context, date time.Time) error
+	Write(ctx context.Context, date time.Time) (int, error)
 }
 
 type StandardWriteInteractor struct {
@@ -24,18 +27,59 @@ type StandardWriteInteractor struct {
 	transaction repository.Transaction
 	domain      repository.TrancoDomainsRepository
 	ranking     repository.TrancoRankingsRepository
+	stats       repository.TrancoTLDStatsRepository
+	monthly     repository.TrancoMonthlyRankRepository
 }
 
-func NewStandardWriteInteractor(api repository.TrancoAPIRepository, list repository.TrancoListsRepository, csv repository.TrancoCsvRepository, transaction repository.Transaction, domain repository.TrancoDomainsRepository, ranking repository.TrancoRankingsRepository) *StandardWriteInteractor {
-	return &StandardWriteInteractor{api: api, list: list, csv: csv, transaction: transaction, domain: domain, ranking: ranking}
+func NewStandardWriteInteractor(api repository.TrancoAPIRepository, list repository.TrancoListsRepository, csv repository.TrancoCsvRepository, transaction repository.Transaction, domain repository.TrancoDomainsRepository, ranking repository.TrancoRankingsRepository, stats repository.TrancoTLDStatsRepository, monthly repository.TrancoMonthlyRankRepository) *StandardWriteInteractor {
+	return &StandardWriteInteractor{api: api, list: list, csv: csv, transaction: transaction, domain: domain, ranking: ranking, stats: stats, monthly: monthly}
 }
 
-func (i StandardWriteInteractor) Write(ctx context.Context, date time.Time) error {
+// Write saves the Tranco list of the date and returns how many rankings were written.
+// It writes nothing and returns 0 when the list is already saved.
+func (i StandardWriteInteractor) Write(ctx context.Context, date time.Time) (written int, err error) {
+	ctx, span := tracer.Start(ctx, "standard_write", trace.WithAttributes(attribute.String("date", date.Format("2006-01-02"))))
+	defer func() {
+		span.SetAttributes(attribute.Int("rows", written))
+		endSpan(span, err)
+	}()
+
+	metadata, saved, err := i.resolveList(ctx, date)
+	if err != nil {
+		return 0, err
+	}
+
+	if saved {
+		util.Logger(ctx).WithFields(log.Fields{"list_id": metadata.ListID, "date": date}).Info("list id already exists in writing standard tranco list")
+		return 0, nil
+	} else {
+		util.Logger(ctx).WithFields(log.Fields{"list_id": metadata.ListID, "date": date}).Info("list id does not exist and write standard tranco list")
+	}
+
+	parse, err := url.Parse(metadata.Download)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse csv url in writing standard tranco list error: %w", err)
+	}
+	rankings, err := i.csv.Get(ctx, *parse)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get csv in writing standard tranco list error: %w", err)
+	}
+
+	return i.insert(ctx, metadata, rankings)
+}
+
+// resolveList looks up the Tranco list of the date and reports whether it is already saved.
+func (i StandardWriteInteractor) resolveList(ctx context.Context, date time.Time) (metadata tranco.ListMetadata, saved bool, err error) {
 	const maxRetries = 3
 	const retryInterval = 100 * time.Millisecond
 
+	ctx, span := tracer.Start(ctx, "standard_write.resolve_list")
+	defer func() {
+		span.SetAttributes(attribute.String("list_id", metadata.ListID), attribute.Bool("saved", saved))
+		endSpan(span, err)
+	}()
+
 	// retry get tranco id until success
-	var metadata tranco.ListMetadata
 	var lastErr error
 	for attempt := 0; attempt < maxRetries; attempt++ {
 		var err error
@@ -48,32 +92,27 @@ func (i StandardWriteInteractor) Write(ctx context.Context, date time.Time) erro
 	}
 
 	if lastErr != nil {
-		return fmt.Errorf("failed to get tranco list id for date (%s) after %d retries. lastErr: %w", date.Format("2006-01-02"), maxRetries, lastErr)
+		return metadata, false, fmt.Errorf("failed to get tranco list id for date (%s) after %d retries. lastErr: %w", date.Format("2006-01-02"), maxRetries, lastErr)
 	}
 
-	savedListID, err := i.list.ExistsID(ctx, metadata.ListID)
+	saved, err = i.list.ExistsID(ctx, metadata.ListID)
 	if err != nil {
-		return fmt.Errorf("failed to check list id is already exist or not in writing standard tranco list error: %w", err)
+		return metadata, false, fmt.Errorf("failed to check list id is already exist or not in writing standard tranco list error: %w", err)
 	}
 
-	if savedListID {
-		log.WithFields(log.Fields{"list_id": metadata.ListID, "date": date}).Info("list id already exists in writing standard tranco list")
-		return nil
-	} else {
-		log.WithFields(log.Fields{"list_id": metadata.ListID, "date": date}).Info("list id does not exist and write standard tranco list")
-	}
+	return metadata, saved, nil
+}
 
-	parse, err := url.Parse(metadata.Download)
-	if err != nil {
-		return fmt.Errorf("failed to parse csv url in writing standard tranco list error: %w", err)
-	}
-	rankings, err := i.csv.Get(*parse)
-	if err != nil {
-		return fmt.Errorf("failed to get csv in writing standard tranco list error: %w", err)
-	}
+// insert saves the list and its rankings in a single transaction and returns how many rankings were written.
+func (i StandardWriteInteractor) insert(ctx context.Context, metadata tranco.ListMetadata, rankings []model.SiteRanking) (n int, err error) {
+	ctx, span := tracer.Start(ctx, "standard_write.insert", trace.WithAttributes(attribute.String("list_id", metadata.ListID)))
+	defer func() {
+		span.SetAttributes(attribute.Int("rows", n))
+		endSpan(span, err)
+	}()
 
-	_, err = i.transaction.DoInTx(ctx, func(ctx context.Context) (interface{}, error) {
-		err = i.list.Save(ctx, model.TrancoList{ID: metadata.ListID, CreatedOn: metadata.CreatedOn})
+	written, err := i.transaction.DoInTx(ctx, func(ctx context.Context) (interface{}, error) {
+		err := i.list.Save(ctx, model.TrancoList{ID: metadata.ListID, CreatedOn: metadata.CreatedOn})
 		if err != nil {
 			return nil, fmt.Errorf("failed to save tranco list with id %s error: %w", metadata.ListID, err)
 		}
@@ -87,7 +126,7 @@ func (i StandardWriteInteractor) Write(ctx context.Context, date time.Time) erro
 			}
 
 			if domainID == 0 {
-				domainID, err = i.domain.Save(ctx, ranking.Domain)
+				domainID, err = i.domain.Save(ctx, model.ParseDomainName(ranking.Domain))
 				if err != nil {
 					return nil, fmt.Errorf("failed to save domain in writing standard tranco list error: %w", err)
 				}
@@ -96,17 +135,42 @@ func (i StandardWriteInteractor) Write(ctx context.Context, date time.Time) erro
 			l = append(l, model.TrancoRanking{DomainID: domainID, ListID: metadata.ListID, Ranking: ranking.Rank})
 		}
 
-		err := i.ranking.BulkSave(ctx, l)
+		err = i.ranking.BulkSave(ctx, l)
 		if err != nil {
 			return nil, fmt.Errorf("failed to bulk save %d rankings in writing standard tranco list error: %w", len(l), err)
 		}
 
-		return nil, nil
+		err = i.list.SetRowCount(ctx, metadata.ListID, len(l))
+		if err != nil {
+			return nil, fmt.Errorf("failed to set row count in writing standard tranco list error: %w", err)
+		}
+
+		// counted in the same transaction so that a saved list always has its TLD stats
+		err = i.stats.Refresh(ctx, metadata.ListID, model.TLDStatsTops)
+		if err != nil {
+			return nil, fmt.Errorf("failed to refresh TLD stats in writing standard tranco list error: %w", err)
+		}
+
+		if isLastDayOfMonth(metadata.CreatedOn) {
+			err = i.monthly.Refresh(ctx, metadata.ListID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to refresh monthly rankings in writing standard tranco list error: %w", err)
+			}
+		}
+
+		return len(l), nil
 	})
 
 	if err != nil {
-		return fmt.Errorf("failed to save ranking data in writing standard tranco list and saving operation was rollbacked error: %w", err)
+		return 0, fmt.Errorf("failed to save ranking data in writing standard tranco list and saving operation was rollbacked error: %w", err)
 	}
 
-	return nil
+	n, _ = written.(int)
+	return n, nil
+}
+
+// Helper function to check if the given date is the last day of its month
+func isLastDayOfMonth(t time.Time) bool {
+	nextDay := t.AddDate(0, 0, 1)
+	return t.Month() != nextDay.Month()
 }
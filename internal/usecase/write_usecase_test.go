@@ -28,6 +28,9 @@ type MockTrancoListsRepository struct {
 	IsExist     bool
 	ExistsIDErr error
 	SaveErr     error
+	// SetRowCountErr is returned by SetRowCount, which records the count in RowCount.
+	SetRowCountErr error
+	RowCount       int
 }
 
 func (m *MockTrancoListsRepository) ExistsID(ctx context.Context, id string) (bool, error) {
@@ -44,20 +47,48 @@ func (m *MockTrancoListsRepository) Save(ctx context.Context, list model.TrancoL
 	return m.SaveErr
 }
 
+func (m *MockTrancoListsRepository) SetRowCount(ctx context.Context, id string, rows int) error {
+	if id != "X5Y7N" {
+		return errors.New("unexpected parameters in set row count")
+	}
+	m.RowCount = rows
+	return m.SetRowCountErr
+}
+
 func (m *MockTrancoListsRepository) FindByCreatedOnLessThan(ctx context.Context, date time.Time) ([]model.TrancoList, error) {
 	return nil, errors.New("not implemented")
 }
 
+func (m *MockTrancoListsRepository) FindByCreatedOnBetween(ctx context.Context, start time.Time, end time.Time) ([]model.TrancoList, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockTrancoListsRepository) FindLatest(ctx context.Context) (model.TrancoList, error) {
+	return model.TrancoList{}, errors.New("not implemented")
+}
+
+func (m *MockTrancoListsRepository) FindVersion(ctx context.Context) (string, error) {
+	return "", errors.New("not implemented")
+}
+
 func (m *MockTrancoListsRepository) DeleteByID(ctx context.Context, id string) error {
 	return errors.New("not implemented")
 }
 
+func (m *MockTrancoListsRepository) FindSummariesByCreatedOnBetween(ctx context.Context, start time.Time, end time.Time) ([]model.TrancoListSummary, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockTrancoListsRepository) FindLatestSummary(ctx context.Context) (model.TrancoListSummary, error) {
+	return model.TrancoListSummary{}, errors.New("not implemented")
+}
+
 type MockTrancoCsvRepository struct {
 	SiteRankings []model.SiteRanking
 	Err          error
 }
 
-func (m *MockTrancoCsvRepository) Get(url url.URL) ([]model.SiteRanking, error) {
+func (m *MockTrancoCsvRepository) Get(_ context.Context, url url.URL) ([]model.SiteRanking, error) {
 	expected, _ := url.Parse("https://tranco-list.eu/download/X5Y7N/1000000")
 	if *expected != url {
 		return nil, errors.New("unexpected parameters in Get")
@@ -86,10 +117,18 @@ func (m *MockTrancoDomainsRepository) GetIDByDomain(ctx context.Context, domain
 	return m.ID, m.GetIDByDomainErr
 }
 
-func (m *MockTrancoDomainsRepository) Save(ctx context.Context, domain string) (int, error) {
+func (m *MockTrancoDomainsRepository) Save(ctx context.Context, name model.DomainName) (int, error) {
 	return 10, m.SaveErr
 }
 
+func (m *MockTrancoDomainsRepository) FindUnparsed(ctx context.Context, limit int) ([]string, error) {
+	return nil, errors.New("unexpected invoke")
+}
+
+func (m *MockTrancoDomainsRepository) UpdateNames(ctx context.Context, names []model.DomainName) (int, error) {
+	return 0, errors.New("unexpected invoke")
+}
+
 type MockTrancoRankingsRepository struct {
 	Err              error
 	ExpectedRankings []model.TrancoRanking
@@ -115,28 +154,83 @@ func (m *MockTrancoRankingsRepository) DeleteByListID(ctx context.Context, listI
 	return errors.New("not implemented")
 }
 
+func (m *MockTrancoRankingsRepository) FindTopByListID(ctx context.Context, listID string, limit int) ([]model.SiteRanking, error) {
+	return nil, errors.New("not implemented")
+}
+
+type MockTrancoTLDStatsRepository struct {
+	Err error
+}
+
+func (m *MockTrancoTLDStatsRepository) Refresh(ctx context.Context, listID string, tops []int) error {
+	return m.Err
+}
+
+func (m *MockTrancoTLDStatsRepository) FindByListID(ctx context.Context, listID string, top int) ([]model.TLDCount, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockTrancoTLDStatsRepository) FindLargestTLDs(ctx context.Context, top int, start time.Time, end time.Time, n int) ([]string, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockTrancoTLDStatsRepository) FindByCreatedOnBetween(ctx context.Context, top int, start time.Time, end time.Time, tlds []string) ([]model.TLDListCount, error) {
+	return nil, errors.New("not implemented")
+}
+
+type MockTrancoMonthlyRankRepository struct {
+	Err       error
+	Refreshed []string
+}
+
+func (m *MockTrancoMonthlyRankRepository) Refresh(ctx context.Context, listID string) error {
+	m.Refreshed = append(m.Refreshed, listID)
+	return m.Err
+}
+
+func (m *MockTrancoMonthlyRankRepository) RefreshMissing(ctx context.Context) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (m *MockTrancoMonthlyRankRepository) DeleteByListID(ctx context.Context, listID string) error {
+	return errors.New("not implemented")
+}
+
+func (m *MockTrancoMonthlyRankRepository) GetMonthlyRanksByDateRange(ctx context.Context, domain string, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockTrancoMonthlyRankRepository) GetRegistrableMonthlyRanksByDateRange(ctx context.Context, registrable string, start time.Time, end time.Time) ([]model.DailyRank, error) {
+	return nil, errors.New("not implemented")
+}
+
 func TestStandardWriteInteractor_Write(t *testing.T) {
 	tests := []struct {
-		name          string
-		inputDate     time.Time
-		api           repository.TrancoAPIRepository
-		list          repository.TrancoListsRepository
-		csv           repository.TrancoCsvRepository
-		transaction   repository.Transaction
-		domain        repository.TrancoDomainsRepository
-		ranking       repository.TrancoRankingsRepository
-		expectedError error
+		name            string
+		inputDate       time.Time
+		api             repository.TrancoAPIRepository
+		list            repository.TrancoListsRepository
+		csv             repository.TrancoCsvRepository
+		transaction     repository.Transaction
+		domain          repository.TrancoDomainsRepository
+		ranking         repository.TrancoRankingsRepository
+		stats           repository.TrancoTLDStatsRepository
+		monthly         repository.TrancoMonthlyRankRepository
+		expectedWritten int
+		expectedError   error
 	}{
 		{
-			name:          "successful write",
-			inputDate:     time.Now(),
-			api:           &MockTrancoAPIRepository{Metadata: tranco.ListMetadata{ListID: "X5Y7N", Download: "https://tranco-list.eu/download/X5Y7N/1000000", CreatedOn: time.Date(2023, 10, 17, 0, 0, 0, 0, time.UTC)}, Err: nil},
-			list:          &MockTrancoListsRepository{IsExist: false, ExistsIDErr: nil},
-			csv:           &MockTrancoCsvRepository{SiteRankings: []model.SiteRanking{{Domain: "example.com", Rank: 1}}, Err: nil},
-			transaction:   &MockTransaction{},
-			domain:        &MockTrancoDomainsRepository{ID: 0, GetIDByDomainErr: nil},
-			ranking:       &MockTrancoRankingsRepository{ExpectedRankings: []model.TrancoRanking{{DomainID: 10, ListID: "X5Y7N", Ranking: 1}}},
-			expectedError: nil,
+			name:            "successful write",
+			inputDate:       time.Now(),
+			api:             &MockTrancoAPIRepository{Metadata: tranco.ListMetadata{ListID: "X5Y7N", Download: "https://tranco-list.eu/download/X5Y7N/1000000", CreatedOn: time.Date(2023, 10, 17, 0, 0, 0, 0, time.UTC)}, Err: nil},
+			list:            &MockTrancoListsRepository{IsExist: false, ExistsIDErr: nil},
+			csv:             &MockTrancoCsvRepository{SiteRankings: []model.SiteRanking{{Domain: "example.com", Rank: 1}}, Err: nil},
+			transaction:     &MockTransaction{},
+			domain:          &MockTrancoDomainsRepository{ID: 0, GetIDByDomainErr: nil},
+			ranking:         &MockTrancoRankingsRepository{ExpectedRankings: []model.TrancoRanking{{DomainID: 10, ListID: "X5Y7N", Ranking: 1}}},
+			stats:           &MockTrancoTLDStatsRepository{},
+			expectedWritten: 1,
+			expectedError:   nil,
 		},
 		{
 			name:          "api error",
@@ -205,15 +299,17 @@ func TestStandardWriteInteractor_Write(t *testing.T) {
 			expectedError: errors.New("failed to save ranking data in writing standard tranco list and saving operation was rollbacked error: failed to save list id in writing standard tranco list error: test"),
 		},
 		{
-			name:          "domain exists",
-			inputDate:     time.Now(),
-			api:           &MockTrancoAPIRepository{Metadata: tranco.ListMetadata{ListID: "X5Y7N", Download: "https://tranco-list.eu/download/X5Y7N/1000000", CreatedOn: time.Date(2023, 10, 17, 0, 0, 0, 0, time.UTC)}, Err: nil},
-			list:          &MockTrancoListsRepository{IsExist: false, ExistsIDErr: nil, SaveErr: nil},
-			csv:           &MockTrancoCsvRepository{SiteRankings: []model.SiteRanking{{Domain: "example.com", Rank: 1}}, Err: nil},
-			transaction:   &MockTransaction{},
-			domain:        &MockTrancoDomainsRepository{ID: 10, GetIDByDomainErr: nil},
-			ranking:       &MockTrancoRankingsRepository{ExpectedRankings: []model.TrancoRanking{{DomainID: 10, ListID: "X5Y7N", Ranking: 1}}},
-			expectedError: nil,
+			name:            "domain exists",
+			inputDate:       time.Now(),
+			api:             &MockTrancoAPIRepository{Metadata: tranco.ListMetadata{ListID: "X5Y7N", Download: "https://tranco-list.eu/download/X5Y7N/1000000", CreatedOn: time.Date(2023, 10, 17, 0, 0, 0, 0, time.UTC)}, Err: nil},
+			list:            &MockTrancoListsRepository{IsExist: false, ExistsIDErr: nil, SaveErr: nil},
+			csv:             &MockTrancoCsvRepository{SiteRankings: []model.SiteRanking{{Domain: "example.com", Rank: 1}}, Err: nil},
+			transaction:     &MockTransaction{},
+			domain:          &MockTrancoDomainsRepository{ID: 10, GetIDByDomainErr: nil},
+			ranking:         &MockTrancoRankingsRepository{ExpectedRankings: []model.TrancoRanking{{DomainID: 10, ListID: "X5Y7N", Ranking: 1}}},
+			stats:           &MockTrancoTLDStatsRepository{},
+			expectedWritten: 1,
+			expectedError:   nil,
 		},
 		{
 			name:          "domain save error",
@@ -237,13 +333,75 @@ func TestStandardWriteInteractor_Write(t *testing.T) {
 			ranking:       &MockTrancoRankingsRepository{Err: errors.New("test")},
 			expectedError: errors.New("failed to save ranking data in writing standard tranco list and saving operation was rollbacked error: failed to bulk save 1 rankings in writing standard tranco list error: test"),
 		},
+		{
+			name:          "TLD stats refresh error",
+			inputDate:     time.Now(),
+			api:           &MockTrancoAPIRepository{Metadata: tranco.ListMetadata{ListID: "X5Y7N", Download: "https://tranco-list.eu/download/X5Y7N/1000000", CreatedOn: time.Date(2023, 10, 17, 0, 0, 0, 0, time.UTC)}, Err: nil},
+			list:          &MockTrancoListsRepository{IsExist: false, ExistsIDErr: nil, SaveErr: nil},
+			csv:           &MockTrancoCsvRepository{SiteRankings: []model.SiteRanking{{Domain: "example.com", Rank: 1}}, Err: nil},
+			transaction:   &MockTransaction{},
+			domain:        &MockTrancoDomainsRepository{ID: 10},
+			ranking:       &MockTrancoRankingsRepository{ExpectedRankings: []model.TrancoRanking{{DomainID: 10, ListID: "X5Y7N", Ranking: 1}}},
+			stats:         &MockTrancoTLDStatsRepository{Err: errors.New("test")},
+			expectedError: errors.New("failed to save ranking data in writing standard tranco list and saving operation was rollbacked error: failed to refresh TLD stats in writing standard tranco list error: test"),
+		},
+		{
+			name:          "row count error",
+			inputDate:     time.Now(),
+			api:           &MockTrancoAPIRepository{Metadata: tranco.ListMetadata{ListID: "X5Y7N", Download: "https://tranco-list.eu/download/X5Y7N/1000000", CreatedOn: time.Date(2023, 10, 17, 0, 0, 0, 0, time.UTC)}, Err: nil},
+			list:          &MockTrancoListsRepository{IsExist: false, ExistsIDErr: nil, SetRowCountErr: errors.New("test")},
+			csv:           &MockTrancoCsvRepository{SiteRankings: []model.SiteRanking{{Domain: "example.com", Rank: 1}}, Err: nil},
+			transaction:   &MockTransaction{},
+			domain:        &MockTrancoDomainsRepository{ID: 10},
+			ranking:       &MockTrancoRankingsRepository{ExpectedRankings: []model.TrancoRanking{{DomainID: 10, ListID: "X5Y7N", Ranking: 1}}},
+			stats:         &MockTrancoTLDStatsRepository{},
+			expectedError: errors.New("failed to save ranking data in writing standard tranco list and saving operation was rollbacked error: failed to set row count in writing standard tranco list error: test"),
+		},
+		{
+			name:            "month-end list refreshes monthly rankings",
+			inputDate:       time.Now(),
+			api:             &MockTrancoAPIRepository{Metadata: tranco.ListMetadata{ListID: "X5Y7N", Download: "https://tranco-list.eu/download/X5Y7N/1000000", CreatedOn: time.Date(2023, 10, 31, 0, 0, 0, 0, time.UTC)}, Err: nil},
+			list:            &MockTrancoListsRepository{IsExist: false, ExistsIDErr: nil},
+			csv:             &MockTrancoCsvRepository{SiteRankings: []model.SiteRanking{{Domain: "example.com", Rank: 1}}, Err: nil},
+			transaction:     &MockTransaction{},
+			domain:          &MockTrancoDomainsRepository{ID: 10},
+			ranking:         &MockTrancoRankingsRepository{ExpectedRankings: []model.TrancoRanking{{DomainID: 10, ListID: "X5Y7N", Ranking: 1}}},
+			stats:           &MockTrancoTLDStatsRepository{},
+			monthly:         &MockTrancoMonthlyRankRepository{},
+			expectedWritten: 1,
+		},
+		{
+			name:          "monthly rankings refresh error",
+			inputDate:     time.Now(),
+			api:           &MockTrancoAPIRepository{Metadata: tranco.ListMetadata{ListID: "X5Y7N", Download: "https://tranco-list.eu/download/X5Y7N/1000000", CreatedOn: time.Date(2023, 10, 31, 0, 0, 0, 0, time.UTC)}, Err: nil},
+			list:          &MockTrancoListsRepository{IsExist: false, ExistsIDErr: nil},
+			csv:           &MockTrancoCsvRepository{SiteRankings: []model.SiteRanking{{Domain: "example.com", Rank: 1}}, Err: nil},
+			transaction:   &MockTransaction{},
+			domain:        &MockTrancoDomainsRepository{ID: 10},
+			ranking:       &MockTrancoRankingsRepository{ExpectedRankings: []model.TrancoRanking{{DomainID: 10, ListID: "X5Y7N", Ranking: 1}}},
+			stats:         &MockTrancoTLDStatsRepository{},
+			monthly:       &MockTrancoMonthlyRankRepository{Err: errors.New("test")},
+			expectedError: errors.New("failed to save ranking data in writing standard tranco list and saving operation was rollbacked error: failed to refresh monthly rankings in writing standard tranco list error: test"),
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			interactor := NewStandardWriteInteractor(tt.api, tt.list, tt.csv, tt.transaction, tt.domain, tt.ranking)
+			monthly := tt.monthly
+			if monthly == nil {
+				// Only month-end lists are snapshotted.
+				monthly = &MockTrancoMonthlyRankRepository{Err: errors.New("unexpected invoke")}
+			}
+			interactor := NewStandardWriteInteractor(tt.api, tt.list, tt.csv, tt.transaction, tt.domain, tt.ranking, tt.stats, monthly)
+
+			written, err := interactor.Write(context.Background(), tt.inputDate)
 
-			err := interactor.Write(context.Background(), tt.inputDate)
+			if written != tt.expectedWritten {
+				t.Errorf("expected %d written rankings, got %d", tt.expectedWritten, written)
+			}
+			if list, ok := tt.list.(*MockTrancoListsRepository); ok && tt.expectedWritten > 0 && list.RowCount != tt.expectedWritten {
+				t.Errorf("expected row count %d to be stored, got %d", tt.expectedWritten, list.RowCount)
+			}
 
 			if tt.expectedError != nil {
 				if err == nil {
@@ -1,13 +1,15 @@
 package util
 
 import (
+	"context"
+
 	log "github.com/sirupsen/logrus"
 )
 
-func SetupLogger() error {
-	p := GetEnvWithDefault("LOG_LEVEL", "info")
+type loggerKey struct{}
 
-	level, err := log.ParseLevel(p)
+func SetupLogger(levelName string) error {
+	level, err := log.ParseLevel(levelName)
 	if err != nil {
 		return err
 	}
@@ -17,3 +19,17 @@ func SetupLogger() error {
 
 	return nil
 }
+
+// WithLogger returns a copy of ctx that carries logger.
+func WithLogger(ctx context.Context, logger *log.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger returns the request-scoped logger stored in ctx.
+// It falls back to the standard logger when ctx carries none, e.g. in batch jobs.
+func Logger(ctx context.Context) *log.Entry {
+	if logger, ok := ctx.Value(loggerKey{}).(*log.Entry); ok {
+		return logger.WithContext(ctx)
+	}
+	return log.WithContext(ctx)
+}
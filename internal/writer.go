@@ -5,22 +5,60 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/shigaichi/top-sites-ranking-api/internal/config"
 	"github.com/shigaichi/top-sites-ranking-api/internal/injector"
+	"github.com/shigaichi/top-sites-ranking-api/internal/metrics"
+	"github.com/shigaichi/top-sites-ranking-api/internal/util"
+	log "github.com/sirupsen/logrus"
 
 	"github.com/shigaichi/top-sites-ranking-api/internal/infra"
 )
 
-func StandardWriter(date time.Time) error {
-	db, err := infra.NewDb()
+// normalizeBatchSize is how many saved domains are filled in by one statement.
+const normalizeBatchSize = 10000
+
+func StandardWriter(cfg config.Config, date time.Time) error {
+	start := time.Now()
+	m := metrics.NewJobMetrics("standard_writer")
+
+	shutdownTracing, err := util.SetupTracing(context.Background(), "standard-writer")
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing. error: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.WithFields(log.Fields{"error": err}).Warn("failed to flush standard writer traces")
+		}
+	}()
+
+	written, err := standardWrite(cfg, date)
+	m.Observe(start, written, err)
+	if exportErr := m.Export(cfg.Metrics.Textfile, cfg.Metrics.PushgatewayURL); exportErr != nil {
+		log.WithFields(log.Fields{"error": exportErr}).Warn("failed to export standard writer metrics")
+	}
+
+	return err
+}
+
+func standardWrite(cfg config.Config, date time.Time) (int, error) {
+	db, err := infra.NewDb(cfg.Database)
 	if err != nil {
-		return fmt.Errorf("failed to create db connection when start up service. error: %w", err)
+		return 0, fmt.Errorf("failed to create db connection when start up service. error: %w", err)
 	}
+	ctx := context.Background()
+
+	// fill in the registrable domains of rows saved before they were stored, so that rollups cover their history.
+	// Once every row is filled in this finds nothing.
+	if _, err := injector.NewDomainNameInteractor(db, normalizeBatchSize).Normalize(ctx); err != nil {
+		log.WithFields(log.Fields{"error": err}).Warn("failed to normalize saved domains")
+	}
+
 	transaction := infra.NewTransaction(db)
-	u := injector.NewStandardWriteInteractor(transaction, db, 10000)
+	u := injector.NewStandardWriteInteractor(transaction, db, cfg.Writer)
 
-	err = u.Write(context.Background(), date)
+	written, err := u.Write(ctx, date)
 	if err != nil {
-		return fmt.Errorf("failed to write csv. error: %w", err)
+		return 0, fmt.Errorf("failed to write csv. error: %w", err)
 	}
-	return nil
+	return written, nil
 }